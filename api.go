@@ -3,10 +3,11 @@ package main
 import (
 	"codenotary/internal"
 	"codenotary/internal/models"
-	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -33,7 +34,7 @@ func HandleAddOrUpdateDependency(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := sqlite.AddOrUpdateDependency(internal.Db, req.ProjectName, req.Dependency)
+	err := internal.Store.AddOrUpdateDependency(req.ProjectName, req.Dependency)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to add/update dependency: %v", err), http.StatusInternalServerError)
 		return
@@ -63,7 +64,7 @@ func HandleGetDependency(w http.ResponseWriter, r *http.Request) {
 	}
 	projectName, depName := parts[0], parts[1]
 
-	dep, err := sqlite.GetDependency(internal.Db, projectName, depName)
+	dep, err := internal.Store.GetDependency(projectName, depName)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error retrieving dependency: %v", err), http.StatusInternalServerError)
 		return
@@ -97,7 +98,7 @@ func HandleDeleteDependency(w http.ResponseWriter, r *http.Request) {
 	}
 	projectName, depName := parts[0], parts[1]
 
-	err := sqlite.DeleteDependency(internal.Db, projectName, depName)
+	err := internal.Store.DeleteDependency(projectName, depName)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error deleting dependency: %v", err), http.StatusInternalServerError)
 		return
@@ -125,12 +126,61 @@ func HandleListDependencies(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	deps, err := sqlite.ListDependencies(internal.Db, name, minScore)
+	profileQuery, err := parseProfileQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := store.DependencyQuery{Name: name}
+	if minScore > 0 {
+		query.MinOSSFScore = &minScore
+	}
+	page, err := internal.Store.ListDependencies(query)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error listing dependencies: %v", err), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(deps)
+	if profileQuery == nil {
+		deps := make([]models.Node, len(page.Dependencies))
+		for i, d := range page.Dependencies {
+			deps[i] = d.Node
+		}
+		json.NewEncoder(w).Encode(deps)
+		return
+	}
+	json.NewEncoder(w).Encode(profileDependencies(page.Dependencies, profileQuery))
+}
+
+// ProfiledDependency is a listed dependency with its score under ?profile=.
+type ProfiledDependency struct {
+	models.Node
+	ProfileScore float64 `json:"profile_score"`
+}
+
+// profileDependencies scores dependencies with the profile, keeps those in range
+// and ranks them best first. A dependency is scored with the checks of the
+// project its version resolves to, as GET /dependencies/query resolves it.
+func profileDependencies(deps []store.Dependency, q *profileQuery) []ProfiledDependency {
+	scores := make(map[string]float64)
+	profiled := []ProfiledDependency{}
+	for _, dep := range deps {
+		score, ok := scores[dep.SourceProjectID]
+		if !ok {
+			score = -1
+			if dep.SourceProjectID != "" {
+				score = q.score(dep.SourceProjectID)
+			}
+			scores[dep.SourceProjectID] = score
+		}
+		if q.keep(score) {
+			profiled = append(profiled, ProfiledDependency{Node: dep.Node, ProfileScore: score})
+		}
+	}
+	sort.SliceStable(profiled, func(i, j int) bool {
+		return profiled[i].ProfileScore > profiled[j].ProfileScore
+	})
+	return profiled
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/models"
+	"codenotary/internal/profile"
+	"codenotary/internal/store"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestListDependenciesProfileNPM(t *testing.T) {
+	s := useTestStore(t)
+	profiles, err := profile.Parse([]byte("profiles:\n  reviews:\n    weights:\n      Code-Review: 1\n"))
+	if err != nil {
+		t.Fatalf("parsing profiles: %v", err)
+	}
+	oldProfiles := internal.Profiles
+	internal.Profiles = profiles
+	t.Cleanup(func() { internal.Profiles = oldProfiles })
+
+	// express is scored with the checks of its source repository; the NPM
+	// package named like a Go module project has no project of its own.
+	const cobra = "github.com/spf13/cobra"
+	for id, review := range map[string]float64{"github.com/expressjs/express": 8, cobra: 3} {
+		err := s.InsertProject(&models.Project{
+			ProjectKey: models.ProjectKey{ID: id},
+			Scorecard:  models.Scorecard{Date: "2024-01-08T00:00:00Z", Checks: []models.ScorecardCheck{{Name: "Code-Review", Score: review}}},
+			FetchedAt:  time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("InsertProject(%s): %v", id, err)
+		}
+	}
+	node := func(name, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "NPM", Name: name, Version: "4.18.2"}, Relation: relation, Errors: []string{}}
+	}
+	graph := &models.DependencyGraph{
+		Nodes: []models.Node{node("express", "SELF"), node(cobra, "DIRECT")},
+		Edges: []models.Edge{{FromNode: 0, ToNode: 1}},
+	}
+	if err := s.InsertDependencyGraph("NPM", "express", "4.18.2", store.SourceDepsDev, graph); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+	if err := s.SetVersionProject(graph.Nodes[0].VersionKey, "github.com/expressjs/express"); err != nil {
+		t.Fatalf("SetVersionProject: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleListDependencies(rec, httptest.NewRequest(http.MethodGet, "/dependencies?profile=reviews", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var got []ProfiledDependency
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(got) != 2 || got[0].VersionKey.Name != "express" || got[0].ProfileScore != 8 || got[1].ProfileScore != -1 {
+		t.Errorf("profiled dependencies = %+v, want express scoring 8, then %s without a score", got, cobra)
+	}
+
+	rec = httptest.NewRecorder()
+	HandleListDependencies(rec, httptest.NewRequest(http.MethodGet, "/dependencies?profile=reviews&min_profile_score=5", nil))
+	got = nil
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || len(got) != 1 || got[0].VersionKey.Name != "express" {
+		t.Errorf("min_profile_score=5 kept %+v, %v; want express", got, err)
+	}
+}
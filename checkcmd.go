@@ -0,0 +1,230 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"codenotary/internal/models"
+	"codenotary/internal/policy"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Exit codes of `codenotary check`.
+const (
+	checkPassed    = 0
+	checkViolation = 1
+	checkFailed    = 2
+)
+
+// checkOptions are the parsed flags of `codenotary check`.
+type checkOptions struct {
+	cfg          *config.Config
+	system       string
+	target       string
+	gomod, gosum string
+	version      string
+	policyPath   string
+	minScore     *float64
+	minChecks    []policy.Rule
+	directOnly   bool
+	allowUnknown bool
+	junit, sarif string
+}
+
+func parseCheckFlags(args []string) (*checkOptions, error) {
+	opts := &checkOptions{}
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary check [flags] {name[@version] | -gomod go.mod}")
+		flags.PrintDefaults()
+	}
+	var err error
+	if opts.cfg, err = loadConfig(flags, args, config.GroupStore|config.GroupClient); err != nil {
+		return nil, err
+	}
+	flags.StringVar(&opts.system, "system", "", "ecosystem of the named package (GO, NPM, PYPI, ...), GO by default")
+	flags.StringVar(&opts.gomod, "gomod", "", "check a local go.mod instead of a named package")
+	flags.StringVar(&opts.gosum, "gosum", "", "go.sum of -gomod, the one next to it by default")
+	flags.StringVar(&opts.version, "version", "", "version the -gomod graph is stored under (default \"local\")")
+	flags.StringVar(&opts.policyPath, "policy", opts.cfg.Policy, "policy file (YAML or JSON) with the rules to enforce (CODENOTARY_POLICY)")
+	flags.Func("min-score", "fail dependencies with an overall score below `N`", func(value string) error {
+		min, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		opts.minScore = &min
+		return nil
+	})
+	flags.Func("min-check", "fail dependencies whose `Check=N` score is lower, repeatable", func(value string) error {
+		check, value, ok := strings.Cut(value, "=")
+		min, err := strconv.ParseFloat(value, 64)
+		if !ok || check == "" || err != nil {
+			return fmt.Errorf("want Check=N, like Maintained=3")
+		}
+		opts.minChecks = append(opts.minChecks, policy.Rule{
+			Name:        "min-check-" + check,
+			Description: fmt.Sprintf("%s check score of at least %s", check, value),
+			Type:        policy.TypeCheck,
+			Check:       check,
+			Min:         &min,
+		})
+		return nil
+	})
+	flags.BoolVar(&opts.directOnly, "direct-only", false, "apply -min-score and -min-check to direct dependencies only")
+	flags.BoolVar(&opts.allowUnknown, "allow-unknown", false, "let dependencies without a scorecard pass -min-score and -min-check")
+	flags.StringVar(&opts.junit, "junit", "", "also write a JUnit XML report to this file")
+	flags.StringVar(&opts.sarif, "sarif", "", "also write a SARIF report to this file")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case opts.gomod == "" && flags.NArg() != 1:
+		return nil, fmt.Errorf("name a package or pass -gomod")
+	case opts.gomod != "" && flags.NArg() != 0:
+		return nil, fmt.Errorf("pass either a package name or -gomod, not both")
+	case opts.gomod != "" && opts.system != "":
+		return nil, fmt.Errorf("-system doesn't apply to -gomod")
+	}
+	opts.target = flags.Arg(0)
+	return opts, nil
+}
+
+// policy combines the policy file with the rules given as flags.
+func (opts *checkOptions) policy() (*policy.Policy, error) {
+	p := &policy.Policy{}
+	if opts.policyPath != "" {
+		loaded, err := policy.Load(opts.policyPath)
+		if err != nil {
+			return nil, err
+		}
+		p = loaded
+	}
+
+	var rules []policy.Rule
+	if opts.minScore != nil {
+		rules = append(rules, policy.Rule{
+			Name:        "min-score",
+			Description: fmt.Sprintf("Overall OpenSSF score of at least %g", *opts.minScore),
+			Type:        policy.TypeScore,
+			Min:         opts.minScore,
+		})
+	}
+	rules = append(rules, opts.minChecks...)
+	for _, rule := range rules {
+		if opts.directOnly {
+			rule.Relations = []string{"DIRECT"}
+		}
+		rule.AllowUnknown = opts.allowUnknown
+		p.Rules = append(p.Rules, rule)
+	}
+	if len(p.Rules) == 0 {
+		return nil, fmt.Errorf("nothing to check, pass -policy, -min-score or -min-check")
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// runCheck implements `codenotary check`: it scans a package or a local go.mod,
+// evaluates the policy against its dependencies and prints a report.
+func runCheck(args []string, stdout io.Writer) (int, error) {
+	opts, err := parseCheckFlags(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return checkPassed, nil
+	} else if err != nil {
+		return checkFailed, err
+	}
+	rules, err := opts.policy()
+	if err != nil {
+		return checkFailed, err
+	}
+
+	if err := openClient(opts.cfg); err != nil {
+		return checkFailed, err
+	}
+	defer internal.Store.Close()
+
+	report, projects, err := opts.scan()
+	if err != nil {
+		return checkFailed, err
+	}
+	report.Policy = rules
+	report.Result = rules.Evaluate(policy.Input{Graph: report.Graph, Projects: projects, Checks: nodeChecks(projects)})
+
+	if err := report.WriteText(stdout); err != nil {
+		return checkFailed, err
+	}
+	if opts.junit != "" {
+		if err := writeReportFile(opts.junit, report.WriteJUnit); err != nil {
+			return checkFailed, err
+		}
+	}
+	if opts.sarif != "" {
+		if err := writeReportFile(opts.sarif, report.WriteSARIF); err != nil {
+			return checkFailed, err
+		}
+	}
+	if !report.Result.Passed {
+		return checkViolation, nil
+	}
+	return checkPassed, nil
+}
+
+// scan fetches the graph to check and the project of each of its nodes.
+func (opts *checkOptions) scan() (policy.Report, []*models.Project, error) {
+	if opts.gomod != "" {
+		gomod, gosum, err := readGoMod(opts.gomod, opts.gosum)
+		if err != nil {
+			return policy.Report{}, nil, err
+		}
+
+		result, err := internal.Client.ScanGoMod(gomod, gosum, opts.version)
+		if err != nil {
+			return policy.Report{}, nil, fmt.Errorf("failed to scan %s: %v", opts.gomod, err)
+		}
+		report := policy.Report{System: "GO", Name: result.Module, Version: result.Version, Artifact: filepath.ToSlash(opts.gomod), Graph: result.Graph}
+		return report, result.NodeProjects, nil
+	}
+
+	system, name, pinnedVersion, err := packageArg(opts.system, opts.target)
+	if err != nil {
+		return policy.Report{}, nil, err
+	}
+	dependencyGraph, err := fetchGraph(system, name, pinnedVersion)
+	if err != nil {
+		return policy.Report{}, nil, fmt.Errorf("failed to fetch the dependencies of %s: %v", name, err)
+	}
+	report := policy.Report{System: system, Name: name, Version: selfVersion(dependencyGraph), Graph: dependencyGraph}
+	return report, nodeProjects(dependencyGraph), nil
+}
+
+func writeReportFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+	return file.Close()
+}
+
+func runCheckCommand(args []string) error {
+	code, err := runCheck(args, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "codenotary check: %v\n", err)
+	}
+	if code != checkPassed {
+		return exitCode(code)
+	}
+	return nil
+}
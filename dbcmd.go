@@ -0,0 +1,95 @@
+package main
+
+import (
+	"codenotary/internal/config"
+	"codenotary/internal/store"
+	"flag"
+	"fmt"
+)
+
+// runDB implements `codenotary db {migrate|version}`.
+func runDB(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: codenotary db {migrate|version} [flags]")
+	}
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "version":
+		return runSchemaVersion(args[1:])
+	default:
+		return fmt.Errorf("unknown db command %q, use migrate or version", args[0])
+	}
+}
+
+// runMigrate implements `codenotary db migrate [-backend name] [-db dsn] [-dry-run] [-to version]`.
+func runMigrate(args []string) error {
+	flags := flag.NewFlagSet("db migrate", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, config.GroupStore)
+	if err != nil {
+		return err
+	}
+	dryRun := flags.Bool("dry-run", false, "list pending migrations without applying them")
+	target := flags.Int("to", 0, "migrate up to this version instead of the latest")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	current, err := st.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s is at schema version %d\n", cfg.Store.Backend, current)
+
+	migrations, err := st.Migrate(store.MigrateOptions{DryRun: *dryRun, Target: *target})
+	verb := "applied"
+	if *dryRun {
+		verb = "pending"
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", verb, m.Version, m.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return nil
+}
+
+// runSchemaVersion implements `codenotary db version`: the applied schema version and
+// how many migrations are pending.
+func runSchemaVersion(args []string) error {
+	flags := flag.NewFlagSet("db version", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, config.GroupStore)
+	if err != nil {
+		return err
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	current, err := st.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	pending, err := st.Migrate(store.MigrateOptions{DryRun: true})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s is at schema version %d, %d migrations pending\n", cfg.Store.Backend, current, len(pending))
+	return nil
+}
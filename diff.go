@@ -0,0 +1,217 @@
+package main
+
+import (
+	"codenotary/internal/graph"
+	"codenotary/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"text/tabwriter"
+)
+
+type DiffChange struct {
+	Change      string   `json:"change"`
+	System      string   `json:"system"`
+	Name        string   `json:"name"`
+	FromVersion string   `json:"from_version,omitempty"`
+	ToVersion   string   `json:"to_version,omitempty"`
+	Relation    string   `json:"relation"`
+	FromScore   float64  `json:"from_score"`
+	ToScore     float64  `json:"to_score"`
+	ScoreDelta  *float64 `json:"score_delta,omitempty"`
+}
+
+type DiffSummary struct {
+	Added           int      `json:"added"`
+	Removed         int      `json:"removed"`
+	Upgraded        int      `json:"upgraded"`
+	Downgraded      int      `json:"downgraded"`
+	MetadataChanged int      `json:"metadata_changed"`
+	Unchanged       int      `json:"unchanged"`
+	FromAverage     float64  `json:"from_average_score"`
+	ToAverage       float64  `json:"to_average_score"`
+	AverageDelta    *float64 `json:"average_score_delta,omitempty"`
+}
+
+type DiffResponse struct {
+	ProjectName string       `json:"project_name"`
+	System      string       `json:"system"`
+	From        string       `json:"from"`
+	To          string       `json:"to"`
+	Summary     DiffSummary  `json:"summary"`
+	Changes     []DiffChange `json:"changes"`
+}
+
+// HandleDiff serves GET /diff/{name}?from=vX&to=vY[&format=text]: the dependencies
+// added, removed, upgraded and downgraded between two versions of a package, with
+// their OpenSSF scores. Scores are -1 when unknown. Without to, the latest
+// version is used.
+func HandleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	system, projectName := parseSystemAndName(strings.TrimPrefix(r.URL.Path, "/diff/"))
+	if projectName == "" {
+		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	fromVersion, toVersion := query.Get("from"), query.Get("to")
+	if fromVersion == "" {
+		http.Error(w, "Missing from version. Use /diff/{name}?from=vX&to=vY", http.StatusBadRequest)
+		return
+	}
+	format := query.Get("format")
+	if format != "" && format != "json" && format != "text" {
+		http.Error(w, "format must be json or text", http.StatusBadRequest)
+		return
+	}
+
+	from, err := fetchGraph(system, projectName, fromVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies of %s: %v", fromVersion, err), http.StatusBadGateway)
+		return
+	}
+	to, err := fetchGraph(system, projectName, toVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies of %s: %v", toVersion, err), http.StatusBadGateway)
+		return
+	}
+
+	response := diffResponse(from, to, nodeScores(from), nodeScores(to))
+	response.ProjectName = projectName
+	response.System = system
+
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writeDiffText(w, response)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+func diffResponse(from, to *models.DependencyGraph, fromScores, toScores []float64) DiffResponse {
+	diff := graph.DiffGraphs(from, to)
+	response := DiffResponse{
+		From:    selfVersion(from),
+		To:      selfVersion(to),
+		Changes: []DiffChange{},
+		Summary: DiffSummary{Unchanged: diff.Unchanged},
+	}
+
+	for _, c := range diff.Changes {
+		change := DiffChange{Change: c.Kind, System: c.System, Name: c.Name, FromScore: -1, ToScore: -1}
+		if c.FromIndex >= 0 {
+			change.FromVersion = from.Nodes[c.FromIndex].VersionKey.Version
+			change.Relation = from.Nodes[c.FromIndex].Relation
+			change.FromScore = fromScores[c.FromIndex]
+		}
+		if c.ToIndex >= 0 {
+			change.ToVersion = to.Nodes[c.ToIndex].VersionKey.Version
+			change.Relation = to.Nodes[c.ToIndex].Relation
+			change.ToScore = toScores[c.ToIndex]
+		}
+		change.ScoreDelta = scoreDelta(change.FromScore, change.ToScore)
+		response.Changes = append(response.Changes, change)
+
+		switch c.Kind {
+		case graph.Added:
+			response.Summary.Added++
+		case graph.Removed:
+			response.Summary.Removed++
+		case graph.Upgraded:
+			response.Summary.Upgraded++
+		case graph.Downgraded:
+			response.Summary.Downgraded++
+		case graph.MetadataChanged:
+			response.Summary.MetadataChanged++
+		}
+	}
+
+	response.Summary.FromAverage = averageScore(from, fromScores)
+	response.Summary.ToAverage = averageScore(to, toScores)
+	response.Summary.AverageDelta = scoreDelta(response.Summary.FromAverage, response.Summary.ToAverage)
+	return response
+}
+
+// averageScore is the mean score of the dependencies with a known score, -1 if none has one.
+func averageScore(g *models.DependencyGraph, scores []float64) float64 {
+	var sum float64
+	var known int
+	for i, node := range g.Nodes {
+		if node.Relation == "SELF" || scores[i] < 0 {
+			continue
+		}
+		sum += scores[i]
+		known++
+	}
+	if known == 0 {
+		return -1
+	}
+	return sum / float64(known)
+}
+
+func scoreDelta(from, to float64) *float64 {
+	if from < 0 || to < 0 {
+		return nil
+	}
+	delta := to - from
+	return &delta
+}
+
+var changeMarkers = map[string]string{
+	graph.Added:           "+",
+	graph.Removed:         "-",
+	graph.Upgraded:        "^",
+	graph.Downgraded:      "v",
+	graph.MetadataChanged: "~",
+}
+
+func writeDiffText(w io.Writer, d DiffResponse) {
+	fmt.Fprintf(w, "%s/%s %s -> %s\n", d.System, d.ProjectName, d.From, d.To)
+	fmt.Fprintf(w, "%d added, %d removed, %d upgraded, %d downgraded, %d metadata changed, %d unchanged\n",
+		d.Summary.Added, d.Summary.Removed, d.Summary.Upgraded, d.Summary.Downgraded, d.Summary.MetadataChanged, d.Summary.Unchanged)
+	fmt.Fprintf(w, "average score %s -> %s%s\n", formatScore(d.Summary.FromAverage), formatScore(d.Summary.ToAverage), formatDelta(d.Summary.AverageDelta))
+	if len(d.Changes) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range d.Changes {
+		versions := c.ToVersion
+		scores := formatScore(c.ToScore)
+		switch c.Change {
+		case graph.Removed:
+			versions = c.FromVersion
+			scores = formatScore(c.FromScore)
+		case graph.Upgraded, graph.Downgraded, graph.MetadataChanged:
+			versions = c.FromVersion + " -> " + c.ToVersion
+			scores = formatScore(c.FromScore) + " -> " + formatScore(c.ToScore) + formatDelta(c.ScoreDelta)
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\t%s\tscore %s\n", changeMarkers[c.Change], c.Name, versions, c.Relation, scores)
+	}
+	tw.Flush()
+}
+
+func formatScore(score float64) string {
+	if score < 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%.1f", score)
+}
+
+func formatDelta(delta *float64) string {
+	if delta == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (%+.1f)", *delta)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// runExport implements `codenotary export`, the command line twin of GET /sbom/.
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary export [flags] name[@version]")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient)
+	if err != nil {
+		return err
+	}
+	systemFlag := flags.String("system", "", "ecosystem of the package (GO, NPM, PYPI, ...), GO by default")
+	formatName := flags.String("format", "cyclonedx-json", "SBOM format: "+sbomFormatNames)
+	output := flags.String("o", "", "write the SBOM to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("name one package")
+	}
+	format, ok := sbomFormats[*formatName]
+	if !ok {
+		return fmt.Errorf("format must be %s", sbomFormatNames)
+	}
+
+	system, name, pinnedVersion, err := packageArg(*systemFlag, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	dependencyGraph, err := fetchGraph(system, name, pinnedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the dependencies of %s: %v", name, err)
+	}
+	input := sbomInput(system, name, dependencyGraph)
+
+	write := func(w io.Writer) error { return format.write(w, input) }
+	if *output != "" {
+		return writeReportFile(*output, write)
+	}
+	if err := write(os.Stdout); err != nil {
+		return fmt.Errorf("failed to write the SBOM: %v", err)
+	}
+	return nil
+}
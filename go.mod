@@ -2,4 +2,9 @@ module codenotary
 
 go 1.23.4
 
-require github.com/mattn/go-sqlite3 v1.14.24 // indirect
+require (
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.24
+	golang.org/x/mod v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
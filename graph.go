@@ -0,0 +1,336 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/graph"
+	"codenotary/internal/models"
+	"codenotary/internal/risk"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type GraphNode struct {
+	Index    int      `json:"index"`
+	System   string   `json:"system"`
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Relation string   `json:"relation"`
+	Bundled  bool     `json:"bundled"`
+	Errors   []string `json:"errors"`
+	Depth    int      `json:"depth"`
+	Score    float64  `json:"score"`
+}
+
+type GraphEdge struct {
+	From        int    `json:"from"`
+	To          int    `json:"to"`
+	Requirement string `json:"requirement"`
+}
+
+type GraphResponse struct {
+	ProjectName string      `json:"project_name"`
+	System      string      `json:"system"`
+	Version     string      `json:"version"`
+	Stale       bool        `json:"stale"`
+	FetchedAt   time.Time   `json:"fetched_at"`
+	Risk        risk.Result `json:"risk"`
+	Nodes       []GraphNode `json:"nodes"`
+	Edges       []GraphEdge `json:"edges"`
+}
+
+// riskQuery selects how the graph's aggregate risk score is computed.
+type riskQuery struct {
+	strategy string
+	options  risk.Options
+}
+
+var defaultRiskQuery = riskQuery{strategy: risk.DefaultStrategy, options: risk.DefaultOptions}
+
+// parseRiskQuery reads risk={strategy} and the weights that override
+// risk.DefaultOptions: direct_weight, indirect_weight, depth_decay, fan_in_weight
+// and missing_score.
+func parseRiskQuery(query url.Values) (riskQuery, error) {
+	q := defaultRiskQuery
+	if strategy := query.Get("risk"); strategy != "" {
+		q.strategy = strategy
+	}
+	if _, ok := risk.Strategies[q.strategy]; !ok {
+		return q, fmt.Errorf("risk must be one of %s", strings.Join(risk.StrategyNames(), ", "))
+	}
+	for name, value := range map[string]*float64{
+		"direct_weight":   &q.options.DirectWeight,
+		"indirect_weight": &q.options.IndirectWeight,
+		"depth_decay":     &q.options.DepthDecay,
+		"fan_in_weight":   &q.options.FanInWeight,
+		"missing_score":   &q.options.MissingScore,
+	} {
+		if s := query.Get(name); s != "" {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return q, fmt.Errorf("%s must be a number", name)
+			}
+			*value = v
+		}
+	}
+	return q, q.options.Validate()
+}
+
+// HandleGetGraph serves GET /graph/{name}[@version]?depth=N&relation=DIRECT,INDIRECT
+// with the nodes and edges of the stored dependency graph and its aggregate risk
+// score (see parseRiskQuery). Node indexes are the ones deps.dev assigned, so
+// edges stay valid whatever the filters drop.
+// GET /graph/{name}/why/{dependency} is handed to handleWhy.
+func HandleGetGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trimmedPath := strings.TrimPrefix(r.URL.Path, "/graph/")
+	if project, dependency, ok := strings.Cut(trimmedPath, "/why/"); ok {
+		handleWhy(w, r, project, dependency)
+		return
+	}
+
+	system, projectName := parseSystemAndName(trimmedPath)
+	if projectName == "" {
+		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
+		return
+	}
+	projectName, pinnedVersion := splitVersion(projectName)
+
+	var filter graph.Filter
+	if depth := r.URL.Query().Get("depth"); depth != "" {
+		var err error
+		filter.MaxDepth, err = strconv.Atoi(depth)
+		if err != nil || filter.MaxDepth < 1 {
+			http.Error(w, "depth must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+	if relation := r.URL.Query().Get("relation"); relation != "" {
+		filter.Relations = strings.Split(relation, ",")
+	}
+	riskQuery, err := parseRiskQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	response := graphResponse(system, projectName, dependencyGraph, filter, riskQuery)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+// graphResponse lists the nodes the filter selects, with their depth and score,
+// and the edges between them. The risk score covers the whole graph, whatever
+// the filter drops.
+func graphResponse(system, projectName string, dependencyGraph *models.DependencyGraph, filter graph.Filter, riskQuery riskQuery) GraphResponse {
+	selected := graph.Select(dependencyGraph, filter)
+	scores := nodeScores(dependencyGraph)
+	depths := graph.Depths(dependencyGraph)
+
+	response := GraphResponse{
+		ProjectName: projectName,
+		System:      system,
+		Version:     selfVersion(dependencyGraph),
+		Stale:       dependencyGraph.Stale,
+		FetchedAt:   dependencyGraph.FetchedAt,
+		Nodes:       []GraphNode{},
+		Edges:       []GraphEdge{},
+	}
+	// parseRiskQuery has validated the query.
+	response.Risk, _ = risk.Aggregate(dependencyGraph, scores, riskQuery.strategy, riskQuery.options)
+
+	for _, i := range selected {
+		node := dependencyGraph.Nodes[i]
+		errors := node.Errors
+		if errors == nil {
+			errors = []string{}
+		}
+		response.Nodes = append(response.Nodes, GraphNode{
+			Index:    i,
+			System:   node.VersionKey.System,
+			Name:     node.VersionKey.Name,
+			Version:  node.VersionKey.Version,
+			Relation: node.Relation,
+			Bundled:  node.Bundled,
+			Errors:   errors,
+			Depth:    depths[i],
+			Score:    scores[i],
+		})
+	}
+	for _, edge := range graph.EdgesBetween(dependencyGraph, selected) {
+		response.Edges = append(response.Edges, GraphEdge{
+			From:        edge.FromNode,
+			To:          edge.ToNode,
+			Requirement: edge.Requirement,
+		})
+	}
+	return response
+}
+
+type PathHop struct {
+	Index       int    `json:"index"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Relation    string `json:"relation"`
+	Requirement string `json:"requirement,omitempty"`
+}
+
+// handleWhy serves GET /graph/{name}[@version]/why/{dependency}[@version]?limit=N:
+// the paths from the root to every node of the dependency, shortest first, with
+// the requirement each hop was resolved from.
+func handleWhy(w http.ResponseWriter, r *http.Request, project, dependency string) {
+	system, projectName := parseSystemAndName(project)
+	if projectName == "" || dependency == "" {
+		http.Error(w, "Invalid URL format. Use /graph/{project}/why/{dependency}", http.StatusBadRequest)
+		return
+	}
+	projectName, pinnedVersion := splitVersion(projectName)
+	dependencyName, dependencyVersion := splitVersion(dependency)
+
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	var targets []int
+	for i, node := range dependencyGraph.Nodes {
+		if node.VersionKey.Name == dependencyName && (dependencyVersion == "" || node.VersionKey.Version == dependencyVersion) {
+			targets = append(targets, i)
+		}
+	}
+	if len(targets) == 0 {
+		http.Error(w, fmt.Sprintf("%s is not in the dependency graph of %s", dependency, projectName), http.StatusNotFound)
+		return
+	}
+
+	paths, truncated := graph.Paths(dependencyGraph, targets, limit)
+
+	type Path struct {
+		Length int       `json:"length"`
+		Hops   []PathHop `json:"hops"`
+	}
+	response := struct {
+		ProjectName string `json:"project_name"`
+		System      string `json:"system"`
+		Version     string `json:"version"`
+		Dependency  string `json:"dependency"`
+		Paths       []Path `json:"paths"`
+		Truncated   bool   `json:"truncated"`
+	}{
+		ProjectName: projectName,
+		System:      system,
+		Version:     selfVersion(dependencyGraph),
+		Dependency:  dependency,
+		Paths:       []Path{},
+		Truncated:   truncated,
+	}
+
+	hop := func(index int, requirement string) PathHop {
+		node := dependencyGraph.Nodes[index]
+		return PathHop{
+			Index:       index,
+			Name:        node.VersionKey.Name,
+			Version:     node.VersionKey.Version,
+			Relation:    node.Relation,
+			Requirement: requirement,
+		}
+	}
+	root := graph.Root(dependencyGraph)
+	for _, path := range paths {
+		hops := []PathHop{hop(root, "")}
+		for _, edge := range path {
+			hops = append(hops, hop(edge.ToNode, edge.Requirement))
+		}
+		response.Paths = append(response.Paths, Path{Length: len(path), Hops: hops})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+// fetchGraph returns the graph of the pinned version, or of the latest one.
+func fetchGraph(system, name, pinnedVersion string) (*models.DependencyGraph, error) {
+	if pinnedVersion != "" {
+		return internal.Client.GetDependenciesAtVersion(system, name, pinnedVersion)
+	}
+	return internal.Client.GetDependencies(system, name)
+}
+
+// nodeScores returns the OpenSSF score of every node, -1 when its project is unknown.
+func nodeScores(dependencyGraph *models.DependencyGraph) []float64 {
+	projects := nodeProjects(dependencyGraph)
+	scores := make([]float64, len(projects))
+	for i, project := range projects {
+		scores[i] = -1
+		if project != nil {
+			scores[i] = project.Scorecard.OverallScore
+		}
+	}
+	return scores
+}
+
+// nodeProjects resolves the project of every node, nil where it is unknown.
+// Fetched projects are stored so later requests hit the database.
+func nodeProjects(dependencyGraph *models.DependencyGraph) []*models.Project {
+	projects := internal.Client.GetNodeProjects(dependencyGraph)
+
+	var found []*models.Project
+	for _, project := range projects {
+		if project != nil {
+			found = append(found, project)
+		}
+	}
+	if err := internal.Store.InsertProjects(found); err != nil {
+		slog.Error("storing projects", "err", err)
+	}
+	return projects
+}
+
+// nodeChecks returns the stored scorecard check scores of every project, nil where
+// the project is unknown.
+func nodeChecks(projects []*models.Project) []map[string]int {
+	checks := make([]map[string]int, len(projects))
+	for i, project := range projects {
+		if project == nil {
+			continue
+		}
+		scores, err := internal.Store.GetScoresByProjectID(project.ProjectKey.ID)
+		if err != nil {
+			slog.Error("fetching scores", "project", project.ProjectKey.ID, "err", err)
+			continue
+		}
+		checks[i] = scores
+	}
+	return checks
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"codenotary/internal/graph"
+	"codenotary/internal/risk"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// runGraph implements `codenotary graph`, the command line twin of GET /graph/.
+func runGraph(args []string) error {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary graph [flags] name[@version]")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient)
+	if err != nil {
+		return err
+	}
+	systemFlag := flags.String("system", "", "ecosystem of the package (GO, NPM, PYPI, ...), GO by default")
+	var filter graph.Filter
+	flags.IntVar(&filter.MaxDepth, "depth", 0, "only show dependencies up to this depth, 0 for all")
+	relations := flags.String("relation", "", "only show these relations, comma separated: DIRECT, INDIRECT")
+	asJSON := flags.Bool("json", false, "print the API's JSON response instead of a tree")
+	// The risk flags take the values of GET /graph/'s query parameters.
+	riskValues := url.Values{}
+	for _, f := range []struct{ flag, param, usage string }{
+		{"risk", "risk", "aggregate risk strategy: " + strings.Join(risk.StrategyNames(), ", ") + " (default " + risk.DefaultStrategy + ")"},
+		{"missing-score", "missing_score", "score assumed for dependencies without a scorecard (default 0)"},
+		{"depth-decay", "depth_decay", "weight multiplier per level below the direct dependencies (default 0.8)"},
+	} {
+		param := f.param
+		flags.Func(f.flag, f.usage, func(value string) error {
+			riskValues.Set(param, value)
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("name one package")
+	}
+	if filter.MaxDepth < 0 {
+		return fmt.Errorf("depth must not be negative")
+	}
+	if *relations != "" {
+		filter.Relations = strings.Split(*relations, ",")
+	}
+	riskQuery, err := parseRiskQuery(riskValues)
+	if err != nil {
+		return err
+	}
+
+	system, name, pinnedVersion, err := packageArg(*systemFlag, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	dependencyGraph, err := fetchGraph(system, name, pinnedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the dependencies of %s: %v", name, err)
+	}
+	response := graphResponse(system, name, dependencyGraph, filter, riskQuery)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	}
+	writeGraphTree(os.Stdout, response)
+	return nil
+}
+
+// writeGraphTree prints the selected nodes as a tree below the root. A node
+// reachable along several paths is expanded once; later occurrences end in "(*)".
+// Selected nodes the tree doesn't reach are listed after it.
+func writeGraphTree(w io.Writer, g GraphResponse) {
+	nodes := make(map[int]GraphNode)
+	for _, node := range g.Nodes {
+		nodes[node.Index] = node
+	}
+	children := make(map[int][]int)
+	for _, edge := range g.Edges {
+		children[edge.From] = append(children[edge.From], edge.To)
+	}
+
+	printed := make(map[int]bool)
+	var walk func(index, level int)
+	walk = func(index, level int) {
+		node := nodes[index]
+		line := fmt.Sprintf("%s%s %s", strings.Repeat("  ", level), node.Name, node.Version)
+		if node.Relation != "SELF" {
+			line += fmt.Sprintf("  %s, score %s", node.Relation, formatScore(node.Score))
+		}
+		if printed[index] {
+			if len(children[index]) > 0 {
+				line += " (*)"
+			}
+			fmt.Fprintln(w, line)
+			return
+		}
+		fmt.Fprintln(w, line)
+		printed[index] = true
+		for _, child := range children[index] {
+			walk(child, level+1)
+		}
+	}
+
+	fmt.Fprintf(w, "%s/%s@%s\n", g.System, g.ProjectName, g.Version)
+	fmt.Fprintf(w, "risk score %s (%s), %d dependencies, %d without a score\n",
+		formatScore(g.Risk.Score), g.Risk.Strategy, g.Risk.Dependencies, g.Risk.Unscored)
+	for _, node := range g.Nodes {
+		if node.Relation == "SELF" {
+			walk(node.Index, 0)
+		}
+	}
+	for _, node := range g.Nodes {
+		if !printed[node.Index] {
+			walk(node.Index, 0)
+		}
+	}
+}
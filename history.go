@@ -0,0 +1,72 @@
+package main
+
+import (
+	"codenotary/internal"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HandleProjectHistory serves GET /projects/{projectID}/history.
+func HandleProjectHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	trimmedPath := strings.TrimPrefix(r.URL.Path, "/projects/")
+	projectID := strings.TrimSuffix(trimmedPath, "/history")
+	if trimmedPath == r.URL.Path || projectID == trimmedPath || projectID == "" {
+		http.Error(w, "Invalid URL format. Use /projects/{projectID}/history", http.StatusBadRequest)
+		return
+	}
+
+	snapshots, err := internal.Store.GetScorecardHistory(projectID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error retrieving scorecard history: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if len(snapshots) == 0 {
+		http.Error(w, "No scorecard history for this project", http.StatusNotFound)
+		return
+	}
+
+	type Snapshot struct {
+		Date             string             `json:"date"`
+		RepositoryCommit string             `json:"repository_commit"`
+		ScorecardVersion string             `json:"scorecard_version"`
+		OverallScore     float64            `json:"overall_score"`
+		OverallDelta     float64            `json:"overall_delta"`
+		Checks           map[string]float64 `json:"checks"`
+		FetchedAt        time.Time          `json:"fetched_at"`
+	}
+
+	response := struct {
+		ProjectID string     `json:"project_id"`
+		Snapshots []Snapshot `json:"snapshots"`
+	}{
+		ProjectID: projectID,
+		Snapshots: []Snapshot{},
+	}
+
+	for i, s := range snapshots {
+		delta := 0.0
+		if i > 0 {
+			delta = s.OverallScore - snapshots[i-1].OverallScore
+		}
+		response.Snapshots = append(response.Snapshots, Snapshot{
+			Date:             s.Date,
+			RepositoryCommit: s.RepositoryCommit,
+			ScorecardVersion: s.ScorecardVersion,
+			OverallScore:     s.OverallScore,
+			OverallDelta:     delta,
+			Checks:           s.Checks,
+			FetchedAt:        s.FetchedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,299 @@
+// Package config holds every runtime setting of the service. Settings come from,
+// in increasing precedence: the defaults, a YAML (or JSON) file, CODENOTARY_*
+// environment variables and command line flags.
+package config
+
+import (
+	"bytes"
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvFile names the configuration file when -config isn't given.
+const EnvFile = "CODENOTARY_CONFIG"
+
+type Config struct {
+	Store    Store   `yaml:"store"`
+	DepsDev  DepsDev `yaml:"depsdev"`
+	Cache    Cache   `yaml:"cache"`
+	Server   Server  `yaml:"server"`
+	Policy   string  `yaml:"policy"`
+	Profiles string  `yaml:"profiles"`
+	LogLevel string  `yaml:"log_level"`
+}
+
+type Store struct {
+	Backend string `yaml:"backend"`
+	// DSN is the SQLite file or the PostgreSQL connection string.
+	DSN string `yaml:"dsn"`
+}
+
+type DepsDev struct {
+	URL            string        `yaml:"url"`
+	Timeout        time.Duration `yaml:"timeout"`
+	MaxConcurrency int           `yaml:"max_concurrency"`
+	CassetteMode   string        `yaml:"cassette_mode"`
+	CassetteDir    string        `yaml:"cassette_dir"`
+}
+
+type Cache struct {
+	TTLProject      time.Duration `yaml:"ttl_project"`
+	TTLPackage      time.Duration `yaml:"ttl_package"`
+	TTLGraph        time.Duration `yaml:"ttl_graph"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	RefreshBatch    int           `yaml:"refresh_batch"`
+}
+
+type Server struct {
+	Addr         string        `yaml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+	// CORSOrigins may call the API from a browser; "*" allows any origin.
+	CORSOrigins []string `yaml:"cors_origins"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Store: Store{Backend: internal.BackendSQLite, DSN: internal.Database},
+		DepsDev: DepsDev{
+			URL:            deps.DefaultBaseURL,
+			Timeout:        30 * time.Second,
+			MaxConcurrency: 16,
+			CassetteMode:   string(deps.CassetteOff),
+		},
+		Cache: Cache{
+			TTLProject:      deps.DefaultTTLs.Project,
+			TTLPackage:      deps.DefaultTTLs.Package,
+			TTLGraph:        deps.DefaultTTLs.Graph,
+			RefreshInterval: time.Hour,
+			RefreshBatch:    50,
+		},
+		Server: Server{
+			Addr:         ":8080",
+			ReadTimeout:  time.Minute,
+			WriteTimeout: 5 * time.Minute,
+			IdleTimeout:  2 * time.Minute,
+		},
+		LogLevel: "info",
+	}
+}
+
+// Groups of settings, so each command only offers the flags it uses.
+const (
+	GroupStore  = 1 << iota // the database
+	GroupClient             // deps.dev, the cache and logging
+	GroupServer             // the HTTP API and the background refresher
+)
+
+// setting binds one field to its file key, environment variable and flag.
+type setting struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	group int
+	value func(c *Config) flag.Value
+}
+
+var settings = []setting{
+	{"store.backend", "CODENOTARY_STORE", "backend", "storage backend: sqlite or postgres", GroupStore,
+		func(c *Config) flag.Value { return (*stringValue)(&c.Store.Backend) }},
+	{"store.dsn", "CODENOTARY_DSN", "db", "SQLite database file or PostgreSQL connection string", GroupStore,
+		func(c *Config) flag.Value { return (*stringValue)(&c.Store.DSN) }},
+
+	{"depsdev.url", "CODENOTARY_DEPS_URL", "deps-url", "deps.dev API base URL", GroupClient,
+		func(c *Config) flag.Value { return (*stringValue)(&c.DepsDev.URL) }},
+	{"depsdev.timeout", "CODENOTARY_DEPS_TIMEOUT", "deps-timeout", "timeout of one deps.dev request, 0 for none", GroupClient,
+		func(c *Config) flag.Value { return (*durationValue)(&c.DepsDev.Timeout) }},
+	{"depsdev.max_concurrency", "CODENOTARY_DEPS_MAX_CONCURRENCY", "deps-max-concurrency", "deps.dev requests in flight at once, 0 for no limit", GroupClient,
+		func(c *Config) flag.Value { return (*intValue)(&c.DepsDev.MaxConcurrency) }},
+	{"depsdev.cassette_mode", "CODENOTARY_CASSETTE_MODE", "cassette-mode", "record or replay deps.dev traffic: off, record or replay", GroupClient,
+		func(c *Config) flag.Value { return (*stringValue)(&c.DepsDev.CassetteMode) }},
+	{"depsdev.cassette_dir", "CODENOTARY_CASSETTE_DIR", "cassette-dir", "cassette directory", GroupClient,
+		func(c *Config) flag.Value { return (*stringValue)(&c.DepsDev.CassetteDir) }},
+	{"cache.ttl_project", "CODENOTARY_TTL_PROJECT", "ttl-project", "how long a project and its scorecard stay fresh, 0 never expires", GroupClient,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Cache.TTLProject) }},
+	{"cache.ttl_package", "CODENOTARY_TTL_PACKAGE", "ttl-package", "how long a package's version list stays fresh, 0 never expires", GroupClient,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Cache.TTLPackage) }},
+	{"cache.ttl_graph", "CODENOTARY_TTL_GRAPH", "ttl-graph", "how long a dependency graph stays fresh, 0 never expires", GroupClient,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Cache.TTLGraph) }},
+	{"log_level", "CODENOTARY_LOG_LEVEL", "log-level", "debug, info, warn or error", GroupStore | GroupClient,
+		func(c *Config) flag.Value { return (*stringValue)(&c.LogLevel) }},
+
+	{"cache.refresh_interval", "CODENOTARY_REFRESH_INTERVAL", "refresh-interval", "how often stale data is re-fetched, 0 disables it", GroupServer,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Cache.RefreshInterval) }},
+	{"cache.refresh_batch", "CODENOTARY_REFRESH_BATCH", "refresh-batch", "stale entries of each kind re-fetched per refresh", GroupServer,
+		func(c *Config) flag.Value { return (*intValue)(&c.Cache.RefreshBatch) }},
+	{"server.addr", "CODENOTARY_ADDR", "addr", "address the API listens on", GroupServer,
+		func(c *Config) flag.Value { return (*stringValue)(&c.Server.Addr) }},
+	{"server.read_timeout", "CODENOTARY_READ_TIMEOUT", "read-timeout", "how long reading a request may take, 0 for no limit", GroupServer,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Server.ReadTimeout) }},
+	{"server.write_timeout", "CODENOTARY_WRITE_TIMEOUT", "write-timeout", "how long answering a request may take, 0 for no limit", GroupServer,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Server.WriteTimeout) }},
+	{"server.idle_timeout", "CODENOTARY_IDLE_TIMEOUT", "idle-timeout", "how long an idle keep-alive connection stays open, 0 for no limit", GroupServer,
+		func(c *Config) flag.Value { return (*durationValue)(&c.Server.IdleTimeout) }},
+	{"server.cors_origins", "CODENOTARY_CORS_ORIGINS", "cors-origins", "comma separated origins allowed to call the API from a browser, * for any", GroupServer,
+		func(c *Config) flag.Value { return (*listValue)(&c.Server.CORSOrigins) }},
+	{"policy", "CODENOTARY_POLICY", "policy", "default policy of /policy/evaluate/", GroupServer,
+		func(c *Config) flag.Value { return (*stringValue)(&c.Policy) }},
+	{"profiles", "CODENOTARY_PROFILES", "profiles", "scoring profiles file for ?profile=", GroupServer,
+		func(c *Config) flag.Value { return (*stringValue)(&c.Profiles) }},
+}
+
+// Load builds the configuration of a command and registers the flags of the given
+// groups, plus -config, on flags. The defaults are overridden by the file named by
+// -config (or CODENOTARY_CONFIG), then by the environment; the flags, once parsed,
+// override both. Call Validate after flags.Parse.
+func Load(flags *flag.FlagSet, args []string, groups int, getenv func(string) string) (*Config, error) {
+	c := Default()
+
+	path := configArg(args)
+	if path == "" {
+		path = getenv(EnvFile)
+	}
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	for _, s := range settings {
+		if value := getenv(s.env); value != "" {
+			if err := s.value(c).Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", s.env, err))
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	flags.String("config", path, "YAML or JSON configuration file ("+EnvFile+")")
+	for _, s := range settings {
+		if s.group&groups != 0 {
+			flags.Var(s.value(c), s.flag, s.usage+" ("+s.env+")")
+		}
+	}
+	return c, nil
+}
+
+// configArg finds the value of -config before the flags are parsed.
+func configArg(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration: %v", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once, each named by its file key.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	switch c.Store.Backend {
+	case internal.BackendSQLite, internal.BackendPostgres:
+		if c.Store.DSN == "" {
+			invalid("store.dsn", "the %s backend needs a DSN", c.Store.Backend)
+		}
+	default:
+		invalid("store.backend", "unknown backend %q, use %s or %s", c.Store.Backend, internal.BackendSQLite, internal.BackendPostgres)
+	}
+
+	if u, err := url.Parse(c.DepsDev.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid("depsdev.url", "%q is not an http(s) URL", c.DepsDev.URL)
+	}
+	if c.DepsDev.MaxConcurrency < 0 {
+		invalid("depsdev.max_concurrency", "must not be negative")
+	}
+	if mode, err := deps.ParseCassetteMode(c.DepsDev.CassetteMode); err != nil {
+		invalid("depsdev.cassette_mode", "%v", err)
+	} else if mode != deps.CassetteOff && c.DepsDev.CassetteDir == "" {
+		invalid("depsdev.cassette_dir", "cassette mode %s needs a directory", mode)
+	}
+
+	for key, d := range map[string]time.Duration{
+		"depsdev.timeout":        c.DepsDev.Timeout,
+		"cache.ttl_project":      c.Cache.TTLProject,
+		"cache.ttl_package":      c.Cache.TTLPackage,
+		"cache.ttl_graph":        c.Cache.TTLGraph,
+		"cache.refresh_interval": c.Cache.RefreshInterval,
+		"server.read_timeout":    c.Server.ReadTimeout,
+		"server.write_timeout":   c.Server.WriteTimeout,
+		"server.idle_timeout":    c.Server.IdleTimeout,
+	} {
+		if d < 0 {
+			invalid(key, "must not be negative")
+		}
+	}
+	if c.Cache.RefreshBatch < 1 {
+		invalid("cache.refresh_batch", "must be at least 1")
+	}
+
+	if _, port, err := net.SplitHostPort(c.Server.Addr); err != nil || port == "" {
+		invalid("server.addr", "%q is not a host:port address", c.Server.Addr)
+	}
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			invalid("server.cors_origins", "%q is not an origin like https://example.com", origin)
+		}
+	}
+
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		invalid("log_level", "unknown level %q, use debug, info, warn or error", c.LogLevel)
+	}
+
+	// Map iteration makes the duration errors come in random order.
+	sortErrors(errs)
+	return errors.Join(errs...)
+}
+
+// TTLs are the cache settings in the form deps.WithTTLs takes.
+func (c *Config) TTLs() deps.TTLs {
+	return deps.TTLs{Project: c.Cache.TTLProject, Package: c.Cache.TTLPackage, Graph: c.Cache.TTLGraph}
+}
@@ -0,0 +1,150 @@
+package config_test
+
+import (
+	"codenotary/internal/config"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testConfig = `
+store:
+  dsn: from-file.db
+depsdev:
+  timeout: 10s
+  max_concurrency: 4
+server:
+  addr: ":9000"
+  cors_origins: [https://app.example.com]
+log_level: debug
+`
+
+func load(t *testing.T, args []string, env map[string]string) (*config.Config, error) {
+	t.Helper()
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	cfg, err := config.Load(flags, args, config.GroupStore|config.GroupClient|config.GroupServer, func(name string) string {
+		return env[name]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "codenotary.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDefaultIsValid(t *testing.T) {
+	cfg, err := load(t, nil, nil)
+	if err != nil {
+		t.Fatalf("default configuration: %v", err)
+	}
+	if cfg.Server.Addr != ":8080" || cfg.Store.DSN != "codenotarydatabase.db" {
+		t.Errorf("addr %q, dsn %q", cfg.Server.Addr, cfg.Store.DSN)
+	}
+}
+
+func TestPrecedence(t *testing.T) {
+	path := writeConfig(t, testConfig)
+
+	cfg, err := load(t, []string{"-config", path}, nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Store.DSN != "from-file.db" || cfg.DepsDev.Timeout != 10*time.Second || cfg.DepsDev.MaxConcurrency != 4 {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.Cache.RefreshBatch != 50 {
+		t.Errorf("refresh batch = %d, want the default 50", cfg.Cache.RefreshBatch)
+	}
+
+	env := map[string]string{
+		config.EnvFile:         path,
+		"CODENOTARY_DSN":       "from-env.db",
+		"CODENOTARY_ADDR":      ":9100",
+		"CODENOTARY_LOG_LEVEL": "warn",
+	}
+	cfg, err = load(t, []string{"-addr=:9200"}, env)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Store.DSN != "from-env.db" {
+		t.Errorf("dsn = %q, the environment should override the file", cfg.Store.DSN)
+	}
+	if cfg.Server.Addr != ":9200" {
+		t.Errorf("addr = %q, the flag should override the environment", cfg.Server.Addr)
+	}
+	if cfg.LogLevel != "warn" || cfg.DepsDev.MaxConcurrency != 4 {
+		t.Errorf("log level %q, max concurrency %d", cfg.LogLevel, cfg.DepsDev.MaxConcurrency)
+	}
+	if len(cfg.Server.CORSOrigins) != 1 || cfg.Server.CORSOrigins[0] != "https://app.example.com" {
+		t.Errorf("cors origins = %v", cfg.Server.CORSOrigins)
+	}
+}
+
+func TestListFromEnvironment(t *testing.T) {
+	cfg, err := load(t, nil, map[string]string{"CODENOTARY_CORS_ORIGINS": "https://a.example.com, http://localhost:3000"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Server.CORSOrigins) != 2 || cfg.Server.CORSOrigins[1] != "http://localhost:3000" {
+		t.Errorf("cors origins = %q", cfg.Server.CORSOrigins)
+	}
+}
+
+func TestUnknownFileKey(t *testing.T) {
+	path := writeConfig(t, "server:\n  port: 8080\n")
+	if _, err := load(t, []string{"-config=" + path}, nil); err == nil || !strings.Contains(err.Error(), "port") {
+		t.Errorf("err = %v, want the unknown key named", err)
+	}
+}
+
+func TestInvalidEnvironment(t *testing.T) {
+	_, err := load(t, nil, map[string]string{"CODENOTARY_DEPS_TIMEOUT": "soon"})
+	if err == nil || !strings.Contains(err.Error(), "CODENOTARY_DEPS_TIMEOUT") {
+		t.Errorf("err = %v, want the variable named", err)
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	path := writeConfig(t, `
+store:
+  backend: mysql
+depsdev:
+  url: api.deps.dev
+  max_concurrency: -1
+  cassette_mode: replay
+cache:
+  ttl_graph: -1h
+server:
+  addr: localhost
+  cors_origins: ["*", example.com]
+log_level: verbose
+`)
+	_, err := load(t, []string{"-config", path}, nil)
+	if err == nil {
+		t.Fatal("Validate accepted an invalid configuration")
+	}
+	for _, key := range []string{
+		"store.backend", "depsdev.url", "depsdev.max_concurrency", "depsdev.cassette_dir",
+		"cache.ttl_graph", "server.addr", "server.cors_origins", "log_level",
+	} {
+		if !strings.Contains(err.Error(), key+":") {
+			t.Errorf("no error for %s in:\n%v", key, err)
+		}
+	}
+}
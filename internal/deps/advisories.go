@@ -0,0 +1,157 @@
+package deps
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/osv"
+	"codenotary/internal/store"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// GetVersionAdvisories returns the advisories affecting a package version: those
+// deps.dev lists for it, fetched again once older than the package TTL, and the
+// imported OSV records whose affected versions contain it. The error reports that
+// deps.dev couldn't be asked; the advisories known locally are still returned.
+func (c *Client) GetVersionAdvisories(system, name, version string) ([]models.Advisory, error) {
+	key := models.VersionKey{System: system, Name: name, Version: version}
+	ids, fetchedAt, err := c.store.GetVersionAdvisories(key)
+	if err != nil {
+		return nil, err
+	}
+	var fetchErr error
+	if fetchedAt.IsZero() || c.expired(fetchedAt, c.ttls.Package) {
+		// GetVersion records the advisory keys.
+		if details, err := c.GetVersion(system, name, version); err != nil {
+			fetchErr = fmt.Errorf("couldn't get the advisories of %s/%s@%s: %w", system, name, version, err)
+		} else {
+			ids = advisoryIDs(details)
+		}
+	}
+
+	advisories, err := c.getAdvisories(ids)
+	if err != nil {
+		return nil, err
+	}
+	imported, err := c.store.ListPackageAdvisories(system, name)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(advisories))
+	for _, a := range advisories {
+		known[a.AdvisoryKey.ID] = true
+	}
+	for _, a := range imported {
+		if !known[a.AdvisoryKey.ID] && osv.Affects(a, system, name, version) {
+			advisories = append(advisories, a)
+		}
+	}
+	sort.Slice(advisories, func(i, j int) bool { return advisories[i].AdvisoryKey.ID < advisories[j].AdvisoryKey.ID })
+	return advisories, fetchErr
+}
+
+// getAdvisories returns the advisories with these ids, fetching the ones not stored
+// yet. An advisory deps.dev can't serve is returned with its id only.
+func (c *Client) getAdvisories(ids []string) ([]models.Advisory, error) {
+	advisories, err := c.store.GetAdvisories(ids)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(advisories))
+	for _, a := range advisories {
+		stored[a.AdvisoryKey.ID] = true
+	}
+
+	var fetched []models.Advisory
+	for _, id := range ids {
+		if stored[id] {
+			continue
+		}
+		advisory, err := c.fetchAdvisory(id)
+		if err != nil {
+			slog.Error("fetching advisory", "id", id, "err", err)
+			advisories = append(advisories, models.Advisory{
+				AdvisoryKey: models.AdvisoryKey{ID: id},
+				CVSS3Score:  -1,
+				Severity:    osv.SeverityUnknown,
+			})
+			continue
+		}
+		fetched = append(fetched, *advisory)
+	}
+	if len(fetched) > 0 {
+		if err := c.store.InsertAdvisories(fetched); err != nil {
+			slog.Error("storing advisories", "err", err)
+		}
+	}
+	return append(advisories, fetched...), nil
+}
+
+func (c *Client) fetchAdvisory(id string) (*models.Advisory, error) {
+	url := c.baseURL + "/advisories/" + url.PathEscape(id)
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't make the get request to %q: %w", url, err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read all of the body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deps.dev returned %s for %q", resp.Status, url)
+	}
+
+	var advisory models.Advisory
+	if err := json.Unmarshal(body, &advisory); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JSON response: %v", err)
+	}
+	if advisory.CVSS3Vector == "" {
+		advisory.CVSS3Score = -1
+	}
+	advisory.Severity = osv.Severity(advisory.CVSS3Score, "")
+	advisory.Source = store.SourceDepsDev
+	advisory.FetchedAt = c.now()
+	return &advisory, nil
+}
+
+// storeAdvisoryKeys records the advisory keys of a version response.
+func (c *Client) storeAdvisoryKeys(details *models.VersionDetails) {
+	if err := c.store.SetVersionAdvisories(details.VersionKey, advisoryIDs(details), c.now()); err != nil {
+		slog.Error("storing advisory keys", "system", details.VersionKey.System, "name", details.VersionKey.Name, "version", details.VersionKey.Version, "err", err)
+	}
+}
+
+func advisoryIDs(details *models.VersionDetails) []string {
+	ids := make([]string, 0, len(details.AdvisoryKeys))
+	for _, key := range details.AdvisoryKeys {
+		ids = append(ids, key.ID)
+	}
+	return ids
+}
+
+// GetNodeAdvisories returns the advisories of every node, SELF included, aligned
+// with graph.Nodes. errs holds, also aligned, the nodes deps.dev couldn't be asked
+// about; their advisories are the ones known locally.
+func (c *Client) GetNodeAdvisories(graph *models.DependencyGraph) (advisories [][]models.Advisory, errs []error) {
+	advisories = make([][]models.Advisory, len(graph.Nodes))
+	errs = make([]error, len(graph.Nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range graph.Nodes {
+		wg.Add(1)
+		go func(i int, key models.VersionKey) {
+			defer wg.Done()
+			advisories[i], errs[i] = c.GetVersionAdvisories(key.System, key.Name, key.Version)
+		}(i, node.VersionKey)
+	}
+	wg.Wait()
+
+	return advisories, errs
+}
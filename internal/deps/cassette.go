@@ -0,0 +1,191 @@
+package deps
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+type CassetteMode string
+
+const (
+	CassetteOff    CassetteMode = "off"
+	CassetteRecord CassetteMode = "record"
+	CassetteReplay CassetteMode = "replay"
+)
+
+var ErrCassetteMiss = errors.New("request not recorded in cassette")
+
+func ParseCassetteMode(s string) (CassetteMode, error) {
+	switch mode := CassetteMode(strings.ToLower(s)); mode {
+	case "", CassetteOff:
+		return CassetteOff, nil
+	case CassetteRecord, CassetteReplay:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown cassette mode %q, use off, record or replay", s)
+}
+
+// WithCassette records every deps.dev exchange into dir, or serves exclusively from
+// dir, depending on mode. Replay never touches the network and fails on a miss.
+// The cassette wraps the transport the other options end up with, whatever their
+// order.
+func WithCassette(dir string, mode CassetteMode) Option {
+	return func(c *Client) {
+		c.cassetteDir = dir
+		c.cassetteMode = mode
+	}
+}
+
+// useCassette installs the cassette chosen with WithCassette, once every option has
+// set the http.Client it wraps.
+func (c *Client) useCassette() {
+	if c.cassetteMode == CassetteOff || c.cassetteMode == "" {
+		return
+	}
+	next := c.httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	WithTransport(&cassetteTransport{dir: c.cassetteDir, mode: c.cassetteMode, next: next})(c)
+}
+
+type cassetteEntry struct {
+	Method     string              `json:"method"`
+	URL        string              `json:"url"`
+	StatusCode int                 `json:"status_code"`
+	Status     string              `json:"status"`
+	Header     map[string][]string `json:"header"`
+	Body       *string             `json:"body,omitempty"`
+	BodyBase64 string              `json:"body_base64,omitempty"`
+}
+
+type cassetteTransport struct {
+	dir  string
+	mode CassetteMode
+	next http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	file := filepath.Join(t.dir, cassetteFileName(req))
+	if t.mode == CassetteReplay {
+		return t.replay(req, file)
+	}
+	return t.record(req, file)
+}
+
+func (t *cassetteTransport) replay(req *http.Request, file string) (*http.Response, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s (looked for %s)", ErrCassetteMiss, req.Method, req.URL.RequestURI(), file)
+	} else if err != nil {
+		return nil, fmt.Errorf("couldn't read cassette %s: %v", file, err)
+	}
+
+	var entry cassetteEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("couldn't parse cassette %s: %v", file, err)
+	}
+
+	var body []byte
+	if entry.Body != nil {
+		body = []byte(*entry.Body)
+	} else if body, err = base64.StdEncoding.DecodeString(entry.BodyBase64); err != nil {
+		return nil, fmt.Errorf("couldn't decode body in cassette %s: %v", file, err)
+	}
+
+	return &http.Response{
+		Status:        entry.Status,
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header(entry.Header),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request, file string) (*http.Response, error) {
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read response to record: %v", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry := cassetteEntry{
+		Method:     req.Method,
+		URL:        req.URL.RequestURI(),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Header:     resp.Header,
+	}
+	if utf8.Valid(body) {
+		text := string(body)
+		entry.Body = &text
+	} else {
+		entry.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+	}
+
+	if err := writeCassette(file, entry); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func writeCassette(file string, entry cassetteEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode cassette entry: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return fmt.Errorf("couldn't create cassette directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".cassette-*")
+	if err != nil {
+		return fmt.Errorf("couldn't create cassette file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("couldn't write cassette %s: %v", file, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("couldn't write cassette %s: %v", file, err)
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// cassetteFileName names the recording of req after its method, path and query.
+// The host is left out so a cassette keeps working when the base URL changes.
+func cassetteFileName(req *http.Request) string {
+	key := req.Method + " " + req.URL.RequestURI()
+	sum := sha256.Sum256([]byte(key))
+
+	readable := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(req.URL.Path, "/"))
+	if len(readable) > 100 {
+		readable = readable[len(readable)-100:]
+	}
+	return readable + "-" + hex.EncodeToString(sum[:8]) + ".json"
+}
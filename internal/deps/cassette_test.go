@@ -0,0 +1,75 @@
+package deps_test
+
+import (
+	"codenotary/internal/deps"
+	"codenotary/internal/sqlite"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder, _ := newTestClient(t, deps.WithCassette(dir, deps.CassetteRecord))
+	recorded, err := recorder.GetDependencies("GO", cobra)
+	if err != nil {
+		t.Fatalf("recording GetDependencies: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "replay.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer db.Close()
+	if err := sqlite.Create(db); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+
+	// Nothing listens on this address: every answer has to come from the cassette.
+	replayer := deps.NewClient(sqlite.NewStore(db), deps.WithBaseURL("http://127.0.0.1:1/v3"), deps.WithCassette(dir, deps.CassetteReplay))
+	replayed, err := replayer.GetDependencies("GO", cobra)
+	if err != nil {
+		t.Fatalf("replaying GetDependencies: %v", err)
+	}
+	if !reflect.DeepEqual(recorded.Nodes, replayed.Nodes) || !reflect.DeepEqual(recorded.Edges, replayed.Edges) {
+		t.Errorf("replayed graph differs from the recorded one:\n%+v\n%+v", replayed, recorded)
+	}
+
+	_, err = replayer.GetProject("github.com/spf13/pflag")
+	if !errors.Is(err, deps.ErrCassetteMiss) {
+		t.Errorf("GetProject of an unrecorded project returned %v, want ErrCassetteMiss", err)
+	}
+}
+
+func TestCassetteBeforeHTTPClient(t *testing.T) {
+	dir := t.TempDir()
+	transport := &countingTransport{}
+	client, _ := newTestClient(t, deps.WithCassette(dir, deps.CassetteRecord), deps.WithHTTPClient(&http.Client{Transport: transport}))
+	if _, err := client.GetProject("github.com/spf13/pflag"); err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("the HTTP client given after the cassette sent %d requests, want 1", transport.requests)
+	}
+	recorded, err := os.ReadDir(dir)
+	if err != nil || len(recorded) != 1 {
+		t.Errorf("cassette holds %d exchanges (%v), want 1: WithHTTPClient dropped it", len(recorded), err)
+	}
+}
+
+func TestParseCassetteMode(t *testing.T) {
+	for input, want := range map[string]deps.CassetteMode{"": deps.CassetteOff, "RECORD": deps.CassetteRecord, "replay": deps.CassetteReplay} {
+		got, err := deps.ParseCassetteMode(input)
+		if err != nil || got != want {
+			t.Errorf("ParseCassetteMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := deps.ParseCassetteMode("rewind"); err == nil {
+		t.Error("ParseCassetteMode(\"rewind\") succeeded")
+	}
+}
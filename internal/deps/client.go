@@ -1,15 +1,75 @@
 package deps
 
-import "database/sql"
+import (
+	"codenotary/internal/store"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultSystem = "GO"
+
+const DefaultBaseURL = "https://api.deps.dev/v3"
+
+var Systems = []string{"GO", "NPM", "PYPI", "MAVEN", "CARGO", "NUGET", "RUBYGEMS"}
 
 type Client struct {
-	baseURL string
-	db      *sql.DB
+	baseURL    string
+	store      store.Store
+	httpClient *http.Client
+	ttls       TTLs
+	now        func() time.Time
+
+	cassetteDir  string
+	cassetteMode CassetteMode
+}
+
+type Option func(*Client)
+
+// WithBaseURL points the client at another deps.dev compatible API, e.g. a local stand-in.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport keeps the client's other http.Client settings and only swaps the RoundTripper.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+}
+
+func NewClient(st store.Store, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    DefaultBaseURL,
+		store:      st,
+		httpClient: &http.Client{},
+		ttls:       DefaultTTLs,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.useCassette()
+	return c
 }
 
-func NewClient(db *sql.DB) *Client {
-	return &Client{
-		baseURL: "https://api.deps.dev/v3",
-		db:      db,
+// NormalizeSystem upper-cases s and reports whether it is an ecosystem deps.dev knows.
+func NormalizeSystem(s string) (string, bool) {
+	system := strings.ToUpper(strings.TrimSpace(s))
+	for _, known := range Systems {
+		if system == known {
+			return system, true
+		}
 	}
+	return system, false
 }
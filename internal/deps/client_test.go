@@ -0,0 +1,234 @@
+package deps_test
+
+import (
+	"codenotary/internal/deps"
+	"codenotary/internal/depsdevtest"
+	"codenotary/internal/sqlite"
+	"database/sql"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const cobra = "github.com/spf13/cobra"
+
+func newTestClient(t *testing.T, opts ...deps.Option) (*deps.Client, *depsdevtest.Server) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := sqlite.Create(db); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+
+	fake, err := depsdevtest.NewWithFixtures()
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := fake.Start()
+	t.Cleanup(server.Close)
+
+	opts = append([]deps.Option{deps.WithBaseURL(server.URL + "/v3")}, opts...)
+	return deps.NewClient(sqlite.NewStore(db), opts...), fake
+}
+
+func TestGetDependenciesResolvesDefaultVersion(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	graph, err := client.GetDependencies("GO", cobra)
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	if len(graph.Nodes) != 6 || len(graph.Edges) != 5 {
+		t.Fatalf("got %d nodes and %d edges, want 6 and 5", len(graph.Nodes), len(graph.Edges))
+	}
+	if self := graph.Nodes[0]; self.Relation != "SELF" || self.VersionKey.Version != "v1.8.0" {
+		t.Errorf("SELF node = %+v, want cobra v1.8.0", self)
+	}
+
+	if _, err := client.GetDependencies("GO", cobra); err != nil {
+		t.Fatalf("second GetDependencies: %v", err)
+	}
+	path := depsdevtest.DependenciesPath("GO", cobra, "v1.8.0")
+	if hits := fake.Hits(path); hits != 1 {
+		t.Errorf("%s requested %d times, want 1 (second call should be served from SQLite)", path, hits)
+	}
+	if hits := fake.Hits(depsdevtest.PackagePath("GO", cobra)); hits != 1 {
+		t.Errorf("package requested %d times, want 1", hits)
+	}
+}
+
+func TestGetDependenciesAtVersionKeepsVersionsApart(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	for _, version := range []string{"v1.7.0", "v1.8.0"} {
+		if _, err := client.GetDependenciesAtVersion("GO", cobra, version); err != nil {
+			t.Fatalf("GetDependenciesAtVersion(%s): %v", version, err)
+		}
+	}
+
+	old, err := client.GetDependenciesAtVersion("GO", cobra, "v1.7.0")
+	if err != nil {
+		t.Fatalf("GetDependenciesAtVersion: %v", err)
+	}
+	if got := old.Nodes[1].VersionKey.Version; got != "v2.0.2" {
+		t.Errorf("go-md2man in the v1.7.0 graph is %s, want v2.0.2", got)
+	}
+}
+
+func TestGetDependenciesOtherEcosystem(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	graph, err := client.GetDependencies("NPM", "express")
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	if self := graph.Nodes[0].VersionKey; self.System != "NPM" || self.Version != "4.18.2" {
+		t.Errorf("SELF node = %+v, want NPM express 4.18.2", self)
+	}
+
+	project, err := client.GetProjectForPackage("NPM", "body-parser", "1.20.1")
+	if err != nil {
+		t.Fatalf("GetProjectForPackage: %v", err)
+	}
+	if project.ProjectKey.ID != "github.com/expressjs/body-parser" {
+		t.Errorf("project = %q, want github.com/expressjs/body-parser", project.ProjectKey.ID)
+	}
+}
+
+func TestGetAllProjectsFromGraphSkipsUnknownProjects(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	graph, err := client.GetDependencies("GO", cobra)
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	projects, skipped, _ := client.GetAllProjectsFromGraph(graph)
+
+	var found []string
+	for _, p := range projects {
+		found = append(found, p.ProjectKey.ID)
+	}
+	sort.Strings(found)
+	sort.Strings(skipped)
+
+	wantFound := []string{"github.com/inconshreveable/mousetrap", "github.com/spf13/pflag"}
+	wantSkipped := []string{"github.com/cpuguy83/go-md2man/v2", "github.com/russross/blackfriday/v2", "gopkg.in/yaml.v3"}
+	if !equal(found, wantFound) {
+		t.Errorf("found %v, want %v", found, wantFound)
+	}
+	if !equal(skipped, wantSkipped) {
+		t.Errorf("skipped %v, want %v", skipped, wantSkipped)
+	}
+}
+
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	transport := &countingTransport{}
+	client, _ := newTestClient(t, deps.WithTransport(transport))
+
+	if _, err := client.GetProject(cobra); err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if transport.requests != 1 {
+		t.Errorf("transport saw %d requests, want 1", transport.requests)
+	}
+}
+
+type inFlightTransport struct {
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (c *inFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.mu.Lock()
+	c.inFlight++
+	if c.inFlight > c.max {
+		c.max = c.inFlight
+	}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithMaxConcurrency(t *testing.T) {
+	transport := &inFlightTransport{}
+	client, _ := newTestClient(t, deps.WithTransport(transport), deps.WithMaxConcurrency(2))
+
+	graph, err := client.GetDependencies("GO", cobra)
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	client.GetNodeProjects(graph)
+	if transport.max != 2 {
+		t.Errorf("at most %d requests were in flight, want 2", transport.max)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGetNodeAdvisories(t *testing.T) {
+	client, fake := newTestClient(t)
+
+	graph, err := client.GetDependencies("NPM", "express")
+	if err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	// Versions fetched while resolving projects record their advisory keys.
+	client.GetNodeProjects(graph)
+
+	advisories, errs := client.GetNodeAdvisories(graph)
+	found := make(map[string]string)
+	for i, node := range graph.Nodes {
+		for _, a := range advisories[i] {
+			found[node.VersionKey.Name] = a.AdvisoryKey.ID + " " + a.Severity
+		}
+		if node.VersionKey.Name == "body-parser" && errs[i] != nil {
+			t.Errorf("body-parser: %v", errs[i])
+		}
+	}
+	if got := found["body-parser"]; got != "GHSA-qwcr-r2fm-qrc7 HIGH" {
+		t.Errorf("body-parser advisories = %q, want GHSA-qwcr-r2fm-qrc7 HIGH", got)
+	}
+	path := depsdevtest.VersionPath("NPM", "body-parser", "1.20.1")
+	if hits := fake.Hits(path); hits != 1 {
+		t.Errorf("%s requested %d times, want 1", path, hits)
+	}
+
+	client.GetNodeAdvisories(graph)
+	if hits := fake.Hits(depsdevtest.AdvisoryPath("GHSA-qwcr-r2fm-qrc7")); hits != 1 {
+		t.Errorf("advisory requested %d times, want 1 (then served from the database)", hits)
+	}
+}
@@ -2,35 +2,54 @@ package deps
 
 import (
 	"codenotary/internal/models"
-	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 )
 
 
-func (c *Client) GetDependencies(name string) (*models.DependencyGraph, error) {
-	graph, err := sqlite.GetDependencyGraph(c.db, name)
+func (c *Client) GetDependencies(system, name string) (*models.DependencyGraph, error) {
+	latestVersion, err := c.GetLatestVersionByProjectId(system, name)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get latest version of project ID %q: %v", name, err)
+	}
+	return c.GetDependenciesAtVersion(system, name, latestVersion)
+}
+
+func (c *Client) GetDependenciesAtVersion(system, name, version string) (*models.DependencyGraph, error) {
+	graph, err := c.store.GetDependencyGraph(system, name, version)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving dependency graph from DB: %v", err)
 	}
 
 	if graph != nil {
-		log.Printf("Dependency graph for project %q found in the database.", name)
+		slog.Debug("dependency graph found in the database", "system", system, "name", name, "version", version)
+		graph.Stale = c.expired(graph.FetchedAt, c.ttls.Graph)
 		return graph, nil
 	}
 
-	safeName := url.PathEscape(name)
+	graph, err = c.fetchDependencies(system, name, version)
+	if err != nil {
+		return nil, err
+	}
 
-	latestVersion, err := c.GetLatestVersionByProjectId(name)
+	err = c.store.InsertDependencyGraph(system, name, version, store.SourceDepsDev, graph)
 	if err != nil {
-		return nil, fmt.Errorf("Couldn't get latest version of project ID %q: %v", name, err)
+		slog.Error("storing dependency graph", "system", system, "name", name, "version", version, "err", err)
 	}
-	url := fmt.Sprintf("%s/systems/GO/packages/%s/versions/%s:dependencies", c.baseURL, safeName, latestVersion)
-	resp, err := http.Get(url)
+	return graph, nil
+}
+
+func (c *Client) fetchDependencies(system, name, version string) (*models.DependencyGraph, error) {
+	safeName := url.PathEscape(name)
+
+	url := fmt.Sprintf("%s/systems/%s/packages/%s/versions/%s:dependencies", c.baseURL, system, safeName, url.PathEscape(version))
+	slog.Debug("fetching from deps.dev", "url", url)
+	resp, err := c.httpClient.Get(url)
 	
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't make the get request to %q: %w", url, err)
@@ -41,14 +60,14 @@ func (c *Client) GetDependencies(name string) (*models.DependencyGraph, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't read all of the body")
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deps.dev returned %s for %q", resp.Status, url)
+	}
 	var dependencyGraph models.DependencyGraph
 	if err := json.Unmarshal(body, &dependencyGraph); err != nil {
 		return nil, fmt.Errorf("error unmarshaling JSON response: %v", err)
 	}
+	dependencyGraph.FetchedAt = c.now()
 
-	err = sqlite.InsertDependencyGraph(c.db, name, &dependencyGraph)
-	if err != nil {
-		
-	}
 	return &dependencyGraph, nil
 }
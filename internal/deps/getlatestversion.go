@@ -1,72 +1,48 @@
 package deps
 
 import (
-	"codenotary/internal/models"
+	"codenotary/internal/version"
 	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
 )
 
 
-func (c *Client) GetLatestVersionByProjectId(projectID string) (string, error) {
+func (c *Client) GetLatestVersionByProjectId(system, projectID string) (string, error) {
+	return c.GetLatestVersionWithOptions(system, projectID, version.Options{})
+}
+
+func (c *Client) GetLatestVersionWithOptions(system, projectID string, opts version.Options) (string, error) {
 	
-	pv, err := c.GetPackage(projectID)
+	pv, err := c.GetPackage(system, projectID)
 	if err != nil {
 		return "", fmt.Errorf("Failed to GetPackage: %w", err)
 	}
 
+	if system == "GO" && opts.ModulePath == "" {
+		opts.ModulePath = projectID
+	}
+
 	defaults := make([]string, 0)
+	all := make([]string, 0, len(pv.Versions))
 	for _, v := range pv.Versions {
 		if v.IsDefault {
 			defaults = append(defaults, v.VersionKey.Version)
 		}
+		all = append(all, v.VersionKey.Version)
 	}
-	switch len(defaults) {
-	case 1:
+	if len(defaults) == 1 && eligible(defaults[0], opts) {
 		return defaults[0], nil
-	default:
-		return latestSemVer(pv.Versions)
-	}
-}
-
-func latestSemVer(versions []models.Version) (string, error) {
-	var max string
-	var max1 int
-	var max2 int
-	var max3 int
-
-	for _, v := range versions {
-		stripped := filterNumbersAndDots(v.VersionKey.Name)
-		parts := strings.Split(stripped, ".")
-		if len(parts) != 3 {
-			continue 
-		}
-
-		major, err1 := strconv.Atoi(parts[0])
-		minor, err2 := strconv.Atoi(parts[1])
-		patch, err3 := strconv.Atoi(parts[2])
-		if err1 != nil || err2 != nil || err3 != nil {
-			continue 
-		}
-
-		
-		if major > max1 || (major == max1 && minor > max2) || (major == max1 && minor == max2 && patch > max3) {
-			max = v.VersionKey.Name
-			max1, max2, max3 = major, minor, patch
-		}
-
 	}
 
-	if max == "" {
+	latest, ok := version.Latest(all, opts)
+	if !ok {
 		return "", fmt.Errorf("no valid semantic versions found")
 	}
-	return max, nil
+	return latest, nil
 }
 
-func filterNumbersAndDots(input string) string {
-	
-	re := regexp.MustCompile(`[^\d.]`)
-	
-	return re.ReplaceAllString(input, "")
+// eligible reports whether v survives the filters of opts, so a default version
+// deps.dev marks is not returned when the caller asked to exclude it.
+func eligible(v string, opts version.Options) bool {
+	_, ok := version.Latest([]string{v}, opts)
+	return ok
 }
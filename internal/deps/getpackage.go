@@ -2,25 +2,28 @@ package deps
 
 import (
 	"codenotary/internal/models"
-	"codenotary/internal/sqlite"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 )
 
-func (c *Client) GetPackage(name string) (*models.PackageVersions, error) {
-	if pkg, err := sqlite.GetPackageVersions(c.db, name); pkg != nil && err == nil {
+func (c *Client) GetPackage(system, name string) (*models.PackageVersions, error) {
+	if pkg, err := c.store.GetPackageVersions(system, name); pkg != nil && err == nil {
+		pkg.Stale = c.expired(pkg.FetchedAt, c.ttls.Package)
 		return pkg, nil
 	}
+	return c.fetchPackage(system, name)
+}
 
+func (c *Client) fetchPackage(system, name string) (*models.PackageVersions, error) {
 	safeName := url.PathEscape(name)
 
-	url := c.baseURL + "/systems/GO/packages/" + safeName
-	fmt.Println(url)
-	resp, err := http.Get(url)
+	url := c.baseURL + "/systems/" + system + "/packages/" + safeName
+	slog.Debug("fetching from deps.dev", "url", url)
+	resp, err := c.httpClient.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't make the get request to %q: %w", url, err)
 	}
@@ -30,25 +33,19 @@ func (c *Client) GetPackage(name string) (*models.PackageVersions, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't read all of the body")
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deps.dev returned %s for %q", resp.Status, url)
+	}
 
 	var pkg models.PackageVersions
 	if err := json.Unmarshal(body, &pkg); err != nil {
 		return nil, fmt.Errorf("couldn't parse JSON: %v", err)
 	}
+	pkg.FetchedAt = c.now()
 
-	return &pkg, nil
-}
-
-func GetLatestVersion(db *sql.DB, system, name string) (string, error) {
-	var version string
-	err := db.QueryRow(`
-		SELECT version FROM package_versions
-		WHERE system = ? AND name = ? AND is_default = 1
-		LIMIT 1`, system, name).Scan(&version)
-	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("no default version found for %s/%s", system, name)
-	} else if err != nil {
-		return "", fmt.Errorf("failed to get latest version: %v", err)
+	if err := c.store.StorePackageVersions(&pkg); err != nil {
+		slog.Error("storing package versions", "system", system, "name", name, "err", err)
 	}
-	return version, nil
+
+	return &pkg, nil
 }
@@ -2,25 +2,29 @@ package deps
 
 import (
 	"codenotary/internal/models"
-	"codenotary/internal/sqlite"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"sync"
 )
 
 func (c *Client) GetProject(projectKey string) (*models.Project, error) {
-	if project, err := sqlite.GetProject(c.db, projectKey); project != nil && err == nil {
+	if project, err := c.store.GetProject(projectKey); project != nil && err == nil {
+		project.Stale = c.expired(project.FetchedAt, c.ttls.Project)
 		return project, nil
 	}
+	return c.fetchProject(projectKey)
+}
 
+func (c *Client) fetchProject(projectKey string) (*models.Project, error) {
 	safeName := url.PathEscape(projectKey)
 
 	url := c.baseURL + "/projects/" + safeName
 
-	resp, err := http.Get(url)
+	resp, err := c.httpClient.Get(url)
 	
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't make the get request to %q: %w", url, err)
@@ -31,17 +35,43 @@ func (c *Client) GetProject(projectKey string) (*models.Project, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Couldn't read all of the body")
 	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deps.dev returned %s for %q", resp.Status, url)
+	}
 
 	var project models.Project
 	if err := json.Unmarshal(body, &project); err != nil {
 		return nil, fmt.Errorf("error unmarshaling JSON response: %v", err)
 	}
-
-	
+	project.FetchedAt = c.now()
 
 	return &project, nil
 }
 
+// GetProjectForPackage resolves the source repository project of a package version.
+// Go module paths double as project IDs, every other ecosystem goes through the
+// SOURCE_REPO related project reported by deps.dev.
+func (c *Client) GetProjectForPackage(system, name, version string) (*models.Project, error) {
+	if system == "GO" || system == "" {
+		return c.GetProject(name)
+	}
+
+	details, err := c.GetVersion(system, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get version %s of %s/%s: %w", version, system, name, err)
+	}
+	for _, related := range details.RelatedProjects {
+		if related.RelationType == "SOURCE_REPO" {
+			key := models.VersionKey{System: system, Name: name, Version: version}
+			if err := c.store.SetVersionProject(key, related.ProjectKey.ID); err != nil {
+				slog.Error("storing the project of a version", "system", system, "name", name, "version", version, "err", err)
+			}
+			return c.GetProject(related.ProjectKey.ID)
+		}
+	}
+	return nil, fmt.Errorf("no source repository known for %s/%s", system, name)
+}
+
 func (c *Client) GetAllProjectsFromGraph(graph *models.DependencyGraph) (succesfulProjects []*models.Project, skipped []string, erro error) {
 	var wg sync.WaitGroup
 
@@ -66,7 +96,7 @@ func (c *Client) GetAllProjectsFromGraph(graph *models.DependencyGraph) (succesf
 			}
 
 			
-			project, err := c.GetProject(node.VersionKey.Name)
+			project, err := c.GetProjectForPackage(node.VersionKey.System, node.VersionKey.Name, node.VersionKey.Version)
 			if err != nil {
 				
 				
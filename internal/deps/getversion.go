@@ -0,0 +1,34 @@
+package deps
+
+import (
+	"codenotary/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+func (c *Client) GetVersion(system, name, version string) (*models.VersionDetails, error) {
+	url := fmt.Sprintf("%s/systems/%s/packages/%s/versions/%s", c.baseURL, system, url.PathEscape(name), url.PathEscape(version))
+	resp, err := c.httpClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't make the get request to %q: %w", url, err)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	defer resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't read all of the body")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("deps.dev returned %s for %q", resp.Status, url)
+	}
+
+	var details models.VersionDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		return nil, fmt.Errorf("error unmarshaling JSON response: %v", err)
+	}
+	c.storeAdvisoryKeys(&details)
+	return &details, nil
+}
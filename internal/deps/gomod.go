@@ -0,0 +1,119 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"codenotary/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// ErrInvalidGoMod is returned, wrapped, for a go.mod that can't be parsed.
+var ErrInvalidGoMod = errors.New("invalid go.mod")
+
+// ParseGoMod builds a dependency graph out of a go.mod file. The main module is the
+// SELF node and every require directive becomes a node with an edge from it; replace
+// and exclude directives are applied, and when gosum is given every module missing
+// a checksum gets an error attached.
+func ParseGoMod(gomod, gosum []byte, version string) (*models.DependencyGraph, string, error) {
+	file, err := modfile.Parse("go.mod", gomod, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidGoMod, err)
+	}
+	if file.Module == nil {
+		return nil, "", fmt.Errorf("%w: no module directive", ErrInvalidGoMod)
+	}
+	modulePath := file.Module.Mod.Path
+
+	excluded := make(map[string]bool)
+	for _, exclude := range file.Exclude {
+		excluded[exclude.Mod.String()] = true
+	}
+
+	sums := parseGoSum(gosum)
+
+	graph := &models.DependencyGraph{
+		Nodes: []models.Node{{
+			VersionKey: models.VersionKey{System: "GO", Name: modulePath, Version: version},
+			Relation:   "SELF",
+			Errors:     []string{},
+		}},
+	}
+
+	for _, require := range file.Require {
+		requirement := require.Mod.Version
+		mod := require.Mod
+		errs := []string{}
+
+		if excluded[mod.String()] {
+			errs = append(errs, fmt.Sprintf("version %s is excluded by go.mod", mod.Version))
+		}
+
+		local := false
+		if replacement := findReplace(file.Replace, mod.Path, mod.Version); replacement != nil {
+			if replacement.New.Version == "" {
+				errs = append(errs, fmt.Sprintf("replaced by local directory %s", replacement.New.Path))
+				local = true
+			} else {
+				mod = replacement.New
+			}
+		}
+
+		if gosum != nil && !local && !sums[mod.Path+" "+mod.Version] {
+			errs = append(errs, "missing go.sum entry")
+		}
+
+		relation := "DIRECT"
+		if require.Indirect {
+			relation = "INDIRECT"
+		}
+		graph.Nodes = append(graph.Nodes, models.Node{
+			VersionKey: models.VersionKey{System: "GO", Name: mod.Path, Version: mod.Version},
+			Relation:   relation,
+			Errors:     errs,
+		})
+		graph.Edges = append(graph.Edges, models.Edge{
+			FromNode:    0,
+			ToNode:      len(graph.Nodes) - 1,
+			Requirement: requirement,
+		})
+	}
+
+	return graph, modulePath, nil
+}
+
+// findReplace returns the replace directive that applies to path@version. A directive
+// pinned to the exact version wins over one that covers every version of the module.
+func findReplace(replaces []*modfile.Replace, path, version string) *modfile.Replace {
+	var wildcard *modfile.Replace
+	for _, r := range replaces {
+		if r.Old.Path != path {
+			continue
+		}
+		if r.Old.Version == version {
+			return r
+		}
+		if r.Old.Version == "" {
+			wildcard = r
+		}
+	}
+	return wildcard
+}
+
+// parseGoSum returns the "path version" pairs that have a checksum in go.sum, either
+// for the module zip or only for its go.mod.
+func parseGoSum(gosum []byte) map[string]bool {
+	sums := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(gosum))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		sums[fields[0]+" "+strings.TrimSuffix(fields[1], "/go.mod")] = true
+	}
+	return sums
+}
@@ -0,0 +1,162 @@
+package deps_test
+
+import (
+	"codenotary/internal/deps"
+	"codenotary/internal/models"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const testGoMod = `module example.com/app
+
+go 1.22
+
+require github.com/spf13/pflag v1.0.5
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/old/name v1.0.0
+	github.com/local/fork v0.3.0
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace github.com/old/name => github.com/new/name v1.2.0
+
+replace github.com/local/fork v0.3.0 => ../fork
+
+exclude golang.org/x/text v0.14.0
+`
+
+const testGoSum = `github.com/spf13/pflag v1.0.5 h1:iy+VFUOCP1a+8yFto/drg2CJ5u0yRoB7fZw3DKv/JXA=
+github.com/spf13/pflag v1.0.5/go.mod h1:McXfInJRrz4CZXVZOBLb0bTZqETkiAhM9Iw0y3An2Bg=
+github.com/inconshreveable/mousetrap v1.1.0/go.mod h1:vpF70FUmC8bwa3OWnCshd2FqLfsEA9PFc4w1p2J65bw=
+github.com/new/name v1.2.0 h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=
+golang.org/x/text v0.14.0 h1:BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB=
+`
+
+func TestParseGoMod(t *testing.T) {
+	graph, module, err := deps.ParseGoMod([]byte(testGoMod), []byte(testGoSum), "v0.1.0")
+	if err != nil {
+		t.Fatalf("ParseGoMod: %v", err)
+	}
+	if module != "example.com/app" {
+		t.Errorf("module = %q, want example.com/app", module)
+	}
+
+	want := []struct {
+		name, version, relation string
+		errors                  []string
+	}{
+		{"example.com/app", "v0.1.0", "SELF", nil},
+		{"github.com/spf13/pflag", "v1.0.5", "DIRECT", nil},
+		{"github.com/inconshreveable/mousetrap", "v1.1.0", "INDIRECT", nil},
+		{"github.com/new/name", "v1.2.0", "DIRECT", nil},
+		{"github.com/local/fork", "v0.3.0", "DIRECT", []string{"replaced by local directory ../fork"}},
+		{"golang.org/x/text", "v0.14.0", "INDIRECT", []string{"version v0.14.0 is excluded by go.mod"}},
+		{"gopkg.in/yaml.v3", "v3.0.1", "DIRECT", []string{"missing go.sum entry"}},
+	}
+	if len(graph.Nodes) != len(want) {
+		t.Fatalf("got %d nodes, want %d: %+v", len(graph.Nodes), len(want), graph.Nodes)
+	}
+	for i, w := range want {
+		node := graph.Nodes[i]
+		if node.VersionKey != (models.VersionKey{System: "GO", Name: w.name, Version: w.version}) || node.Relation != w.relation {
+			t.Errorf("node %d = %+v %s, want %s@%s %s", i, node.VersionKey, node.Relation, w.name, w.version, w.relation)
+		}
+		if strings.Join(node.Errors, "; ") != strings.Join(w.errors, "; ") {
+			t.Errorf("node %s errors = %q, want %q", w.name, node.Errors, w.errors)
+		}
+	}
+
+	if len(graph.Edges) != len(want)-1 {
+		t.Fatalf("got %d edges, want %d", len(graph.Edges), len(want)-1)
+	}
+	for i, edge := range graph.Edges {
+		if edge.FromNode != 0 || edge.ToNode != i+1 {
+			t.Errorf("edge %d = %d -> %d, want 0 -> %d", i, edge.FromNode, edge.ToNode, i+1)
+		}
+	}
+	// The requirement is the version go.mod asks for, before the replacement.
+	if got := graph.Edges[2].Requirement; got != "v1.0.0" {
+		t.Errorf("requirement of the replaced module = %q, want v1.0.0", got)
+	}
+}
+
+func TestParseGoModWithoutGoSum(t *testing.T) {
+	graph, _, err := deps.ParseGoMod([]byte(testGoMod), nil, "local")
+	if err != nil {
+		t.Fatalf("ParseGoMod: %v", err)
+	}
+	for _, node := range graph.Nodes {
+		for _, e := range node.Errors {
+			if e == "missing go.sum entry" {
+				t.Errorf("%s is missing a go.sum entry without a go.sum", node.VersionKey.Name)
+			}
+		}
+	}
+}
+
+func TestParseGoModReplaceSpecificVersion(t *testing.T) {
+	gomod := `module example.com/app
+
+require github.com/old/name v1.1.0
+
+replace github.com/old/name v1.0.0 => github.com/new/name v1.2.0
+`
+	graph, _, err := deps.ParseGoMod([]byte(gomod), nil, "local")
+	if err != nil {
+		t.Fatalf("ParseGoMod: %v", err)
+	}
+	if got := graph.Nodes[1].VersionKey.Name; got != "github.com/old/name" {
+		t.Errorf("a replace of another version applied: node is %s", got)
+	}
+}
+
+func TestParseGoModInvalid(t *testing.T) {
+	invalid := map[string]string{
+		"syntax":    "module example.com/app\n\nrequire (\n\tgithub.com/spf13/pflag\n",
+		"no module": "go 1.22\n\nrequire github.com/spf13/pflag v1.0.5\n",
+		"directive": "module example.com/app\n\nrequires github.com/spf13/pflag v1.0.5\n",
+	}
+	for name, gomod := range invalid {
+		if _, _, err := deps.ParseGoMod([]byte(gomod), nil, "local"); err == nil {
+			t.Errorf("%s: ParseGoMod succeeded, want an error", name)
+		}
+	}
+}
+
+func TestScanGoMod(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	result, err := client.ScanGoMod([]byte(testGoMod), []byte(testGoSum), "")
+	if err != nil {
+		t.Fatalf("ScanGoMod: %v", err)
+	}
+	if result.Module != "example.com/app" || result.Version != "local" {
+		t.Errorf("scanned %s@%s, want example.com/app@local", result.Module, result.Version)
+	}
+	var found []string
+	for _, p := range result.Projects {
+		found = append(found, p.ProjectKey.ID)
+	}
+	if len(found) != 2 {
+		t.Errorf("projects = %v, want pflag and mousetrap", found)
+	}
+	if len(result.Skipped) != len(result.Graph.Nodes)-3 {
+		t.Errorf("skipped = %v, want every module without a fixture", result.Skipped)
+	}
+
+	stored, err := client.GetDependenciesAtVersion("GO", "example.com/app", "local")
+	if err != nil {
+		t.Fatalf("GetDependenciesAtVersion: %v", err)
+	}
+	if len(stored.Nodes) != len(result.Graph.Nodes) {
+		t.Errorf("stored %d nodes, want %d", len(stored.Nodes), len(result.Graph.Nodes))
+	}
+
+	if _, err := client.ScanGoMod([]byte("not a go.mod"), nil, ""); !errors.Is(err, deps.ErrInvalidGoMod) {
+		t.Errorf("ScanGoMod of a malformed go.mod = %v, want ErrInvalidGoMod", err)
+	}
+}
@@ -0,0 +1,66 @@
+package deps
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WithTimeout bounds every deps.dev request, body included; 0 means no limit.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Timeout = timeout
+		c.httpClient = &httpClient
+	}
+}
+
+// WithMaxConcurrency caps the deps.dev requests in flight. Graphs fetch the
+// project of every node at once, which otherwise means one connection per node.
+// n <= 0 means no limit.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Client) {
+		if n <= 0 {
+			return
+		}
+		next := c.httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		WithTransport(&limitedTransport{slots: make(chan struct{}, n), next: next})(c)
+	}
+}
+
+type limitedTransport struct {
+	slots chan struct{}
+	next  http.RoundTripper
+}
+
+// RoundTrip holds a slot until the response body is closed.
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	select {
+	case t.slots <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		<-t.slots
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: func() { <-t.slots }}
+	return resp, nil
+}
+
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
@@ -0,0 +1,27 @@
+package deps
+
+import (
+	"codenotary/internal/models"
+	"sync"
+)
+
+// GetNodeProjects resolves the project of every node, SELF included. The result
+// is aligned with graph.Nodes; nodes whose project can't be resolved are nil.
+func (c *Client) GetNodeProjects(graph *models.DependencyGraph) []*models.Project {
+	projects := make([]*models.Project, len(graph.Nodes))
+
+	var wg sync.WaitGroup
+	for i, node := range graph.Nodes {
+		wg.Add(1)
+		go func(i int, node models.Node) {
+			defer wg.Done()
+			project, err := c.GetProjectForPackage(node.VersionKey.System, node.VersionKey.Name, node.VersionKey.Version)
+			if err == nil {
+				projects[i] = project
+			}
+		}(i, node)
+	}
+	wg.Wait()
+
+	return projects
+}
@@ -0,0 +1,129 @@
+package deps
+
+import (
+	"codenotary/internal/store"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+type RefreshStats struct {
+	Projects int
+	Packages int
+	Graphs   int
+	Failed   int
+}
+
+func (c *Client) RefreshProject(projectKey string) error {
+	project, err := c.fetchProject(projectKey)
+	if err != nil {
+		return err
+	}
+	return c.store.InsertProject(project)
+}
+
+// RefreshPackage re-fetches the version list of a package; fetchPackage stores it.
+func (c *Client) RefreshPackage(system, name string) error {
+	_, err := c.fetchPackage(system, name)
+	return err
+}
+
+func (c *Client) RefreshGraph(system, name, version string) error {
+	graph, err := c.fetchDependencies(system, name, version)
+	if err != nil {
+		return err
+	}
+	return c.store.ReplaceDependencyGraph(system, name, version, store.SourceDepsDev, graph)
+}
+
+// RefreshStale re-fetches up to limit expired entries of every kind. Entries that
+// fail stay expired; the attempt is recorded so they are retried after the
+// entries not tried since, instead of filling every batch.
+func (c *Client) RefreshStale(limit int) (RefreshStats, error) {
+	var stats RefreshStats
+	now := c.now()
+
+	if c.ttls.Project > 0 {
+		ids, err := c.store.ListStaleProjects(now.Add(-c.ttls.Project), limit)
+		if err != nil {
+			return stats, err
+		}
+		for _, id := range ids {
+			if err := c.store.MarkProjectAttempt(id, now); err != nil {
+				slog.Error("recording refresh attempt", "project", id, "err", err)
+			}
+			if err := c.RefreshProject(id); err != nil {
+				slog.Error("refreshing project", "project", id, "err", err)
+				stats.Failed++
+				continue
+			}
+			stats.Projects++
+		}
+	}
+
+	if c.ttls.Package > 0 {
+		keys, err := c.store.ListStalePackages(now.Add(-c.ttls.Package), limit)
+		if err != nil {
+			return stats, err
+		}
+		for _, key := range keys {
+			if err := c.store.MarkPackageAttempt(key, now); err != nil {
+				slog.Error("recording refresh attempt", "system", key.System, "name", key.Name, "err", err)
+			}
+			if err := c.RefreshPackage(key.System, key.Name); err != nil {
+				slog.Error("refreshing package", "system", key.System, "name", key.Name, "err", err)
+				stats.Failed++
+				continue
+			}
+			stats.Packages++
+		}
+	}
+
+	if c.ttls.Graph > 0 {
+		graphs, err := c.store.ListStaleGraphs(now.Add(-c.ttls.Graph), limit)
+		if err != nil {
+			return stats, err
+		}
+		for _, g := range graphs {
+			if err := c.store.MarkGraphAttempt(g, now); err != nil {
+				slog.Error("recording refresh attempt", "graph", store.GraphID(g.System, g.ProjectID, g.Version), "err", err)
+			}
+			if err := c.RefreshGraph(g.System, g.ProjectID, g.Version); err != nil {
+				slog.Error("refreshing graph", "graph", store.GraphID(g.System, g.ProjectID, g.Version), "err", err)
+				stats.Failed++
+				continue
+			}
+			stats.Graphs++
+		}
+	}
+
+	return stats, nil
+}
+
+// StartRefresher calls RefreshStale every interval until ctx is done.
+func (c *Client) StartRefresher(ctx context.Context, interval time.Duration, batch int) error {
+	if interval <= 0 {
+		return fmt.Errorf("refresh interval must be positive, got %s", interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				stats, err := c.RefreshStale(batch)
+				if err != nil {
+					slog.Error("refreshing stale data", "err", err)
+					continue
+				}
+				if stats != (RefreshStats{}) {
+					slog.Info("refreshed stale data", "projects", stats.Projects, "packages", stats.Packages, "graphs", stats.Graphs, "failed", stats.Failed)
+				}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,115 @@
+package deps_test
+
+import (
+	"codenotary/internal/deps"
+	"codenotary/internal/depsdevtest"
+	"testing"
+	"time"
+)
+
+// testClock is a clock tests move by hand.
+type testClock struct{ now time.Time }
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestExpiredDataIsServedStaleAndRefreshed(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	client, fake := newTestClient(t,
+		deps.WithTTLs(deps.TTLs{Project: time.Hour, Package: time.Hour, Graph: time.Hour}),
+		deps.WithClock(clock.Now))
+
+	if _, err := client.GetDependencies("GO", cobra); err != nil {
+		t.Fatalf("GetDependencies: %v", err)
+	}
+	if err := client.RefreshProject(cobra); err != nil {
+		t.Fatalf("RefreshProject: %v", err)
+	}
+	clock.Advance(2 * time.Hour)
+
+	project, err := client.GetProject(cobra)
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if !project.Stale {
+		t.Error("expired project was not flagged stale")
+	}
+	graph, err := client.GetDependenciesAtVersion("GO", cobra, "v1.8.0")
+	if err != nil {
+		t.Fatalf("GetDependenciesAtVersion: %v", err)
+	}
+	if !graph.Stale {
+		t.Error("expired graph was not flagged stale")
+	}
+
+	stats, err := client.RefreshStale(10)
+	if err != nil {
+		t.Fatalf("RefreshStale: %v", err)
+	}
+	want := deps.RefreshStats{Projects: 1, Packages: 1, Graphs: 1}
+	if stats != want {
+		t.Errorf("RefreshStale = %+v, want %+v", stats, want)
+	}
+	if hits := fake.Hits(depsdevtest.DependenciesPath("GO", cobra, "v1.8.0")); hits != 2 {
+		t.Errorf("graph fetched %d times, want 2", hits)
+	}
+}
+
+func TestFailingRefreshesDontBlockTheQueue(t *testing.T) {
+	clock := &testClock{now: time.Now()}
+	client, fake := newTestClient(t, deps.WithTTLs(deps.TTLs{Project: time.Hour}), deps.WithClock(clock.Now))
+
+	const pflag = "github.com/spf13/pflag"
+	for _, id := range []string{cobra, pflag} {
+		if err := client.RefreshProject(id); err != nil {
+			t.Fatalf("RefreshProject(%s): %v", id, err)
+		}
+		clock.Advance(time.Minute)
+	}
+	// cobra expired first but can't be fetched any more.
+	fake.Set(depsdevtest.ProjectPath(cobra), []byte("not a project"))
+	clock.Advance(2 * time.Hour)
+
+	stats, err := client.RefreshStale(1)
+	if err != nil {
+		t.Fatalf("RefreshStale: %v", err)
+	}
+	if stats != (deps.RefreshStats{Failed: 1}) {
+		t.Fatalf("first RefreshStale = %+v, want cobra to fail", stats)
+	}
+	clock.Advance(time.Minute)
+	stats, err = client.RefreshStale(1)
+	if err != nil {
+		t.Fatalf("RefreshStale: %v", err)
+	}
+	if stats != (deps.RefreshStats{Projects: 1}) {
+		t.Errorf("second RefreshStale = %+v, want pflag refreshed behind the failing cobra", stats)
+	}
+	if hits := fake.Hits(depsdevtest.ProjectPath(cobra)); hits != 2 {
+		t.Errorf("cobra requested %d times, want 2", hits)
+	}
+}
+
+func TestFreshDataIsNotStale(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	if err := client.RefreshProject(cobra); err != nil {
+		t.Fatalf("RefreshProject: %v", err)
+	}
+	project, err := client.GetProject(cobra)
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if project.Stale || project.FetchedAt.IsZero() {
+		t.Errorf("freshly fetched project: stale=%v fetchedAt=%v", project.Stale, project.FetchedAt)
+	}
+
+	stats, err := client.RefreshStale(10)
+	if err != nil {
+		t.Fatalf("RefreshStale: %v", err)
+	}
+	if stats != (deps.RefreshStats{}) {
+		t.Errorf("RefreshStale refreshed fresh data: %+v", stats)
+	}
+}
@@ -0,0 +1,60 @@
+package deps
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"fmt"
+	"log/slog"
+)
+
+type ScanResult struct {
+	Module   string
+	Version  string
+	Graph    *models.DependencyGraph
+	Projects []*models.Project
+	// NodeProjects is aligned with Graph.Nodes; nodes without a known project are nil.
+	NodeProjects []*models.Project
+	Skipped      []string
+}
+
+// ScanGoMod parses a go.mod (and optionally its go.sum), fetches the scorecard of
+// every required module and then stores the graph under GO/{module}@{version},
+// replacing an earlier scan of the same module and version.
+func (c *Client) ScanGoMod(gomod, gosum []byte, version string) (*ScanResult, error) {
+	if version == "" {
+		version = "local"
+	}
+	graph, module, err := ParseGoMod(gomod, gosum, version)
+	if err != nil {
+		return nil, err
+	}
+	return c.ImportGraph("GO", module, version, store.SourceGoMod, graph)
+}
+
+// ImportGraph fetches the scorecard of every node of a graph built outside deps.dev
+// and stores it under {system}/{name}@{version}, replacing an earlier import of the
+// same version. Projects are stored first so the nodes pick up their OpenSSF scores.
+func (c *Client) ImportGraph(system, name, version, source string, graph *models.DependencyGraph) (*ScanResult, error) {
+	result := &ScanResult{
+		Module:       name,
+		Version:      version,
+		Graph:        graph,
+		NodeProjects: c.GetNodeProjects(graph),
+	}
+	for i, project := range result.NodeProjects {
+		switch {
+		case project != nil:
+			result.Projects = append(result.Projects, project)
+		case graph.Nodes[i].Relation != "SELF":
+			result.Skipped = append(result.Skipped, graph.Nodes[i].VersionKey.Name)
+		}
+	}
+	if err := c.store.InsertProjects(result.Projects); err != nil {
+		slog.Error("storing projects", "name", name, "err", err)
+	}
+
+	if err := c.store.ReplaceDependencyGraph(system, name, version, source, graph); err != nil {
+		return nil, fmt.Errorf("couldn't store the graph of %s@%s: %w", name, version, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,35 @@
+package deps
+
+import "time"
+
+// TTLs says how long fetched data is considered fresh. A zero TTL never expires.
+type TTLs struct {
+	Project time.Duration
+	Package time.Duration
+	Graph   time.Duration
+}
+
+// Scorecards are recomputed weekly, package versions and graphs change more often.
+var DefaultTTLs = TTLs{
+	Project: 7 * 24 * time.Hour,
+	Package: 24 * time.Hour,
+	Graph:   24 * time.Hour,
+}
+
+func WithTTLs(ttls TTLs) Option {
+	return func(c *Client) {
+		c.ttls = ttls
+	}
+}
+
+// WithClock replaces time.Now for fetch times and expiry, e.g. to let a test
+// expire data without waiting.
+func WithClock(now func() time.Time) Option {
+	return func(c *Client) {
+		c.now = now
+	}
+}
+
+func (c *Client) expired(fetchedAt time.Time, ttl time.Duration) bool {
+	return ttl > 0 && c.now().Sub(fetchedAt) > ttl
+}
@@ -0,0 +1,215 @@
+// Package depsdevtest is a local stand-in for the deps.dev v3 API. It serves
+// recorded JSON responses so deps.Client can be exercised without network access.
+package depsdevtest
+
+import (
+	"codenotary/internal/models"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"sync"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Fixtures holds the recorded responses shipped with the package, laid out as
+// packages/, versions/, dependencies/, projects/ and advisories/ directories of JSON files.
+func Fixtures() fs.FS {
+	sub, err := fs.Sub(fixtures, "fixtures")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+type Server struct {
+	mu        sync.Mutex
+	responses map[string][]byte
+	hits      map[string]int
+}
+
+func New() *Server {
+	return &Server{
+		responses: make(map[string][]byte),
+		hits:      make(map[string]int),
+	}
+}
+
+// NewWithFixtures returns a server preloaded with Fixtures().
+func NewWithFixtures() (*Server, error) {
+	s := New()
+	if err := s.Load(Fixtures()); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Start serves s on a local port. The base URL for deps.NewClient is the returned
+// server's URL followed by "/v3".
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// Load reads recorded responses from fsys. Each file is a raw deps.dev response;
+// the request it answers is derived from the key inside it.
+func (s *Server) Load(fsys fs.FS) error {
+	kinds := map[string]func([]byte) (string, error){
+		"packages":     packagePath,
+		"versions":     versionPath,
+		"dependencies": dependenciesPath,
+		"projects":     projectPath,
+		"advisories":   advisoryPath,
+	}
+	for dir, keyOf := range kinds {
+		files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			body, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return fmt.Errorf("couldn't read fixture %s: %v", file, err)
+			}
+			key, err := keyOf(body)
+			if err != nil {
+				return fmt.Errorf("couldn't index fixture %s: %v", file, err)
+			}
+			s.Set(key, body)
+		}
+	}
+	return nil
+}
+
+// Set makes the server answer requests for the escaped path p with body.
+func (s *Server) Set(p string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[p] = body
+}
+
+func (s *Server) AddPackage(pkg models.PackageVersions) {
+	body, _ := json.Marshal(pkg)
+	s.Set(PackagePath(pkg.PackageKey.System, pkg.PackageKey.Name), body)
+}
+
+func (s *Server) AddVersion(details models.VersionDetails) {
+	body, _ := json.Marshal(details)
+	key := details.VersionKey
+	s.Set(VersionPath(key.System, key.Name, key.Version), body)
+}
+
+func (s *Server) AddDependencies(key models.VersionKey, graph models.DependencyGraph) {
+	body, _ := json.Marshal(graph)
+	s.Set(DependenciesPath(key.System, key.Name, key.Version), body)
+}
+
+func (s *Server) AddProject(project models.Project) {
+	body, _ := json.Marshal(project)
+	s.Set(ProjectPath(project.ProjectKey.ID), body)
+}
+
+func (s *Server) AddAdvisory(advisory models.Advisory) {
+	body, _ := json.Marshal(advisory)
+	s.Set(AdvisoryPath(advisory.AdvisoryKey.ID), body)
+}
+
+// Hits returns how many times the escaped path p was requested.
+func (s *Server) Hits(p string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[p]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	p := r.URL.EscapedPath()
+	s.mu.Lock()
+	s.hits[p]++
+	body, ok := s.responses[p]
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"code":5,"message":"%s not found"}`, p)
+		return
+	}
+	w.Write(body)
+}
+
+func PackagePath(system, name string) string {
+	return "/v3/systems/" + system + "/packages/" + url.PathEscape(name)
+}
+
+func VersionPath(system, name, version string) string {
+	return PackagePath(system, name) + "/versions/" + url.PathEscape(version)
+}
+
+func DependenciesPath(system, name, version string) string {
+	return VersionPath(system, name, version) + ":dependencies"
+}
+
+func ProjectPath(id string) string {
+	return "/v3/projects/" + url.PathEscape(id)
+}
+
+func AdvisoryPath(id string) string {
+	return "/v3/advisories/" + url.PathEscape(id)
+}
+
+func packagePath(body []byte) (string, error) {
+	var pkg models.PackageVersions
+	if err := json.Unmarshal(body, &pkg); err != nil {
+		return "", err
+	}
+	return PackagePath(pkg.PackageKey.System, pkg.PackageKey.Name), nil
+}
+
+func versionPath(body []byte) (string, error) {
+	var details models.VersionDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		return "", err
+	}
+	key := details.VersionKey
+	return VersionPath(key.System, key.Name, key.Version), nil
+}
+
+func dependenciesPath(body []byte) (string, error) {
+	var graph models.DependencyGraph
+	if err := json.Unmarshal(body, &graph); err != nil {
+		return "", err
+	}
+	for _, node := range graph.Nodes {
+		if node.Relation == "SELF" {
+			key := node.VersionKey
+			return DependenciesPath(key.System, key.Name, key.Version), nil
+		}
+	}
+	return "", fmt.Errorf("graph has no SELF node")
+}
+
+func projectPath(body []byte) (string, error) {
+	var project models.Project
+	if err := json.Unmarshal(body, &project); err != nil {
+		return "", err
+	}
+	return ProjectPath(project.ProjectKey.ID), nil
+}
+
+func advisoryPath(body []byte) (string, error) {
+	var advisory models.Advisory
+	if err := json.Unmarshal(body, &advisory); err != nil {
+		return "", err
+	}
+	return AdvisoryPath(advisory.AdvisoryKey.ID), nil
+}
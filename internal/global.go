@@ -2,10 +2,18 @@ package internal
 
 import (
 	"codenotary/internal/deps"
-	"database/sql"
+	"codenotary/internal/policy"
+	"codenotary/internal/profile"
+	"codenotary/internal/store"
 )
 
 const Database string = "codenotarydatabase.db"
 
-var Db *sql.DB
+var Store store.Store
 var Client *deps.Client
+
+// Policy is evaluated by POST /policy/evaluate/ when the request carries none.
+var Policy *policy.Policy
+
+// Profiles are the scoring profiles ?profile= can name.
+var Profiles *profile.Profiles
@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/version"
+	"sort"
+)
+
+// Kinds of change reported by Diff.
+const (
+	Added      = "added"
+	Removed    = "removed"
+	Upgraded   = "upgraded"
+	Downgraded = "downgraded"
+	// MetadataChanged is a version that only differs in build metadata
+	// (v2.0.0 to v2.0.0+incompatible), neither newer nor older.
+	MetadataChanged = "metadata_changed"
+)
+
+// Change is one dependency that differs between two graphs. FromIndex and
+// ToIndex point into the nodes of the old and new graph, -1 where the
+// dependency is absent.
+type Change struct {
+	Kind      string
+	System    string
+	Name      string
+	FromIndex int
+	ToIndex   int
+}
+
+type Diff struct {
+	Changes []Change
+	// Unchanged counts dependencies present at the same version in both graphs.
+	Unchanged int
+}
+
+// DiffGraphs compares the dependencies of two graphs, ignoring their SELF nodes.
+// A package present at exactly one version on both sides is upgraded or
+// downgraded, or has its metadata changed when the versions only differ in build
+// metadata; when either side holds several versions of it, the versions only
+// on one side are reported as added or removed. Changes are ordered by name.
+func DiffGraphs(from, to *models.DependencyGraph) Diff {
+	type packageKey struct{ system, name string }
+	versions := func(g *models.DependencyGraph) map[packageKey]map[string]int {
+		byPackage := make(map[packageKey]map[string]int)
+		for i, node := range g.Nodes {
+			if node.Relation == "SELF" {
+				continue
+			}
+			key := packageKey{node.VersionKey.System, node.VersionKey.Name}
+			if byPackage[key] == nil {
+				byPackage[key] = make(map[string]int)
+			}
+			if _, ok := byPackage[key][node.VersionKey.Version]; !ok {
+				byPackage[key][node.VersionKey.Version] = i
+			}
+		}
+		return byPackage
+	}
+	before, after := versions(from), versions(to)
+
+	var diff Diff
+	for key, old := range before {
+		current := after[key]
+		if len(old) == 1 && len(current) == 1 {
+			oldVersion, fromIndex := only(old)
+			newVersion, toIndex := only(current)
+			switch c := version.CompareStrings(oldVersion, newVersion); {
+			case oldVersion == newVersion:
+				diff.Unchanged++
+			case samePrecedence(oldVersion, newVersion):
+				diff.Changes = append(diff.Changes, Change{MetadataChanged, key.system, key.name, fromIndex, toIndex})
+			case c < 0:
+				diff.Changes = append(diff.Changes, Change{Upgraded, key.system, key.name, fromIndex, toIndex})
+			default:
+				diff.Changes = append(diff.Changes, Change{Downgraded, key.system, key.name, fromIndex, toIndex})
+			}
+			continue
+		}
+		for v, fromIndex := range old {
+			if _, ok := current[v]; ok {
+				diff.Unchanged++
+			} else {
+				diff.Changes = append(diff.Changes, Change{Removed, key.system, key.name, fromIndex, -1})
+			}
+		}
+	}
+	for key, current := range after {
+		old := before[key]
+		if len(old) == 1 && len(current) == 1 {
+			continue
+		}
+		for v, toIndex := range current {
+			if _, ok := old[v]; !ok {
+				diff.Changes = append(diff.Changes, Change{Added, key.system, key.name, -1, toIndex})
+			}
+		}
+	}
+
+	nodeVersion := func(c Change) string {
+		if c.ToIndex >= 0 {
+			return to.Nodes[c.ToIndex].VersionKey.Version
+		}
+		return from.Nodes[c.FromIndex].VersionKey.Version
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.System != b.System {
+			return a.System < b.System
+		}
+		return version.CompareStrings(nodeVersion(a), nodeVersion(b)) < 0
+	})
+	return diff
+}
+
+func only(versions map[string]int) (string, int) {
+	for v, i := range versions {
+		return v, i
+	}
+	return "", -1
+}
+
+// samePrecedence reports whether two valid versions only differ in build metadata.
+func samePrecedence(a, b string) bool {
+	va, errA := version.Parse(a)
+	vb, errB := version.Parse(b)
+	return errA == nil && errB == nil && version.Compare(va, vb) == 0
+}
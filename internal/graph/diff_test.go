@@ -0,0 +1,69 @@
+package graph_test
+
+import (
+	"codenotary/internal/graph"
+	"codenotary/internal/models"
+	"testing"
+)
+
+func graphOf(versions ...[2]string) *models.DependencyGraph {
+	g := &models.DependencyGraph{Nodes: []models.Node{{VersionKey: models.VersionKey{System: "NPM", Name: "app", Version: "1.0.0"}, Relation: "SELF"}}}
+	for _, v := range versions {
+		g.Nodes = append(g.Nodes, models.Node{VersionKey: models.VersionKey{System: "NPM", Name: v[0], Version: v[1]}, Relation: "DIRECT"})
+	}
+	return g
+}
+
+func TestDiffGraphs(t *testing.T) {
+	from := graphOf([2]string{"bytes", "3.1.2"}, [2]string{"cookie", "0.5.0"}, [2]string{"qs", "6.11.0"},
+		[2]string{"debug", "2.6.9"}, [2]string{"ms", "2.0.0"}, [2]string{"ms", "2.1.3"})
+	to := graphOf([2]string{"bytes", "3.1.2"}, [2]string{"cookie", "0.6.0"}, [2]string{"qs", "6.10.3"},
+		[2]string{"body-parser", "1.20.2"}, [2]string{"ms", "2.1.3"}, [2]string{"ms", "2.1.2"})
+
+	diff := graph.DiffGraphs(from, to)
+	want := []struct{ kind, name, from, to string }{
+		{graph.Added, "body-parser", "", "1.20.2"},
+		{graph.Upgraded, "cookie", "0.5.0", "0.6.0"},
+		{graph.Removed, "debug", "2.6.9", ""},
+		{graph.Removed, "ms", "2.0.0", ""},
+		{graph.Added, "ms", "", "2.1.2"},
+		{graph.Downgraded, "qs", "6.11.0", "6.10.3"},
+	}
+	if len(diff.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %+v", len(diff.Changes), len(want), diff.Changes)
+	}
+	for i, w := range want {
+		c := diff.Changes[i]
+		var fromVersion, toVersion string
+		if c.FromIndex >= 0 {
+			fromVersion = from.Nodes[c.FromIndex].VersionKey.Version
+		}
+		if c.ToIndex >= 0 {
+			toVersion = to.Nodes[c.ToIndex].VersionKey.Version
+		}
+		if c.Kind != w.kind || c.Name != w.name || fromVersion != w.from || toVersion != w.to {
+			t.Errorf("change %d = %s %s %s -> %s, want %s %s %s -> %s", i, c.Kind, c.Name, fromVersion, toVersion, w.kind, w.name, w.from, w.to)
+		}
+	}
+	if diff.Unchanged != 2 {
+		t.Errorf("Unchanged = %d, want 2 (bytes and ms 2.1.3)", diff.Unchanged)
+	}
+}
+
+func TestDiffGraphsBuildMetadata(t *testing.T) {
+	from := graphOf([2]string{"bar", "v2.0.0"}, [2]string{"baz", "1.0.0+build.1"}, [2]string{"qux", "1.0.0+build.1"})
+	to := graphOf([2]string{"bar", "v2.0.0+incompatible"}, [2]string{"baz", "1.0.0+build.2"}, [2]string{"qux", "1.0.0+build.1"})
+
+	diff := graph.DiffGraphs(from, to)
+	if len(diff.Changes) != 2 {
+		t.Fatalf("got %d changes, want 2: %+v", len(diff.Changes), diff.Changes)
+	}
+	for _, c := range diff.Changes {
+		if c.Kind != graph.MetadataChanged {
+			t.Errorf("%s %s -> %s is %s, want %s", c.Name, from.Nodes[c.FromIndex].VersionKey.Version, to.Nodes[c.ToIndex].VersionKey.Version, c.Kind, graph.MetadataChanged)
+		}
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1 (qux)", diff.Unchanged)
+	}
+}
@@ -0,0 +1,224 @@
+// Package graph holds traversals over models.DependencyGraph. Nodes keep the
+// index they have in the graph as returned by deps.dev, so results can always be
+// matched back to the original edges.
+package graph
+
+import (
+	"codenotary/internal/models"
+	"strings"
+)
+
+// Root returns the index of the SELF node, or -1 if the graph has none.
+func Root(g *models.DependencyGraph) int {
+	for i, node := range g.Nodes {
+		if node.Relation == "SELF" {
+			return i
+		}
+	}
+	return -1
+}
+
+// Children returns the outgoing edges of every node, in edge order.
+func Children(g *models.DependencyGraph) [][]models.Edge {
+	children := make([][]models.Edge, len(g.Nodes))
+	for _, edge := range g.Edges {
+		if edge.FromNode < 0 || edge.FromNode >= len(g.Nodes) || edge.ToNode < 0 || edge.ToNode >= len(g.Nodes) {
+			continue
+		}
+		children[edge.FromNode] = append(children[edge.FromNode], edge)
+	}
+	return children
+}
+
+// Depths returns the length of the shortest path from the SELF node to every
+// node: 0 for SELF, 1 for direct dependencies, -1 for unreachable nodes.
+func Depths(g *models.DependencyGraph) []int {
+	depths := make([]int, len(g.Nodes))
+	for i := range depths {
+		depths[i] = -1
+	}
+	root := Root(g)
+	if root < 0 {
+		return depths
+	}
+
+	children := Children(g)
+	depths[root] = 0
+	queue := []int{root}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, edge := range children[current] {
+			if depths[edge.ToNode] < 0 {
+				depths[edge.ToNode] = depths[current] + 1
+				queue = append(queue, edge.ToNode)
+			}
+		}
+	}
+	return depths
+}
+
+// Filter selects nodes of a graph. The zero value keeps everything.
+type Filter struct {
+	// MaxDepth drops nodes further than this from SELF; 0 means no limit.
+	MaxDepth int
+	// Relations keeps only nodes with one of these relations (SELF, DIRECT,
+	// INDIRECT), compared case-insensitively. The SELF node is always kept.
+	Relations []string
+}
+
+// Select returns the indexes of the nodes that pass f, in graph order. Nodes
+// that cannot be reached from SELF only pass when there is no depth limit.
+func Select(g *models.DependencyGraph, f Filter) []int {
+	depths := Depths(g)
+	var selected []int
+	for i, node := range g.Nodes {
+		if node.Relation != "SELF" {
+			if f.MaxDepth > 0 && (depths[i] < 0 || depths[i] > f.MaxDepth) {
+				continue
+			}
+			if len(f.Relations) > 0 && !hasRelation(f.Relations, node.Relation) {
+				continue
+			}
+		}
+		selected = append(selected, i)
+	}
+	return selected
+}
+
+// EdgesBetween returns the edges whose ends are both in nodes.
+func EdgesBetween(g *models.DependencyGraph, nodes []int) []models.Edge {
+	kept := make(map[int]bool, len(nodes))
+	for _, i := range nodes {
+		kept[i] = true
+	}
+	edges := []models.Edge{}
+	for _, edge := range g.Edges {
+		if kept[edge.FromNode] && kept[edge.ToNode] {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+func hasRelation(relations []string, relation string) bool {
+	for _, r := range relations {
+		if strings.EqualFold(strings.TrimSpace(r), relation) {
+			return true
+		}
+	}
+	return false
+}
+
+// Path is a chain of edges starting at the SELF node.
+type Path []models.Edge
+
+// MaxPaths bounds how many paths Paths returns when no limit is given; the
+// number of paths can grow exponentially with the size of the graph.
+const MaxPaths = 1000
+
+// MaxPathStates bounds how many partial paths Paths explores, whatever the
+// limit, so that a densely connected graph can't tie up the search.
+const MaxPathStates = 100000
+
+// Paths returns the paths from the SELF node to any of the targets, shortest
+// first, without visiting a node twice. limit caps the number of paths, 0 means
+// MaxPaths. truncated reports whether paths were left out, either because of the
+// limit or because the search gave up after MaxPathStates partial paths.
+func Paths(g *models.DependencyGraph, targets []int, limit int) (paths []Path, truncated bool) {
+	if limit <= 0 || limit > MaxPaths {
+		limit = MaxPaths
+	}
+	root := Root(g)
+	if root < 0 {
+		return nil, false
+	}
+	isTarget := make(map[int]bool, len(targets))
+	for _, target := range targets {
+		isTarget[target] = true
+	}
+	if isTarget[root] {
+		return []Path{{}}, false
+	}
+	// Partial paths are only extended to nodes a target can be reached from.
+	useful := reaching(g, targets)
+	if !useful[root] {
+		return nil, false
+	}
+
+	// Breadth-first over partial paths, so complete paths come out in order of
+	// length. A state only holds its last edge and the state it extends; paths
+	// are copied out when they reach a target.
+	children := Children(g)
+	type state struct {
+		edge   models.Edge
+		prev   int
+		node   int
+		length int
+	}
+	states := []state{{prev: -1, node: root}}
+	onPath := func(i, node int) bool {
+		for ; i >= 0; i = states[i].prev {
+			if states[i].node == node {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i < len(states); i++ {
+		current := states[i]
+		for _, edge := range children[current.node] {
+			if !useful[edge.ToNode] || onPath(i, edge.ToNode) {
+				continue
+			}
+			if isTarget[edge.ToNode] {
+				if len(paths) == limit {
+					return paths, true
+				}
+				path := make(Path, current.length+1)
+				path[current.length] = edge
+				for j := i; states[j].prev >= 0; j = states[j].prev {
+					path[states[j].length-1] = states[j].edge
+				}
+				paths = append(paths, path)
+				continue
+			}
+			if len(states) == MaxPathStates {
+				return paths, true
+			}
+			states = append(states, state{edge: edge, prev: i, node: edge.ToNode, length: current.length + 1})
+		}
+	}
+	return paths, false
+}
+
+// reaching marks the nodes from which one of the targets can be reached,
+// targets included.
+func reaching(g *models.DependencyGraph, targets []int) []bool {
+	parents := make([][]int, len(g.Nodes))
+	for _, edge := range g.Edges {
+		if edge.FromNode < 0 || edge.FromNode >= len(g.Nodes) || edge.ToNode < 0 || edge.ToNode >= len(g.Nodes) {
+			continue
+		}
+		parents[edge.ToNode] = append(parents[edge.ToNode], edge.FromNode)
+	}
+	marked := make([]bool, len(g.Nodes))
+	var queue []int
+	for _, target := range targets {
+		if target >= 0 && target < len(g.Nodes) && !marked[target] {
+			marked[target] = true
+			queue = append(queue, target)
+		}
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, parent := range parents[current] {
+			if !marked[parent] {
+				marked[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return marked
+}
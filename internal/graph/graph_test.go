@@ -0,0 +1,183 @@
+package graph_test
+
+import (
+	"codenotary/internal/graph"
+	"codenotary/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testGraph mirrors the cobra graph: SELF -> md2man -> blackfriday, SELF -> pflag,
+// SELF -> yaml, plus an orphan node nothing points at.
+func testGraph() *models.DependencyGraph {
+	node := func(name, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "GO", Name: name}, Relation: relation}
+	}
+	return &models.DependencyGraph{
+		Nodes: []models.Node{
+			node("github.com/spf13/cobra", "SELF"),
+			node("github.com/cpuguy83/go-md2man/v2", "DIRECT"),
+			node("github.com/russross/blackfriday/v2", "INDIRECT"),
+			node("github.com/spf13/pflag", "DIRECT"),
+			node("gopkg.in/yaml.v3", "DIRECT"),
+			node("example.com/orphan", "INDIRECT"),
+		},
+		Edges: []models.Edge{
+			{FromNode: 0, ToNode: 1, Requirement: "^2.0.3"},
+			{FromNode: 1, ToNode: 2, Requirement: "^2.1.0"},
+			{FromNode: 0, ToNode: 3, Requirement: "^1.0.5"},
+			{FromNode: 0, ToNode: 4, Requirement: "^3.0.1"},
+		},
+	}
+}
+
+func TestDepths(t *testing.T) {
+	got := graph.Depths(testGraph())
+	want := []int{0, 1, 2, 1, 1, -1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Depths = %v, want %v", got, want)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter graph.Filter
+		want   []int
+	}{
+		{"everything", graph.Filter{}, []int{0, 1, 2, 3, 4, 5}},
+		{"depth 1", graph.Filter{MaxDepth: 1}, []int{0, 1, 3, 4}},
+		{"indirect only", graph.Filter{Relations: []string{"indirect"}}, []int{0, 2, 5}},
+		{"depth and relation", graph.Filter{MaxDepth: 2, Relations: []string{"INDIRECT"}}, []int{0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graph.Select(testGraph(), tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Select = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEdgesBetween(t *testing.T) {
+	g := testGraph()
+	edges := graph.EdgesBetween(g, graph.Select(g, graph.Filter{MaxDepth: 1}))
+	if len(edges) != 3 {
+		t.Fatalf("got %d edges, want the 3 from SELF", len(edges))
+	}
+	for _, edge := range edges {
+		if edge.FromNode != 0 {
+			t.Errorf("edge %+v doesn't start at SELF", edge)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	// Make blackfriday reachable twice: through md2man and straight from pflag.
+	g := testGraph()
+	g.Edges = append(g.Edges, models.Edge{FromNode: 3, ToNode: 2, Requirement: "v2.1.0"})
+
+	paths, truncated := graph.Paths(g, []int{2}, 0)
+	if truncated || len(paths) != 2 {
+		t.Fatalf("got %d paths (truncated %v), want 2", len(paths), truncated)
+	}
+	for _, path := range paths {
+		if path[0].FromNode != 0 || path[len(path)-1].ToNode != 2 {
+			t.Errorf("path %+v doesn't lead from SELF to blackfriday", path)
+		}
+	}
+
+	paths, truncated = graph.Paths(g, []int{2}, 1)
+	if !truncated || len(paths) != 1 || len(paths[0]) != 2 {
+		t.Errorf("Paths with limit 1 = %+v (truncated %v), want one 2-hop path", paths, truncated)
+	}
+
+	if paths, _ := graph.Paths(g, []int{5}, 0); len(paths) != 0 {
+		t.Errorf("found %d paths to an unreachable node", len(paths))
+	}
+}
+
+// layeredGraph has SELF, then layers of width nodes each depending on every
+// node of the next layer, then a last node the final layer depends on.
+func layeredGraph(layers, width int) (g *models.DependencyGraph, last int) {
+	g = &models.DependencyGraph{Nodes: []models.Node{{Relation: "SELF"}}}
+	previous := []int{0}
+	for l := 0; l < layers; l++ {
+		var layer []int
+		for w := 0; w < width; w++ {
+			g.Nodes = append(g.Nodes, models.Node{Relation: "INDIRECT"})
+			layer = append(layer, len(g.Nodes)-1)
+		}
+		for _, from := range previous {
+			for _, to := range layer {
+				g.Edges = append(g.Edges, models.Edge{FromNode: from, ToNode: to})
+			}
+		}
+		previous = layer
+	}
+	g.Nodes = append(g.Nodes, models.Node{Relation: "INDIRECT"})
+	last = len(g.Nodes) - 1
+	return g, last
+}
+
+func TestPathsInWideGraphs(t *testing.T) {
+	g, last := layeredGraph(8, 8)
+
+	// The last node hangs off nothing: no partial path is worth exploring.
+	start := time.Now()
+	if paths, truncated := graph.Paths(g, []int{last}, 0); len(paths) != 0 || truncated {
+		t.Errorf("Paths to an unreachable node = %d paths (truncated %v), want none", len(paths), truncated)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Paths to an unreachable node took %v", elapsed)
+	}
+
+	// 8^8 paths lead to it through the layers: the search gives up.
+	for i := last - 8; i < last; i++ {
+		g.Edges = append(g.Edges, models.Edge{FromNode: i, ToNode: last})
+	}
+	start = time.Now()
+	paths, truncated := graph.Paths(g, []int{last}, 0)
+	if !truncated {
+		t.Errorf("Paths through %d layers = %d paths, not truncated", 8, len(paths))
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Paths through the layers took %v", elapsed)
+	}
+
+	// A node of the first layer is reached right away, whatever lies behind it.
+	paths, truncated = graph.Paths(g, []int{1}, 0)
+	if truncated || len(paths) != 1 || len(paths[0]) != 1 {
+		t.Errorf("Paths to a direct dependency = %+v (truncated %v), want one 1-hop path", paths, truncated)
+	}
+
+	// Paths through a narrow graph come out whole and in order.
+	g, last = layeredGraph(3, 2)
+	for i := last - 2; i < last; i++ {
+		g.Edges = append(g.Edges, models.Edge{FromNode: i, ToNode: last})
+	}
+	paths, truncated = graph.Paths(g, []int{last}, 0)
+	if truncated || len(paths) != 8 {
+		t.Fatalf("got %d paths (truncated %v), want 8", len(paths), truncated)
+	}
+	for _, path := range paths {
+		if len(path) != 4 || path[0].FromNode != 0 || path[3].ToNode != last {
+			t.Errorf("path %+v doesn't lead from SELF to the last node", path)
+		}
+		for i := 1; i < len(path); i++ {
+			if path[i].FromNode != path[i-1].ToNode {
+				t.Errorf("path %+v is broken at hop %d", path, i)
+			}
+		}
+	}
+}
+
+func TestPathsSurviveCycles(t *testing.T) {
+	g := testGraph()
+	g.Edges = append(g.Edges, models.Edge{FromNode: 2, ToNode: 1}, models.Edge{FromNode: 2, ToNode: 0})
+	paths, _ := graph.Paths(g, []int{4}, 0)
+	if len(paths) != 1 {
+		t.Errorf("got %d paths to yaml, want 1", len(paths))
+	}
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type AdvisoryKey struct {
+	ID string `json:"id"`
+}
+
+// Advisory is a security advisory as served by deps.dev's /v3/advisories/{id}.
+// Advisories imported from OSV records also know which versions they affect.
+type Advisory struct {
+	AdvisoryKey AdvisoryKey `json:"advisoryKey"`
+	URL         string      `json:"url"`
+	Title       string      `json:"title"`
+	Aliases     []string    `json:"aliases"`
+	CVSS3Score  float64     `json:"cvss3Score"`
+	CVSS3Vector string      `json:"cvss3Vector"`
+	Severity    string      `json:"-"`
+	Source      string      `json:"-"`
+	Affected    []Affected  `json:"-"`
+	FetchedAt   time.Time   `json:"-"`
+}
+
+// Affected is either a single affected Version of a package or the range from
+// Introduced up to Fixed (excluded) or LastAffected (included).
+type Affected struct {
+	System       string
+	Name         string
+	Version      string
+	Introduced   string
+	Fixed        string
+	LastAffected string
+}
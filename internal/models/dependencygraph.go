@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Node struct {
 	VersionKey VersionKey `json:"versionKey"`
 	Bundled    bool       `json:"bundled"`
@@ -14,7 +16,9 @@ type Edge struct {
 }
 
 type DependencyGraph struct {
-	Nodes []Node `json:"nodes"`
-	Edges []Edge `json:"edges"`
-	Error string `json:"error"`
+	Nodes     []Node    `json:"nodes"`
+	Edges     []Edge    `json:"edges"`
+	Error     string    `json:"error"`
+	FetchedAt time.Time `json:"-"`
+	Stale     bool      `json:"-"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Version struct {
 	VersionKey VersionKey `json:"versionKey"`
 	IsDefault  bool       `json:"isDefault"` 
@@ -19,4 +21,20 @@ type VersionKey struct {
 type PackageVersions struct {
 	PackageKey PackageKey `json:"packageKey"` 
 	Versions   []Version  `json:"versions"`   
+	FetchedAt  time.Time  `json:"-"`
+	Stale      bool       `json:"-"`
+}
+
+type RelatedProject struct {
+	ProjectKey         ProjectKey `json:"projectKey"`
+	RelationProvenance string     `json:"relationProvenance"`
+	RelationType       string     `json:"relationType"`
+}
+
+type VersionDetails struct {
+	VersionKey      VersionKey       `json:"versionKey"`
+	IsDefault       bool             `json:"isDefault"`
+	Licenses        []string         `json:"licenses"`
+	RelatedProjects []RelatedProject `json:"relatedProjects"`
+	AdvisoryKeys    []AdvisoryKey    `json:"advisoryKeys"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Project struct {
 	ProjectKey      ProjectKey `json:"projectKey"`
 	OpenIssuesCount int        `json:"openIssuesCount"`
@@ -9,6 +11,8 @@ type Project struct {
 	Description     string     `json:"description"`
 	Homepage        string     `json:"homepage"`
 	Scorecard       Scorecard  `json:"scorecard"`
+	FetchedAt       time.Time  `json:"-"`
+	Stale           bool       `json:"-"`
 }
 
 type ProjectKey struct {
@@ -46,3 +50,12 @@ type CheckDocumentation struct {
 	ShortDescription string `json:"shortDescription"`
 	URL              string `json:"url"`
 }
+
+type ScorecardSnapshot struct {
+	Date             string             `json:"date"`
+	RepositoryCommit string             `json:"repositoryCommit"`
+	ScorecardVersion string             `json:"scorecardVersion"`
+	OverallScore     float64            `json:"overallScore"`
+	Checks           map[string]float64 `json:"checks"`
+	FetchedAt        time.Time          `json:"fetchedAt"`
+}
@@ -0,0 +1,82 @@
+package osv
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSS3BaseScore computes the base score of a CVSS v3.0 or v3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", following section 7 of the v3.1
+// specification. Temporal and environmental metrics are ignored.
+func CVSS3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1" {
+		return 0, fmt.Errorf("not a CVSS v3 vector: %q", vector)
+	}
+	metrics := make(map[string]string)
+	for _, part := range parts[1:] {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("invalid CVSS metric %q", part)
+		}
+		metrics[name] = value
+	}
+
+	scope := metrics["S"]
+	if scope != "U" && scope != "C" {
+		return 0, fmt.Errorf("invalid CVSS scope %q", scope)
+	}
+	w := make(map[string]float64)
+	for name, values := range cvss3Weights {
+		weight, ok := values[metrics[name]]
+		if !ok {
+			return 0, fmt.Errorf("invalid or missing CVSS metric %s in %q", name, vector)
+		}
+		w[name] = weight
+	}
+	// Privileges weigh more when the impact crosses the scope.
+	if scope == "C" {
+		switch metrics["PR"] {
+		case "L":
+			w["PR"] = 0.68
+		case "H":
+			w["PR"] = 0.5
+		}
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if scope == "C" {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if scope == "C" {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp is the Roundup function of CVSS v3.1: the smallest number with one
+// decimal that is not below x, computed so floating point noise doesn't round
+// 4.0 up to 4.1.
+func roundUp(x float64) float64 {
+	i := math.Round(x * 100000)
+	if math.Mod(i, 10000) == 0 {
+		return i / 100000
+	}
+	return (math.Floor(i/10000) + 1) / 10
+}
@@ -0,0 +1,104 @@
+package osv
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/version"
+	"strings"
+)
+
+// Severities, from the CVSS v3 qualitative rating scale.
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+	SeverityUnknown  = "UNKNOWN"
+)
+
+// Severities lists them from the most to the least severe.
+var Severities = []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow, SeverityUnknown}
+
+// Severity rates a CVSS v3 score, or, when the score is unknown (negative), the
+// severity the advisory states, GitHub's MODERATE included.
+func Severity(cvss3Score float64, stated string) string {
+	switch {
+	case cvss3Score >= 9:
+		return SeverityCritical
+	case cvss3Score >= 7:
+		return SeverityHigh
+	case cvss3Score >= 4:
+		return SeverityMedium
+	case cvss3Score >= 0:
+		return SeverityLow
+	}
+	switch s := strings.ToUpper(stated); s {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+		return s
+	case "MODERATE":
+		return SeverityMedium
+	}
+	return SeverityUnknown
+}
+
+// SeverityRank orders severities, 0 being the most severe; unknown names rank last.
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if s == severity {
+			return i
+		}
+	}
+	return len(Severities) - 1
+}
+
+// Affects reports whether the advisory's affected versions contain this version
+// of the package. Versions are compared as SemVer, which fits most ecosystems;
+// ones that aren't SemVer only match exactly listed versions reliably.
+func Affects(a models.Advisory, system, name, v string) bool {
+	for _, af := range a.Affected {
+		if af.System != system || af.Name != name {
+			continue
+		}
+		if af.Version != "" {
+			if compare(v, af.Version) == 0 {
+				return true
+			}
+			continue
+		}
+		if af.Introduced != "" && af.Introduced != "0" && compare(v, af.Introduced) < 0 {
+			continue
+		}
+		if af.Fixed != "" && compare(v, af.Fixed) >= 0 {
+			continue
+		}
+		if af.LastAffected != "" && compare(v, af.LastAffected) > 0 {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// FixedVersions returns the versions of the package that fix the advisory, lowest first.
+func FixedVersions(a models.Advisory, system, name string) []string {
+	seen := make(map[string]bool)
+	var fixed []string
+	for _, af := range a.Affected {
+		if af.System == system && af.Name == name && af.Fixed != "" && !seen[af.Fixed] {
+			seen[af.Fixed] = true
+			fixed = append(fixed, af.Fixed)
+		}
+	}
+	version.Sort(fixed)
+	return fixed
+}
+
+// compare orders SemVer versions ignoring the "v" prefix Go modules carry and
+// OSV's Go ranges don't; other versions fall back to version.CompareStrings.
+func compare(a, b string) int {
+	va, errA := version.Parse(a)
+	vb, errB := version.Parse(b)
+	if errA == nil && errB == nil {
+		return version.Compare(va, vb)
+	}
+	return version.CompareStrings(a, b)
+}
@@ -0,0 +1,230 @@
+// Package osv reads advisories in the OSV format (https://ossf.github.io/osv-schema/),
+// as published in the dumps of https://osv.dev, and decides which versions they affect.
+package osv
+
+import (
+	"archive/zip"
+	"bytes"
+	"codenotary/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Source marks advisories imported from OSV records; deps.dev ones use store.SourceDepsDev.
+const Source = "osv"
+
+// ecosystems maps OSV ecosystem names to deps.dev systems.
+var ecosystems = map[string]string{
+	"Go":        "GO",
+	"npm":       "NPM",
+	"PyPI":      "PYPI",
+	"Maven":     "MAVEN",
+	"crates.io": "CARGO",
+	"NuGet":     "NUGET",
+	"RubyGems":  "RUBYGEMS",
+}
+
+type vulnerability struct {
+	ID               string           `json:"id"`
+	Summary          string           `json:"summary"`
+	Details          string           `json:"details"`
+	Aliases          []string         `json:"aliases"`
+	Withdrawn        string           `json:"withdrawn"`
+	Severity         []severity       `json:"severity"`
+	Affected         []affected       `json:"affected"`
+	DatabaseSpecific databaseSpecific `json:"database_specific"`
+}
+
+type severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string  `json:"type"`
+		Events []event `json:"events"`
+	} `json:"ranges"`
+	Versions         []string         `json:"versions"`
+	DatabaseSpecific databaseSpecific `json:"database_specific"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+}
+
+type databaseSpecific struct {
+	// Severity is the GitHub advisory database's rating: LOW, MODERATE, HIGH or CRITICAL.
+	Severity string `json:"severity"`
+}
+
+// Parse reads one OSV record or a JSON array of them. Withdrawn records, and the
+// affected packages of ecosystems deps.dev doesn't know, are left out.
+func Parse(data []byte) ([]models.Advisory, error) {
+	data = bytes.TrimSpace(data)
+	var vulns []vulnerability
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &vulns); err != nil {
+			return nil, fmt.Errorf("invalid OSV records: %v", err)
+		}
+	} else {
+		var v vulnerability
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("invalid OSV record: %v", err)
+		}
+		vulns = []vulnerability{v}
+	}
+
+	var advisories []models.Advisory
+	for _, v := range vulns {
+		if v.ID == "" {
+			return nil, fmt.Errorf("OSV record without id")
+		}
+		if v.Withdrawn != "" {
+			continue
+		}
+		if advisory := v.advisory(); len(advisory.Affected) > 0 {
+			advisories = append(advisories, advisory)
+		}
+	}
+	return advisories, nil
+}
+
+// Load reads the OSV records in path: a JSON file, a directory of them, or a zip
+// archive such as https://osv-vulnerabilities.storage.googleapis.com/npm/all.zip.
+func Load(path string) ([]models.Advisory, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return loadFS(os.DirFS(path))
+	}
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		archive, err := zip.OpenReader(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", path, err)
+		}
+		defer archive.Close()
+		return loadFS(archive)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	advisories, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return advisories, nil
+}
+
+func loadFS(fsys fs.FS) ([]models.Advisory, error) {
+	var advisories []models.Advisory
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".json") {
+			return err
+		}
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		parsed, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		advisories = append(advisories, parsed...)
+		return nil
+	})
+	return advisories, err
+}
+
+func (v vulnerability) advisory() models.Advisory {
+	a := models.Advisory{
+		AdvisoryKey: models.AdvisoryKey{ID: v.ID},
+		URL:         "https://osv.dev/vulnerability/" + v.ID,
+		Title:       v.Summary,
+		Aliases:     v.Aliases,
+		CVSS3Score:  -1,
+		Source:      Source,
+		FetchedAt:   time.Now(),
+	}
+	if a.Title == "" {
+		a.Title, _, _ = strings.Cut(v.Details, "\n")
+	}
+	for _, s := range v.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, err := CVSS3BaseScore(s.Score); err == nil {
+			a.CVSS3Score, a.CVSS3Vector = score, s.Score
+		}
+	}
+	stated := v.DatabaseSpecific.Severity
+
+	for _, af := range v.Affected {
+		system, ok := ecosystems[af.Package.Ecosystem]
+		if !ok || af.Package.Name == "" {
+			continue
+		}
+		if stated == "" {
+			stated = af.DatabaseSpecific.Severity
+		}
+		for _, version := range af.Versions {
+			a.Affected = append(a.Affected, models.Affected{System: system, Name: af.Package.Name, Version: version})
+		}
+		for _, r := range af.Ranges {
+			// GIT ranges are commits, which can't be matched against package versions.
+			if r.Type == "GIT" {
+				continue
+			}
+			a.Affected = append(a.Affected, ranges(system, af.Package.Name, r.Events)...)
+		}
+	}
+	a.Severity = Severity(a.CVSS3Score, stated)
+	return a
+}
+
+// ranges turns the events of an OSV range, in order, into introduced..fixed pairs.
+func ranges(system, name string, events []event) []models.Affected {
+	var out []models.Affected
+	var open *models.Affected
+	for _, e := range events {
+		switch {
+		case e.Introduced != "":
+			if open != nil {
+				out = append(out, *open)
+			}
+			open = &models.Affected{System: system, Name: name, Introduced: e.Introduced}
+		case e.Fixed != "" && open != nil:
+			open.Fixed = e.Fixed
+			out = append(out, *open)
+			open = nil
+		case e.LastAffected != "" && open != nil:
+			open.LastAffected = e.LastAffected
+			out = append(out, *open)
+			open = nil
+		}
+	}
+	if open != nil {
+		out = append(out, *open)
+	}
+	return out
+}
@@ -0,0 +1,141 @@
+package osv_test
+
+import (
+	"archive/zip"
+	"codenotary/internal/osv"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const cookieRecord = `{
+  "id": "GHSA-pxg6-pf52-xh8x",
+  "summary": "cookie accepts cookie name, path, and domain with out of bounds characters",
+  "aliases": ["CVE-2024-47764"],
+  "database_specific": {"severity": "LOW"},
+  "affected": [
+    {
+      "package": {"ecosystem": "npm", "name": "cookie"},
+      "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.7.0"}]}]
+    },
+    {
+      "package": {"ecosystem": "Hex", "name": "cookie"},
+      "versions": ["1.0.0"]
+    }
+  ]
+}`
+
+const goRecord = `{
+  "id": "GO-2022-0001",
+  "details": "Unbounded recursion in the parser.\nMore details.",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [
+    {
+      "package": {"ecosystem": "Go", "name": "example.com/parser"},
+      "ranges": [
+        {"type": "SEMVER", "events": [{"introduced": "1.2.0"}, {"fixed": "1.2.5"}, {"introduced": "1.3.0"}, {"last_affected": "1.3.1"}]},
+        {"type": "GIT", "events": [{"introduced": "abc"}, {"fixed": "def"}]}
+      ],
+      "versions": ["0.9.0"]
+    }
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	advisories, err := osv.Parse([]byte("[" + cookieRecord + "," + goRecord + `,{"id": "GHSA-gone", "withdrawn": "2024-01-01T00:00:00Z"}]`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(advisories) != 2 {
+		t.Fatalf("got %d advisories, want 2 (withdrawn records are skipped)", len(advisories))
+	}
+
+	cookie := advisories[0]
+	if cookie.Severity != osv.SeverityLow || cookie.CVSS3Score != -1 || cookie.Source != osv.Source {
+		t.Errorf("cookie: severity %s, score %v, source %s", cookie.Severity, cookie.CVSS3Score, cookie.Source)
+	}
+	if len(cookie.Affected) != 1 {
+		t.Errorf("cookie affects %+v, want only the npm package", cookie.Affected)
+	}
+
+	parser := advisories[1]
+	if parser.Title != "Unbounded recursion in the parser." {
+		t.Errorf("title = %q, want the first line of details", parser.Title)
+	}
+	if parser.Severity != osv.SeverityCritical || parser.CVSS3Score != 9.8 {
+		t.Errorf("parser: severity %s, score %v; want CRITICAL 9.8", parser.Severity, parser.CVSS3Score)
+	}
+
+	for _, tt := range []struct {
+		version  string
+		affected bool
+	}{
+		{"v0.9.0", true},
+		{"v1.1.0", false},
+		{"v1.2.0", true},
+		{"v1.2.4", true},
+		{"v1.2.5", false},
+		{"v1.3.1", true},
+		{"v1.3.2", false},
+	} {
+		if got := osv.Affects(parser, "GO", "example.com/parser", tt.version); got != tt.affected {
+			t.Errorf("Affects(%s) = %v, want %v", tt.version, got, tt.affected)
+		}
+	}
+	if osv.Affects(cookie, "NPM", "cookie", "0.7.0") || !osv.Affects(cookie, "NPM", "cookie", "0.5.0") {
+		t.Error("cookie should affect 0.5.0 and not 0.7.0")
+	}
+	if fixed := osv.FixedVersions(parser, "GO", "example.com/parser"); len(fixed) != 1 || fixed[0] != "1.2.5" {
+		t.Errorf("FixedVersions = %v, want [1.2.5]", fixed)
+	}
+}
+
+func TestLoadZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(f)
+	for name, record := range map[string]string{"GHSA-pxg6-pf52-xh8x.json": cookieRecord, "GO-2022-0001.json": goRecord} {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(record))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	advisories, err := osv.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(advisories) != 2 {
+		t.Errorf("loaded %d advisories, want 2", len(advisories))
+	}
+}
+
+func TestCVSS3BaseScore(t *testing.T) {
+	for vector, want := range map[string]float64{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H": 9.8,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H": 7.5,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N": 6.1,
+		"CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N": 1.8,
+		"CVSS:3.1/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H": 9.9,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N": 0,
+	} {
+		got, err := osv.CVSS3BaseScore(vector)
+		if err != nil || got != want {
+			t.Errorf("CVSS3BaseScore(%s) = %v, %v; want %v", vector, got, err, want)
+		}
+	}
+	if _, err := osv.CVSS3BaseScore("CVSS:2.0/AV:N"); err == nil {
+		t.Error("accepted a CVSS v2 vector")
+	}
+	if _, err := osv.CVSS3BaseScore("CVSS:3.1/AV:N/AC:L/S:U/C:H/I:H/A:H"); err == nil {
+		t.Error("accepted a vector without PR and UI")
+	}
+}
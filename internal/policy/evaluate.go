@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"codenotary/internal/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Input is the graph a policy is evaluated against.
+type Input struct {
+	Graph *models.DependencyGraph
+	// Projects and Checks are aligned with Graph.Nodes. Checks holds the stored
+	// scorecard check scores of each node's project; nil entries are unknown.
+	Projects []*models.Project
+	Checks   []map[string]int
+}
+
+// Violation is a rule a dependency breaks.
+type Violation struct {
+	Rule     string
+	Severity string
+	Message  string
+}
+
+// Dependency is a node with at least one violation.
+type Dependency struct {
+	Index      int
+	Node       models.Node
+	Violations []Violation
+}
+
+type Result struct {
+	// Passed is false when any rule of severity error is violated.
+	Passed       bool
+	Evaluated    int
+	Errors       int
+	Warnings     int
+	Dependencies []Dependency
+}
+
+// Evaluate applies every rule to every dependency the rule covers.
+func (p *Policy) Evaluate(in Input) Result {
+	result := Result{Passed: true, Dependencies: []Dependency{}}
+	for i, node := range in.Graph.Nodes {
+		if node.Relation == "SELF" {
+			continue
+		}
+		result.Evaluated++
+
+		var project *models.Project
+		if i < len(in.Projects) {
+			project = in.Projects[i]
+		}
+		var checks map[string]int
+		if i < len(in.Checks) {
+			checks = in.Checks[i]
+		}
+
+		var violations []Violation
+		for _, rule := range p.Rules {
+			if !rule.appliesTo(node.Relation) {
+				continue
+			}
+			if message, ok := rule.evaluate(project, checks); !ok {
+				violations = append(violations, Violation{Rule: rule.Name, Severity: rule.Severity, Message: message})
+				if rule.Severity == SeverityWarning {
+					result.Warnings++
+				} else {
+					result.Errors++
+					result.Passed = false
+				}
+			}
+		}
+		if len(violations) > 0 {
+			result.Dependencies = append(result.Dependencies, Dependency{Index: i, Node: node, Violations: violations})
+		}
+	}
+	return result
+}
+
+// evaluate reports whether a dependency satisfies the rule and, if not, why.
+func (r *Rule) evaluate(project *models.Project, checks map[string]int) (string, bool) {
+	switch r.Type {
+	case TypeScore:
+		if project == nil || project.Scorecard.OverallScore < 0 {
+			return "no OpenSSF scorecard available", r.AllowUnknown
+		}
+		if project.Scorecard.OverallScore < *r.Min {
+			return fmt.Sprintf("overall score %s is below %s", formatScore(project.Scorecard.OverallScore), formatScore(*r.Min)), false
+		}
+	case TypeCheck:
+		score, ok := checkScore(checks, r.Check)
+		if !ok || score < 0 {
+			return fmt.Sprintf("no %s check result available", r.Check), r.AllowUnknown
+		}
+		if float64(score) < *r.Min {
+			return fmt.Sprintf("%s check score %d is below %s", r.Check, score, formatScore(*r.Min)), false
+		}
+	case TypeLicense:
+		if project == nil || project.License == "" || project.License == "NOASSERTION" {
+			return "license is unknown", r.AllowUnknown
+		}
+		if !r.licenseAllowed(project.License) {
+			return fmt.Sprintf("license %s is not allowed", project.License), false
+		}
+	}
+	return "", true
+}
+
+// checkScore looks a check up by name, case-insensitively like scoring profiles
+// do, so that a rule on "code-review" applies to the stored "Code-Review".
+func checkScore(checks map[string]int, name string) (int, bool) {
+	if score, ok := checks[name]; ok {
+		return score, true
+	}
+	for check, score := range checks {
+		if strings.EqualFold(check, name) {
+			return score, true
+		}
+	}
+	return 0, false
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
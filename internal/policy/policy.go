@@ -0,0 +1,215 @@
+// Package policy evaluates admission rules, such as a minimum OpenSSF score or a
+// license allow-list, against the dependencies of a graph.
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule types.
+const (
+	// TypeScore requires an overall OpenSSF score of at least Min.
+	TypeScore = "score"
+	// TypeCheck requires the score of the scorecard check Check to be at least Min.
+	TypeCheck = "check"
+	// TypeLicense requires a license from Allow, if set, and none from Deny.
+	TypeLicense = "license"
+)
+
+// Severities. Only errors make an evaluation fail.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+type Policy struct {
+	Rules []Rule `yaml:"rules" json:"rules"`
+}
+
+type Rule struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	Type        string `yaml:"type" json:"type"`
+	// Relations limits the rule to DIRECT or INDIRECT dependencies; empty means both.
+	Relations []string `yaml:"relations" json:"relations"`
+	Check     string   `yaml:"check" json:"check"`
+	Min       *float64 `yaml:"min" json:"min"`
+	Allow     []string `yaml:"allow" json:"allow"`
+	Deny      []string `yaml:"deny" json:"deny"`
+	// AllowUnknown lets dependencies without a scorecard, check result or license
+	// pass; by default they violate the rule.
+	AllowUnknown bool   `yaml:"allow_unknown" json:"allow_unknown"`
+	Severity     string `yaml:"severity" json:"severity"`
+}
+
+// Load reads a policy file, JSON or YAML.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %v", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and validates a policy. Documents starting with "{" are read as
+// JSON, anything else as YAML; unknown fields are rejected in both.
+func Parse(data []byte) (*Policy, error) {
+	var p Policy
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid policy JSON: %v", err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid policy YAML: %v", err)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks every rule and fills in the default severity.
+func (p *Policy) Validate() error {
+	if len(p.Rules) == 0 {
+		return fmt.Errorf("policy has no rules")
+	}
+	names := make(map[string]bool)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("rule %q is defined twice", rule.Name)
+		}
+		names[rule.Name] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %v", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *Rule) validate() error {
+	switch r.Type {
+	case TypeScore, TypeCheck:
+		if r.Type == TypeCheck && r.Check == "" {
+			return fmt.Errorf("check rules need a check name")
+		}
+		if r.Min == nil || *r.Min < 0 || *r.Min > 10 {
+			return fmt.Errorf("min must be between 0 and 10")
+		}
+	case TypeLicense:
+		if len(r.Allow) == 0 && len(r.Deny) == 0 {
+			return fmt.Errorf("license rules need an allow or deny list")
+		}
+	default:
+		return fmt.Errorf("unknown type %q, use %s, %s or %s", r.Type, TypeScore, TypeCheck, TypeLicense)
+	}
+
+	for _, relation := range r.Relations {
+		if relation != "DIRECT" && relation != "INDIRECT" {
+			return fmt.Errorf("unknown relation %q, use DIRECT or INDIRECT", relation)
+		}
+	}
+	switch r.Severity {
+	case "":
+		r.Severity = SeverityError
+	case SeverityError, SeverityWarning:
+	default:
+		return fmt.Errorf("unknown severity %q, use %s or %s", r.Severity, SeverityError, SeverityWarning)
+	}
+	return nil
+}
+
+func (r *Rule) appliesTo(relation string) bool {
+	if relation == "SELF" {
+		return false
+	}
+	if len(r.Relations) == 0 {
+		return true
+	}
+	for _, allowed := range r.Relations {
+		if allowed == relation {
+			return true
+		}
+	}
+	return false
+}
+
+// licenseAllowed evaluates an SPDX expression against the rule: every operand of
+// an AND must be acceptable, one operand of an OR is enough. An expression that
+// doesn't parse is judged as a whole, like a single license.
+func (r *Rule) licenseAllowed(expression string) bool {
+	expr, err := parseLicenseExpression(expression)
+	if err != nil {
+		return r.licenseAccepted(strings.TrimSpace(expression), "")
+	}
+	return r.exprAllowed(expr)
+}
+
+func (r *Rule) exprAllowed(expr *licenseExpr) bool {
+	switch expr.Op {
+	case "AND":
+		for _, operand := range expr.Operands {
+			if !r.exprAllowed(operand) {
+				return false
+			}
+		}
+		return true
+	case "OR":
+		for _, operand := range expr.Operands {
+			if r.exprAllowed(operand) {
+				return true
+			}
+		}
+		return false
+	default:
+		return r.licenseAccepted(expr.License, expr.Exception)
+	}
+}
+
+// licenseAccepted judges a license with its exception, if any. An Allow or Deny
+// entry naming the license with that exception ("GPL-2.0-only WITH
+// Classpath-exception-2.0") decides first; otherwise the license alone counts.
+func (r *Rule) licenseAccepted(license, exception string) bool {
+	if exception != "" {
+		withException := license + " WITH " + exception
+		if listsLicense(r.Deny, withException) {
+			return false
+		}
+		if listsLicense(r.Allow, withException) {
+			return true
+		}
+	}
+	if listsLicense(r.Deny, license) {
+		return false
+	}
+	return len(r.Allow) == 0 || listsLicense(r.Allow, license)
+}
+
+// listsLicense compares licenses ignoring case and the spacing around WITH.
+func listsLicense(list []string, license string) bool {
+	for _, entry := range list {
+		if strings.EqualFold(strings.Join(strings.Fields(entry), " "), license) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package policy_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/policy"
+	"strings"
+	"testing"
+)
+
+const testPolicy = `
+rules:
+  - name: direct-score
+    description: No direct dependency with an overall score below 5
+    type: score
+    relations: [DIRECT]
+    min: 5
+  - name: maintained
+    type: check
+    check: Maintained
+    min: 3
+    allow_unknown: true
+    severity: warning
+  - name: licenses
+    type: license
+    allow: [MIT, Apache-2.0]
+`
+
+func TestParse(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Rules) != 3 || p.Rules[0].Severity != policy.SeverityError || *p.Rules[0].Min != 5 {
+		t.Errorf("rules = %+v, want three with the default severity filled in", p.Rules)
+	}
+
+	json := `{"rules": [{"name": "score", "type": "score", "min": 4.5}]}`
+	if p, err := policy.Parse([]byte(json)); err != nil || *p.Rules[0].Min != 4.5 {
+		t.Errorf("Parse(JSON) = %+v, %v", p, err)
+	}
+
+	invalid := map[string]string{
+		"no rules":        `rules: []`,
+		"unknown field":   `{"rules": [{"name": "a", "type": "score", "min": 5, "minimum": 5}]}`,
+		"unknown type":    `rules: [{name: a, type: stars, min: 5}]`,
+		"missing min":     `rules: [{name: a, type: score}]`,
+		"min above 10":    `rules: [{name: a, type: score, min: 11}]`,
+		"missing check":   `rules: [{name: a, type: check, min: 5}]`,
+		"empty license":   `rules: [{name: a, type: license}]`,
+		"duplicate name":  `rules: [{name: a, type: score, min: 1}, {name: a, type: score, min: 2}]`,
+		"bad relation":    `rules: [{name: a, type: score, min: 1, relations: [SELF]}]`,
+		"bad severity":    `rules: [{name: a, type: score, min: 1, severity: fatal}]`,
+		"missing rule id": `rules: [{type: score, min: 1}]`,
+	}
+	for name, doc := range invalid {
+		if _, err := policy.Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", name)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p, err := policy.Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := func(name, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "NPM", Name: name, Version: "1.0.0"}, Relation: relation}
+	}
+	project := func(score float64, license string) *models.Project {
+		return &models.Project{License: license, Scorecard: models.Scorecard{OverallScore: score}}
+	}
+	in := policy.Input{
+		Graph: &models.DependencyGraph{Nodes: []models.Node{
+			node("app", "SELF"),
+			node("good", "DIRECT"),
+			node("weak", "DIRECT"),
+			node("weak-indirect", "INDIRECT"),
+			node("unknown", "DIRECT"),
+			node("dual", "INDIRECT"),
+		}},
+		Projects: []*models.Project{
+			project(1, "GPL-3.0"),
+			project(8, "MIT"),
+			project(3.5, "Apache-2.0"),
+			project(2, "MIT"),
+			nil,
+			project(7, "(GPL-2.0 OR MIT)"),
+		},
+		Checks: []map[string]int{
+			nil,
+			{"Maintained": 10},
+			{"Maintained": 0},
+			{"Maintained": 5},
+			nil,
+			{"Maintained": -1},
+		},
+	}
+
+	result := p.Evaluate(in)
+	if result.Passed || result.Evaluated != 5 || result.Errors != 3 || result.Warnings != 1 {
+		t.Errorf("result = %+v, want 5 evaluated, 3 errors and 1 warning", result)
+	}
+
+	got := make(map[string][]string)
+	for _, dependency := range result.Dependencies {
+		for _, violation := range dependency.Violations {
+			got[dependency.Node.VersionKey.Name] = append(got[dependency.Node.VersionKey.Name], violation.Rule+": "+violation.Message)
+		}
+	}
+	want := map[string][]string{
+		"weak":    {"direct-score: overall score 3.5 is below 5", "maintained: Maintained check score 0 is below 3"},
+		"unknown": {"direct-score: no OpenSSF scorecard available", "licenses: license is unknown"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("violations = %v, want %v", got, want)
+	}
+	for name, messages := range want {
+		if strings.Join(got[name], "\n") != strings.Join(messages, "\n") {
+			t.Errorf("%s: violations = %q, want %q", name, got[name], messages)
+		}
+	}
+
+	// Check names match whatever their case.
+	lowercase, _ := policy.Parse([]byte(`rules: [{name: maintained, type: check, check: maintained, min: 3}]`))
+	result = lowercase.Evaluate(in)
+	if result.Errors != 3 || result.Dependencies[0].Node.VersionKey.Name != "weak" || result.Dependencies[0].Violations[0].Message != "maintained check score 0 is below 3" {
+		t.Errorf("lowercase check: result = %+v, want weak to violate it", result)
+	}
+
+	lenient, _ := policy.Parse([]byte(`rules: [{name: a, type: score, min: 9, severity: warning}]`))
+	if result := lenient.Evaluate(in); !result.Passed || result.Warnings != 5 {
+		t.Errorf("warnings only: result = %+v, want a pass with 5 warnings", result)
+	}
+}
+
+func TestLicenseExpressions(t *testing.T) {
+	rules := map[string]string{
+		"deny":  `rules: [{name: l, type: license, deny: [BSD-3-Clause, GPL-2.0-only]}]`,
+		"allow": `rules: [{name: l, type: license, allow: [MIT, Apache-2.0, "GPL-2.0-only WITH Classpath-exception-2.0"]}]`,
+	}
+	tests := []struct {
+		rule, license string
+		passed        bool
+	}{
+		{"deny", "MIT", true},
+		{"deny", "BSD-3-Clause", false},
+		{"deny", "MIT OR BSD-3-Clause", true},
+		{"deny", "MIT AND BSD-3-Clause", false},
+		{"deny", "(MIT OR Apache-2.0) AND BSD-3-Clause", false},
+		{"deny", "BSD-3-Clause AND (MIT OR Apache-2.0)", false},
+		{"deny", "MIT OR Apache-2.0 AND BSD-3-Clause", true},
+		{"deny", "BSD-3-Clause OR Apache-2.0 AND MIT", true},
+		{"deny", "(BSD-3-Clause OR (GPL-2.0-only AND MIT)) AND MIT", false},
+		{"deny", "(BSD-3-Clause OR (ISC AND MIT)) AND MIT", true},
+		{"deny", "mit and bsd-3-clause", false},
+		{"deny", "mit or bsd-3-clause", true},
+		{"deny", "GPL-2.0-only WITH Classpath-exception-2.0", false},
+		{"allow", "GPL-2.0-only WITH Classpath-exception-2.0", true},
+		{"allow", "GPL-2.0-only", false},
+		{"allow", "GPL-2.0-only WITH Autoconf-exception-2.0", false},
+		{"allow", "MIT WITH Some-exception", true},
+		{"allow", "(GPL-2.0-only with Classpath-exception-2.0) AND MIT", true},
+		{"allow", "(MIT AND ISC) OR Apache-2.0", true},
+		{"allow", "(MIT AND ISC) OR GPL-3.0-only", false},
+		// Expressions that don't parse are compared as a whole.
+		{"allow", "MIT AND", false},
+		{"deny", "(MIT", true},
+	}
+	for _, tt := range tests {
+		p, err := policy.Parse([]byte(rules[tt.rule]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		in := policy.Input{
+			Graph: &models.DependencyGraph{Nodes: []models.Node{
+				{VersionKey: models.VersionKey{System: "NPM", Name: "app", Version: "1.0.0"}, Relation: "SELF"},
+				{VersionKey: models.VersionKey{System: "NPM", Name: "dep", Version: "1.0.0"}, Relation: "DIRECT"},
+			}},
+			Projects: []*models.Project{nil, {License: tt.license}},
+			Checks:   []map[string]int{nil, nil},
+		}
+		if result := p.Evaluate(in); result.Passed != tt.passed {
+			t.Errorf("%s %q: passed = %v, want %v", tt.rule, tt.license, result.Passed, tt.passed)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package policy
+
+import (
+	"codenotary/internal/models"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Report is an evaluated policy together with what it was evaluated against,
+// ready to be written for people or CI tools.
+type Report struct {
+	System  string
+	Name    string
+	Version string
+	// Artifact is the manifest the graph came from (a go.mod path), used as the
+	// location of SARIF results; empty when the graph came from deps.dev.
+	Artifact string
+	Policy   *Policy
+	Graph    *models.DependencyGraph
+	Result   Result
+}
+
+func (r Report) subject() string {
+	return r.System + "/" + r.Name + "@" + r.Version
+}
+
+func (r Report) violations() map[int][]Violation {
+	violations := make(map[int][]Violation)
+	for _, dependency := range r.Result.Dependencies {
+		violations[dependency.Index] = dependency.Violations
+	}
+	return violations
+}
+
+// WriteText writes a summary line followed by one line per violation.
+func (r Report) WriteText(w io.Writer) error {
+	status := "PASS"
+	if !r.Result.Passed {
+		status = "FAIL"
+	}
+	fmt.Fprintf(w, "%s %s: %d dependencies checked against %d rules, %d errors, %d warnings\n",
+		status, r.subject(), r.Result.Evaluated, len(r.Policy.Rules), r.Result.Errors, r.Result.Warnings)
+	if len(r.Result.Dependencies) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, dependency := range r.Result.Dependencies {
+		for _, violation := range dependency.Violations {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s: %s\n", violation.Severity, dependency.Node.VersionKey.Name,
+				dependency.Node.VersionKey.Version, dependency.Node.Relation, violation.Rule, violation.Message)
+		}
+	}
+	return tw.Flush()
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit XML report with one test case per dependency. Errors
+// are failures; warnings only show up in the case's output.
+func (r Report) WriteJUnit(w io.Writer) error {
+	violations := r.violations()
+	suite := junitTestSuite{Name: r.subject(), Cases: []junitTestCase{}}
+	for i, node := range r.Graph.Nodes {
+		if node.Relation == "SELF" {
+			continue
+		}
+		testCase := junitTestCase{
+			Name:      node.VersionKey.Name + "@" + node.VersionKey.Version,
+			ClassName: node.VersionKey.System + "." + node.Relation,
+		}
+		var failures, warnings string
+		for _, violation := range violations[i] {
+			line := violation.Rule + ": " + violation.Message + "\n"
+			if violation.Severity == SeverityWarning {
+				warnings += "warning " + line
+				continue
+			}
+			if testCase.Failure == nil {
+				testCase.Failure = &junitFailure{Message: violation.Message, Type: violation.Rule}
+			}
+			failures += line
+		}
+		if testCase.Failure != nil {
+			testCase.Failure.Text = failures
+			suite.Failures++
+		}
+		testCase.SystemOut = warnings
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Name: "codenotary", Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes a SARIF 2.1.0 log with one result per violation, for code
+// scanning dashboards.
+func (r Report) WriteSARIF(w io.Writer) error {
+	driver := sarifDriver{Name: "codenotary", Rules: []sarifRule{}}
+	for _, rule := range r.Policy.Rules {
+		description := rule.Description
+		if description == "" {
+			description = rule.Name
+		}
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:                   rule.Name,
+			ShortDescription:     sarifMessage{Text: description},
+			DefaultConfiguration: sarifConfiguration{Level: rule.Severity},
+		})
+	}
+
+	results := []sarifResult{}
+	for _, dependency := range r.Result.Dependencies {
+		key := dependency.Node.VersionKey
+		location := sarifLocation{LogicalLocations: []sarifLogicalLocation{{
+			Name:               key.Name,
+			FullyQualifiedName: key.System + "/" + key.Name + "@" + key.Version,
+			Kind:               "module",
+		}}}
+		if r.Artifact != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: r.Artifact}}
+		}
+		for _, violation := range dependency.Violations {
+			results = append(results, sarifResult{
+				RuleID:    violation.Rule,
+				Level:     violation.Severity,
+				Message:   sarifMessage{Text: fmt.Sprintf("%s@%s: %s", key.Name, key.Version, violation.Message)},
+				Locations: []sarifLocation{location},
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	})
+}
@@ -0,0 +1,130 @@
+package policy_test
+
+import (
+	"bytes"
+	"codenotary/internal/models"
+	"codenotary/internal/policy"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func testReport(t *testing.T) policy.Report {
+	p, err := policy.Parse([]byte(`
+rules:
+  - {name: min-score, type: score, min: 5}
+  - {name: maintained, type: check, check: Maintained, min: 3, severity: warning}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	graph := &models.DependencyGraph{Nodes: []models.Node{
+		{VersionKey: models.VersionKey{System: "GO", Name: "example.com/app", Version: "local"}, Relation: "SELF"},
+		{VersionKey: models.VersionKey{System: "GO", Name: "github.com/good/lib", Version: "v1.0.0"}, Relation: "DIRECT"},
+		{VersionKey: models.VersionKey{System: "GO", Name: "github.com/weak/lib", Version: "v0.3.0"}, Relation: "INDIRECT"},
+	}}
+	in := policy.Input{
+		Graph: graph,
+		Projects: []*models.Project{
+			nil,
+			{Scorecard: models.Scorecard{OverallScore: 7}},
+			{Scorecard: models.Scorecard{OverallScore: 2.5}},
+		},
+		Checks: []map[string]int{nil, {"Maintained": 10}, {"Maintained": 0}},
+	}
+	return policy.Report{System: "GO", Name: "example.com/app", Version: "local", Artifact: "go.mod", Policy: p, Graph: graph, Result: p.Evaluate(in)}
+}
+
+func TestReportText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport(t).WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"FAIL GO/example.com/app@local: 2 dependencies checked against 2 rules, 1 errors, 1 warnings",
+		"min-score: overall score 2.5 is below 5",
+		"maintained: Maintained check score 0 is below 3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("report doesn't contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestReportJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport(t).WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var suites struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Type string `xml:"type,attr"`
+				} `xml:"failure"`
+				SystemOut string `xml:"system-out"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid JUnit XML: %v\n%s", err, buf.String())
+	}
+	if suites.Tests != 2 || suites.Failures != 1 || len(suites.Suites) != 1 || len(suites.Suites[0].Cases) != 2 {
+		t.Fatalf("got %+v, want one suite with 2 tests and 1 failure", suites)
+	}
+	good, weak := suites.Suites[0].Cases[0], suites.Suites[0].Cases[1]
+	if good.Name != "github.com/good/lib@v1.0.0" || good.Failure != nil {
+		t.Errorf("first case = %+v, want a passing github.com/good/lib", good)
+	}
+	if weak.Failure == nil || weak.Failure.Type != "min-score" || !strings.Contains(weak.SystemOut, "maintained") {
+		t.Errorf("second case = %+v, want a min-score failure and the warning in its output", weak)
+	}
+}
+
+func TestReportSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testReport(t).WriteSARIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("got %+v, want one 2.1.0 run describing both rules", log)
+	}
+	results := log.Runs[0].Results
+	if len(results) != 2 || results[0].RuleID != "min-score" || results[0].Level != "error" || results[1].Level != "warning" {
+		t.Fatalf("results = %+v, want the min-score error and the maintained warning", results)
+	}
+	if uri := results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI; uri != "go.mod" {
+		t.Errorf("location = %q, want go.mod", uri)
+	}
+}
@@ -0,0 +1,129 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// licenseExpr is a parsed SPDX license expression (SPDX specification, annex D):
+// licenses joined by AND and OR, AND binding tighter than OR, grouped with
+// parentheses, and WITH attaching an exception to the license before it.
+type licenseExpr struct {
+	// Op is "AND" or "OR" for a compound expression, empty for a single license.
+	Op       string
+	Operands []*licenseExpr
+	License  string
+	// Exception is the exception of "License WITH Exception", empty without.
+	Exception string
+}
+
+// parseLicenseExpression parses an SPDX license expression. Operators are
+// matched whatever their case.
+func parseLicenseExpression(s string) (*licenseExpr, error) {
+	p := &licenseParser{tokens: tokenizeLicense(s)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty license expression")
+	}
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if tok, ok := p.peek(); ok {
+		return nil, fmt.Errorf("unexpected %q in license expression %q", tok, s)
+	}
+	return expr, nil
+}
+
+// tokenizeLicense splits an expression into parentheses and words.
+func tokenizeLicense(s string) []string {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	return strings.Fields(s)
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() (string, bool) {
+	if p.pos >= len(p.tokens) {
+		return "", false
+	}
+	return p.tokens[p.pos], true
+}
+
+// accept consumes the next token if it is the operator or parenthesis want.
+func (p *licenseParser) accept(want string) bool {
+	if tok, ok := p.peek(); ok && strings.EqualFold(tok, want) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// or parses and-expressions joined by OR.
+func (p *licenseParser) or() (*licenseExpr, error) {
+	return p.compound("OR", p.and)
+}
+
+// and parses single licenses and groups joined by AND.
+func (p *licenseParser) and() (*licenseExpr, error) {
+	return p.compound("AND", p.term)
+}
+
+func (p *licenseParser) compound(op string, operand func() (*licenseExpr, error)) (*licenseExpr, error) {
+	first, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpr{first}
+	for p.accept(op) {
+		next, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, next)
+	}
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &licenseExpr{Op: op, Operands: operands}, nil
+}
+
+// term parses a parenthesized expression or a license with its exception.
+func (p *licenseParser) term() (*licenseExpr, error) {
+	if p.accept("(") {
+		expr, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ) in license expression")
+		}
+		return expr, nil
+	}
+	license, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	expr := &licenseExpr{License: license}
+	if p.accept("WITH") {
+		if expr.Exception, err = p.identifier(); err != nil {
+			return nil, err
+		}
+	}
+	return expr, nil
+}
+
+func (p *licenseParser) identifier() (string, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return "", fmt.Errorf("license expression ends early")
+	}
+	switch strings.ToUpper(tok) {
+	case "(", ")", "AND", "OR", "WITH":
+		return "", fmt.Errorf("expected a license, got %q", tok)
+	}
+	p.pos++
+	return tok, nil
+}
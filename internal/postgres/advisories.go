@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (s *Store) InsertAdvisories(advisories []models.Advisory) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, a := range advisories {
+		_, err := tx.Exec(`
+			INSERT INTO advisories (id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT(id) DO UPDATE SET
+				source=excluded.source, title=excluded.title, url=excluded.url, aliases=excluded.aliases,
+				severity=excluded.severity, cvss3_score=excluded.cvss3_score, cvss3_vector=excluded.cvss3_vector,
+				fetched_at=excluded.fetched_at`,
+			a.AdvisoryKey.ID, a.Source, a.Title, a.URL, strings.Join(a.Aliases, ";"),
+			a.Severity, a.CVSS3Score, a.CVSS3Vector, nowIfZero(a.FetchedAt))
+		if err != nil {
+			return fmt.Errorf("failed to insert advisory %s: %v", a.AdvisoryKey.ID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM advisory_affected WHERE advisory_id = $1`, a.AdvisoryKey.ID); err != nil {
+			return fmt.Errorf("failed to replace affected versions of %s: %v", a.AdvisoryKey.ID, err)
+		}
+		for _, af := range a.Affected {
+			_, err := tx.Exec(`
+				INSERT INTO advisory_affected (advisory_id, system, name, version, introduced, fixed, last_affected)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				a.AdvisoryKey.ID, af.System, af.Name, af.Version, af.Introduced, af.Fixed, af.LastAffected)
+			if err != nil {
+				return fmt.Errorf("failed to insert affected versions of %s: %v", a.AdvisoryKey.ID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetAdvisories(ids []string) ([]models.Advisory, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return s.queryAdvisories(`
+		SELECT id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at
+		FROM advisories
+		WHERE id = ANY($1)
+		ORDER BY id`, pq.Array(ids))
+}
+
+func (s *Store) ListPackageAdvisories(system, name string) ([]models.Advisory, error) {
+	return s.queryAdvisories(`
+		SELECT id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at
+		FROM advisories
+		WHERE id IN (SELECT advisory_id FROM advisory_affected WHERE system = $1 AND name = $2)
+		ORDER BY id`, system, name)
+}
+
+func (s *Store) queryAdvisories(query string, args ...interface{}) ([]models.Advisory, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying advisories: %v", err)
+	}
+	defer rows.Close()
+
+	var advisories []models.Advisory
+	for rows.Next() {
+		var a models.Advisory
+		var aliases string
+		var fetchedAt sql.NullTime
+		if err := rows.Scan(&a.AdvisoryKey.ID, &a.Source, &a.Title, &a.URL, &aliases,
+			&a.Severity, &a.CVSS3Score, &a.CVSS3Vector, &fetchedAt); err != nil {
+			return nil, fmt.Errorf("error scanning advisories row: %v", err)
+		}
+		if aliases != "" {
+			a.Aliases = strings.Split(aliases, ";")
+		}
+		a.FetchedAt = timeOrZero(fetchedAt)
+		advisories = append(advisories, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating advisories rows: %v", err)
+	}
+	rows.Close()
+
+	for i := range advisories {
+		affected, err := s.getAffected(advisories[i].AdvisoryKey.ID)
+		if err != nil {
+			return nil, err
+		}
+		advisories[i].Affected = affected
+	}
+	return advisories, nil
+}
+
+func (s *Store) getAffected(advisoryID string) ([]models.Affected, error) {
+	rows, err := s.db.Query(`
+		SELECT system, name, version, introduced, fixed, last_affected
+		FROM advisory_affected
+		WHERE advisory_id = $1`, advisoryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying advisory_affected: %v", err)
+	}
+	defer rows.Close()
+
+	var affected []models.Affected
+	for rows.Next() {
+		var af models.Affected
+		if err := rows.Scan(&af.System, &af.Name, &af.Version, &af.Introduced, &af.Fixed, &af.LastAffected); err != nil {
+			return nil, fmt.Errorf("error scanning advisory_affected row: %v", err)
+		}
+		affected = append(affected, af)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating advisory_affected rows: %v", err)
+	}
+	return affected, nil
+}
+
+func (s *Store) SetVersionAdvisories(key models.VersionKey, advisoryIDs []string, fetchedAt time.Time) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO package_version_advisory_checks (system, name, version, fetched_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT(system, name, version) DO UPDATE SET fetched_at=excluded.fetched_at`,
+		key.System, key.Name, key.Version, nowIfZero(fetchedAt))
+	if err != nil {
+		return fmt.Errorf("failed to record advisory check: %v", err)
+	}
+	_, err = tx.Exec(`DELETE FROM package_version_advisories WHERE system = $1 AND name = $2 AND version = $3`,
+		key.System, key.Name, key.Version)
+	if err != nil {
+		return fmt.Errorf("failed to replace version advisories: %v", err)
+	}
+	for _, id := range advisoryIDs {
+		_, err := tx.Exec(`
+			INSERT INTO package_version_advisories (system, name, version, advisory_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING`, key.System, key.Name, key.Version, id)
+		if err != nil {
+			return fmt.Errorf("failed to insert version advisory: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetVersionAdvisories(key models.VersionKey) ([]string, time.Time, error) {
+	var fetchedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT fetched_at FROM package_version_advisory_checks
+		WHERE system = $1 AND name = $2 AND version = $3`,
+		key.System, key.Name, key.Version).Scan(&fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	} else if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error querying package_version_advisory_checks: %v", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT advisory_id FROM package_version_advisories
+		WHERE system = $1 AND name = $2 AND version = $3
+		ORDER BY advisory_id`,
+		key.System, key.Name, key.Version)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error querying package_version_advisories: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, time.Time{}, fmt.Errorf("error scanning package_version_advisories row: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("error iterating package_version_advisories rows: %v", err)
+	}
+	return ids, timeOrZero(fetchedAt), nil
+}
@@ -0,0 +1,307 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ossfScore is the stored overall scorecard score of a project, 0 when unknown.
+func ossfScore(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, projectID string) (float64, error) {
+	var score sql.NullFloat64
+	err := q.QueryRow(`SELECT scorecard_overall_score FROM project WHERE id = $1`, projectID).Scan(&score)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("failed to retrieve OpenSSF score: %v", err)
+	}
+	return score.Float64, nil
+}
+
+func (s *Store) GetDependencyGraph(system, projectID, version string) (*models.DependencyGraph, error) {
+	graphID := store.GraphID(system, projectID, version)
+
+	nodeRows, err := s.db.Query(`
+		SELECT system, name, version, bundled, relation, errors
+		FROM dependency_nodes
+		WHERE project_id = $1 AND graph_id = $2
+		ORDER BY node_index`, projectID, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dependency_nodes: %v", err)
+	}
+	defer nodeRows.Close()
+
+	var nodes []models.Node
+	for nodeRows.Next() {
+		var node models.Node
+		var errorsStr sql.NullString
+		if err := nodeRows.Scan(&node.VersionKey.System, &node.VersionKey.Name, &node.VersionKey.Version,
+			&node.Bundled, &node.Relation, &errorsStr); err != nil {
+			return nil, fmt.Errorf("error scanning dependency_nodes row: %v", err)
+		}
+		if errorsStr.Valid && errorsStr.String != "" {
+			node.Errors = strings.Split(errorsStr.String, ";")
+		} else {
+			node.Errors = []string{}
+		}
+		nodes = append(nodes, node)
+	}
+	if err := nodeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependency_nodes rows: %v", err)
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
+	edgeRows, err := s.db.Query(`
+		SELECT from_node_index, to_node_index, requirement
+		FROM dependency_edges
+		WHERE project_id = $1 AND graph_id = $2
+		ORDER BY id`, projectID, graphID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying dependency_edges: %v", err)
+	}
+	defer edgeRows.Close()
+
+	var edges []models.Edge
+	for edgeRows.Next() {
+		var edge models.Edge
+		if err := edgeRows.Scan(&edge.FromNode, &edge.ToNode, &edge.Requirement); err != nil {
+			return nil, fmt.Errorf("error scanning dependency_edges row: %v", err)
+		}
+		edges = append(edges, edge)
+	}
+	if err := edgeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependency_edges rows: %v", err)
+	}
+
+	var fetchedAt sql.NullTime
+	err = s.db.QueryRow(`SELECT fetched_at FROM dependency_graphs WHERE graph_id = $1`, graphID).Scan(&fetchedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying dependency_graphs: %v", err)
+	}
+
+	return &models.DependencyGraph{
+		Nodes:     nodes,
+		Edges:     edges,
+		FetchedAt: timeOrZero(fetchedAt),
+	}, nil
+}
+
+// InsertDependencyGraph stores a graph and its nodes and edges in one transaction.
+func (s *Store) InsertDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error {
+	if graph == nil {
+		return fmt.Errorf("nil graph")
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := insertDependencyGraph(tx, system, projectID, version, source, graph); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceDependencyGraph deletes the stored graph of a version and stores graph in
+// its place, in one transaction: on failure the old graph is left as it was.
+func (s *Store) ReplaceDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error {
+	if graph == nil {
+		return fmt.Errorf("nil graph")
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := deleteDependencyGraph(tx, system, projectID, version); err != nil {
+		return err
+	}
+	if err := insertDependencyGraph(tx, system, projectID, version, source, graph); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertDependencyGraph(tx *sql.Tx, system, projectID, version, source string, graph *models.DependencyGraph) error {
+	graphID := store.GraphID(system, projectID, version)
+
+	_, err := tx.Exec(`
+		INSERT INTO dependency_graphs (graph_id, project_id, system, version, source, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT(graph_id) DO UPDATE SET
+			source=excluded.source,
+			fetched_at=excluded.fetched_at`,
+		graphID, projectID, system, version, source, nowIfZero(graph.FetchedAt))
+	if err != nil {
+		return fmt.Errorf("failed to record graph %s: %v", graphID, err)
+	}
+
+	for idx, node := range graph.Nodes {
+		score, err := ossfScore(tx, node.VersionKey.Name)
+		if err != nil {
+			score = -1
+		}
+		_, err = tx.Exec(`
+			INSERT INTO dependency_nodes (
+				project_id, graph_id, node_index, system, name, version, bundled, relation, errors, ossf_score
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			projectID,
+			graphID,
+			idx,
+			node.VersionKey.System,
+			node.VersionKey.Name,
+			node.VersionKey.Version,
+			node.Bundled,
+			node.Relation,
+			strings.Join(node.Errors, ";"),
+			score,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert node at index %d: %v", idx, err)
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		_, err := tx.Exec(`
+			INSERT INTO dependency_edges (project_id, graph_id, from_node_index, to_node_index, requirement)
+			VALUES ($1, $2, $3, $4, $5)`,
+			projectID, graphID, edge.FromNode, edge.ToNode, edge.Requirement)
+		if err != nil {
+			return fmt.Errorf("failed to insert edge from %d to %d: %v", edge.FromNode, edge.ToNode, err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) DeleteDependencyGraph(system, projectID, version string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := deleteDependencyGraph(tx, system, projectID, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteDependencyGraph(tx *sql.Tx, system, projectID, version string) error {
+	graphID := store.GraphID(system, projectID, version)
+	if _, err := tx.Exec(`DELETE FROM dependency_nodes WHERE project_id = $1 AND graph_id = $2`, projectID, graphID); err != nil {
+		return fmt.Errorf("failed to delete nodes of graph %s: %v", graphID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM dependency_edges WHERE project_id = $1 AND graph_id = $2`, projectID, graphID); err != nil {
+		return fmt.Errorf("failed to delete edges of graph %s: %v", graphID, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM dependency_graphs WHERE graph_id = $1`, graphID); err != nil {
+		return fmt.Errorf("failed to delete graph %s: %v", graphID, err)
+	}
+	return nil
+}
+
+func (s *Store) ListGraphVersions(system, projectID string) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT DISTINCT version
+		FROM dependency_nodes
+		WHERE project_id = $1 AND system = $2 AND relation = 'SELF'`, projectID, system)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stored graph versions: %v", err)
+	}
+	defer rows.Close()
+
+	versions := []string{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("error scanning stored graph version: %v", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stored graph versions: %v", err)
+	}
+	return versions, nil
+}
+
+// AddOrUpdateDependency keeps manually managed dependencies in the graph whose
+// graph_id is the project id, one node per dependency name.
+func (s *Store) AddOrUpdateDependency(projectID string, dep models.Node) error {
+	score, err := ossfScore(s.db, dep.VersionKey.Name)
+	if err != nil {
+		score = -1
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Serialises concurrent adds to the same project so node_index stays unique.
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, projectID); err != nil {
+		return fmt.Errorf("failed to lock project %s: %v", projectID, err)
+	}
+
+	result, err := tx.Exec(`
+		UPDATE dependency_nodes SET
+			system=$3, version=$4, bundled=$5, relation=$6, errors=$7, ossf_score=$8
+		WHERE project_id = $1 AND graph_id = $1 AND name = $2`,
+		projectID, dep.VersionKey.Name, dep.VersionKey.System, dep.VersionKey.Version,
+		dep.Bundled, dep.Relation, strings.Join(dep.Errors, ";"), score)
+	if err != nil {
+		return fmt.Errorf("failed to add or update dependency: %v", err)
+	}
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		_, err = tx.Exec(`
+			INSERT INTO dependency_nodes (
+				project_id, graph_id, node_index, system, name, version, bundled, relation, errors, ossf_score
+			)
+			SELECT $1, $1, COALESCE(MAX(node_index) + 1, 0), $2, $3, $4, $5::boolean, $6, $7, $8::double precision
+			FROM dependency_nodes WHERE project_id = $1 AND graph_id = $1`,
+			projectID, dep.VersionKey.System, dep.VersionKey.Name, dep.VersionKey.Version,
+			dep.Bundled, dep.Relation, strings.Join(dep.Errors, ";"), score)
+		if err != nil {
+			return fmt.Errorf("failed to add or update dependency: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *Store) GetDependency(projectID, depName string) (*models.Node, error) {
+	var dep models.Node
+	var errorsStr sql.NullString
+	err := s.db.QueryRow(`
+		SELECT system, name, version, bundled, relation, errors
+		FROM dependency_nodes
+		WHERE project_id = $1 AND name = $2
+		LIMIT 1`, projectID, depName).Scan(
+		&dep.VersionKey.System, &dep.VersionKey.Name, &dep.VersionKey.Version, &dep.Bundled, &dep.Relation, &errorsStr)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get dependency: %v", err)
+	}
+	dep.Errors = strings.Split(errorsStr.String, ";")
+	return &dep, nil
+}
+
+func (s *Store) DeleteDependency(projectID, depName string) error {
+	result, err := s.db.Exec(`DELETE FROM dependency_nodes WHERE project_id = $1 AND name = $2`, projectID, depName)
+	if err != nil {
+		return fmt.Errorf("failed to delete dependency: %v", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no dependency found to delete")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"fmt"
+	"time"
+)
+
+func (s *Store) ListStaleProjects(fetchedBefore time.Time, limit int) ([]string, error) {
+	rows, err := s.db.Query(`
+		SELECT id FROM project
+		WHERE fetched_at IS NULL OR fetched_at < $1
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at) NULLS FIRST
+		LIMIT $2`, fetchedBefore.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale projects: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan stale project: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *Store) ListStalePackages(fetchedBefore time.Time, limit int) ([]models.PackageKey, error) {
+	rows, err := s.db.Query(`
+		SELECT system, name FROM packages
+		WHERE fetched_at IS NULL OR fetched_at < $1
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at) NULLS FIRST
+		LIMIT $2`, fetchedBefore.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale packages: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []models.PackageKey
+	for rows.Next() {
+		var key models.PackageKey
+		if err := rows.Scan(&key.System, &key.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan stale package: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ListStaleGraphs only returns graphs fetched from deps.dev; uploaded graphs can't be re-fetched.
+func (s *Store) ListStaleGraphs(fetchedBefore time.Time, limit int) ([]store.StaleGraph, error) {
+	rows, err := s.db.Query(`
+		SELECT system, project_id, version FROM dependency_graphs
+		WHERE source = $1 AND (fetched_at IS NULL OR fetched_at < $2)
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at) NULLS FIRST
+		LIMIT $3`, store.SourceDepsDev, fetchedBefore.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale graphs: %v", err)
+	}
+	defer rows.Close()
+
+	var graphs []store.StaleGraph
+	for rows.Next() {
+		var g store.StaleGraph
+		if err := rows.Scan(&g.System, &g.ProjectID, &g.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan stale graph: %v", err)
+		}
+		graphs = append(graphs, g)
+	}
+	return graphs, rows.Err()
+}
+
+func (s *Store) MarkProjectAttempt(projectID string, at time.Time) error {
+	if _, err := s.db.Exec(`UPDATE project SET refresh_attempted_at = $1 WHERE id = $2`, at.UTC(), projectID); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of project %s: %v", projectID, err)
+	}
+	return nil
+}
+
+func (s *Store) MarkPackageAttempt(key models.PackageKey, at time.Time) error {
+	if _, err := s.db.Exec(`UPDATE packages SET refresh_attempted_at = $1 WHERE system = $2 AND name = $3`,
+		at.UTC(), key.System, key.Name); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of package %s/%s: %v", key.System, key.Name, err)
+	}
+	return nil
+}
+
+func (s *Store) MarkGraphAttempt(g store.StaleGraph, at time.Time) error {
+	graphID := store.GraphID(g.System, g.ProjectID, g.Version)
+	if _, err := s.db.Exec(`UPDATE dependency_graphs SET refresh_attempted_at = $1 WHERE graph_id = $2`, at.UTC(), graphID); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of graph %s: %v", graphID, err)
+	}
+	return nil
+}
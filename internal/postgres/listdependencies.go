@@ -0,0 +1,10 @@
+package postgres
+
+import (
+	"codenotary/internal/store"
+	"codenotary/internal/store/sqlquery"
+)
+
+func (s *Store) ListDependencies(q store.DependencyQuery) (*store.DependencyPage, error) {
+	return sqlquery.ListDependencies(s.db, sqlquery.Dollar, q)
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"codenotary/internal/store"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationLock is the advisory lock key that keeps replicas starting at the
+// same time from applying the same migration twice.
+const migrationLock = 0x636e6f74
+
+type migration struct {
+	store.Migration
+	SQL string
+}
+
+// migrations returns every known migration ordered by version.
+func migrations() ([]migration, error) {
+	files, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %v", err)
+	}
+
+	var all []migration
+	for _, file := range files {
+		version, name, ok := strings.Cut(strings.TrimSuffix(file.Name(), ".sql"), "_")
+		n, err := strconv.Atoi(version)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s is not named NNNN_name.sql", file.Name())
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %v", file.Name(), err)
+		}
+		all = append(all, migration{Migration: store.Migration{Version: n, Name: name}, SQL: string(body)})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	for i, m := range all {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migrations must be numbered 1..n without gaps, found %d at position %d", m.Version, i+1)
+		}
+	}
+	return all, nil
+}
+
+type queryRower interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func schemaVersion(ctx context.Context, db queryRower) (int, error) {
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT,
+			applied_at TIMESTAMPTZ
+		)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_version table: %v", err)
+	}
+	var version int
+	if err := db.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// SchemaVersion returns the highest applied migration, 0 for a fresh database.
+func (s *Store) SchemaVersion() (int, error) {
+	return schemaVersion(context.Background(), s.db)
+}
+
+// Migrate applies pending migrations in order, each in its own transaction, and
+// returns the ones it applied (or would apply, with DryRun). It holds an
+// advisory lock for the duration so concurrent replicas wait for each other.
+func (s *Store) Migrate(opts store.MigrateOptions) ([]store.Migration, error) {
+	ctx := context.Background()
+	all, err := migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLock); err != nil {
+		return nil, fmt.Errorf("failed to take migration lock: %v", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLock)
+
+	current, err := schemaVersion(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if current > len(all) {
+		return nil, fmt.Errorf("database schema version %d is newer than this binary (%d)", current, len(all))
+	}
+
+	var pending []migration
+	var infos []store.Migration
+	for _, m := range all {
+		if m.Version <= current || (opts.Target > 0 && m.Version > opts.Target) {
+			continue
+		}
+		pending = append(pending, m)
+		infos = append(infos, m.Migration)
+	}
+	if opts.DryRun {
+		return infos, nil
+	}
+
+	for i, m := range pending {
+		if err := apply(ctx, conn, m); err != nil {
+			return infos[:i], fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+		}
+	}
+	return infos, nil
+}
+
+func apply(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_version (version, name, applied_at) VALUES ($1, $2, now())`,
+		m.Version, m.Name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,96 @@
+-- The whole schema at the point PostgreSQL support was added; it matches
+-- internal/sqlite after all of its migrations.
+CREATE TABLE IF NOT EXISTS project (
+	id TEXT PRIMARY KEY,
+	open_issues_count INTEGER,
+	stars_count INTEGER,
+	forks_count INTEGER,
+	license TEXT,
+	description TEXT,
+	homepage TEXT,
+	scorecard_date TEXT,
+	scorecard_repo_name TEXT,
+	scorecard_repo_commit TEXT,
+	scorecard_version TEXT,
+	scorecard_commit TEXT,
+	scorecard_overall_score DOUBLE PRECISION,
+	fetched_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS scorecard_checks (
+	project_id TEXT REFERENCES project(id),
+	name TEXT,
+	short_description TEXT,
+	url TEXT,
+	score DOUBLE PRECISION,
+	reason TEXT,
+	details TEXT
+);
+
+CREATE TABLE IF NOT EXISTS packages (
+	system TEXT,
+	name TEXT,
+	fetched_at TIMESTAMPTZ,
+	PRIMARY KEY (system, name)
+);
+
+CREATE TABLE IF NOT EXISTS package_versions (
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	is_default INTEGER,
+	PRIMARY KEY (system, name, version),
+	FOREIGN KEY (system, name) REFERENCES packages(system, name)
+);
+
+CREATE TABLE IF NOT EXISTS dependency_nodes (
+	id SERIAL PRIMARY KEY,
+	project_id TEXT,
+	graph_id TEXT,       -- {system}/{name}@{version}
+	node_index INTEGER,  -- index of this node in the original response
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	bundled BOOLEAN,
+	relation TEXT,       -- SELF, DIRECT, INDIRECT
+	errors TEXT,         -- ';' separated
+	ossf_score DOUBLE PRECISION,
+	UNIQUE(project_id, graph_id, node_index)
+);
+
+CREATE TABLE IF NOT EXISTS dependency_edges (
+	id SERIAL PRIMARY KEY,
+	project_id TEXT,
+	graph_id TEXT,
+	from_node_index INTEGER,
+	to_node_index INTEGER,
+	requirement TEXT
+);
+
+CREATE TABLE IF NOT EXISTS dependency_graphs (
+	graph_id TEXT PRIMARY KEY,
+	project_id TEXT,
+	system TEXT,
+	version TEXT,
+	source TEXT,  -- deps.dev, go.mod
+	fetched_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS scorecard_snapshots (
+	id SERIAL PRIMARY KEY,
+	project_id TEXT REFERENCES project(id),
+	scorecard_date TEXT,
+	repo_commit TEXT,
+	scorecard_version TEXT,
+	scorecard_commit TEXT,
+	overall_score DOUBLE PRECISION,
+	fetched_at TIMESTAMPTZ,
+	UNIQUE(project_id, scorecard_date, repo_commit)
+);
+
+CREATE TABLE IF NOT EXISTS scorecard_snapshot_checks (
+	snapshot_id INTEGER REFERENCES scorecard_snapshots(id),
+	name TEXT,
+	score DOUBLE PRECISION,
+	reason TEXT
+);
@@ -0,0 +1,12 @@
+-- The source repository project deps.dev reports for a package version, which
+-- ListDependencies joins non-Go dependencies to their project through. Go
+-- module paths are their own project IDs and are not recorded.
+CREATE TABLE IF NOT EXISTS package_version_projects (
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	project_id TEXT,
+	PRIMARY KEY (system, name, version)
+);
+
+CREATE INDEX IF NOT EXISTS package_version_projects_project ON package_version_projects (project_id);
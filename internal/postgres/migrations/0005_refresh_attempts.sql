@@ -0,0 +1,5 @@
+-- When the refresher last tried to re-fetch an entry. The stale listings order
+-- by it, so entries that keep failing don't hold up the ones behind them.
+ALTER TABLE project ADD COLUMN IF NOT EXISTS refresh_attempted_at TIMESTAMPTZ;
+ALTER TABLE packages ADD COLUMN IF NOT EXISTS refresh_attempted_at TIMESTAMPTZ;
+ALTER TABLE dependency_graphs ADD COLUMN IF NOT EXISTS refresh_attempted_at TIMESTAMPTZ;
@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+)
+
+func (s *Store) GetPackageVersions(system, name string) (*models.PackageVersions, error) {
+	var fetchedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT fetched_at FROM packages
+		WHERE system = $1 AND name = $2`,
+		system, name).Scan(&fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying packages: %v", err)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT version, is_default FROM package_versions
+		WHERE system = $1 AND name = $2`,
+		system, name)
+	if err != nil {
+		return nil, fmt.Errorf("error querying package_versions: %v", err)
+	}
+	defer rows.Close()
+
+	var versions []models.Version
+	for rows.Next() {
+		var version string
+		var isDefault int
+		if err := rows.Scan(&version, &isDefault); err != nil {
+			return nil, fmt.Errorf("error scanning package_versions row: %v", err)
+		}
+		versions = append(versions, models.Version{
+			VersionKey: models.VersionKey{
+				System:  system,
+				Name:    name,
+				Version: version,
+			},
+			IsDefault: isDefault == 1,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating package_versions rows: %v", err)
+	}
+
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	return &models.PackageVersions{
+		PackageKey: models.PackageKey{
+			System: system,
+			Name:   name,
+		},
+		Versions:  versions,
+		FetchedAt: timeOrZero(fetchedAt),
+	}, nil
+}
+
+func (s *Store) StorePackageVersions(pv *models.PackageVersions) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO packages (system, name, fetched_at) VALUES ($1, $2, $3)
+		ON CONFLICT(system, name) DO UPDATE SET fetched_at=excluded.fetched_at`,
+		pv.PackageKey.System, pv.PackageKey.Name, nowIfZero(pv.FetchedAt))
+	if err != nil {
+		return fmt.Errorf("failed to insert package: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM package_versions WHERE system = $1 AND name = $2`,
+		pv.PackageKey.System, pv.PackageKey.Name)
+	if err != nil {
+		return fmt.Errorf("failed to replace versions: %v", err)
+	}
+
+	for _, v := range pv.Versions {
+		isDefault := 0
+		if v.IsDefault {
+			isDefault = 1
+		}
+		_, err = tx.Exec(`
+			INSERT INTO package_versions (system, name, version, is_default)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT(system, name, version) DO UPDATE SET is_default=excluded.is_default`,
+			v.VersionKey.System, v.VersionKey.Name, v.VersionKey.Version, isDefault)
+		if err != nil {
+			return fmt.Errorf("failed to insert version: %v", err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,324 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const upsertProjectSQL = `
+	INSERT INTO project (
+		id, open_issues_count, stars_count, forks_count, license, description, homepage,
+		scorecard_date, scorecard_repo_name, scorecard_repo_commit, scorecard_version,
+		scorecard_commit, scorecard_overall_score, fetched_at
+	) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14)
+	ON CONFLICT(id) DO UPDATE SET
+		open_issues_count=excluded.open_issues_count,
+		stars_count=excluded.stars_count,
+		forks_count=excluded.forks_count,
+		license=excluded.license,
+		description=excluded.description,
+		homepage=excluded.homepage,
+		scorecard_date=excluded.scorecard_date,
+		scorecard_repo_name=excluded.scorecard_repo_name,
+		scorecard_repo_commit=excluded.scorecard_repo_commit,
+		scorecard_version=excluded.scorecard_version,
+		scorecard_commit=excluded.scorecard_commit,
+		scorecard_overall_score=excluded.scorecard_overall_score,
+		fetched_at=excluded.fetched_at`
+
+func (s *Store) GetProject(projectID string) (*models.Project, error) {
+	var p models.Project
+	var fetchedAt sql.NullTime
+	err := s.db.QueryRow(`
+		SELECT
+			id, open_issues_count, stars_count, forks_count, license, description, homepage,
+			scorecard_date, scorecard_repo_name, scorecard_repo_commit, scorecard_version,
+			scorecard_commit, scorecard_overall_score, fetched_at
+		FROM project
+		WHERE id = $1`, projectID).Scan(
+		&p.ProjectKey.ID,
+		&p.OpenIssuesCount,
+		&p.StarsCount,
+		&p.ForksCount,
+		&p.License,
+		&p.Description,
+		&p.Homepage,
+		&p.Scorecard.Date,
+		&p.Scorecard.Repository.Name,
+		&p.Scorecard.Repository.Commit,
+		&p.Scorecard.Scorecard.Version,
+		&p.Scorecard.Scorecard.Commit,
+		&p.Scorecard.OverallScore,
+		&fetchedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error querying project: %v", err)
+	}
+	p.FetchedAt = timeOrZero(fetchedAt)
+
+	rows, err := s.db.Query(`
+		SELECT name, short_description, url, score, reason, details
+		FROM scorecard_checks
+		WHERE project_id = $1`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying scorecard_checks: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var check models.ScorecardCheck
+		var detailsStr string
+		if err := rows.Scan(
+			&check.Name,
+			&check.Documentation.ShortDescription,
+			&check.Documentation.URL,
+			&check.Score,
+			&check.Reason,
+			&detailsStr,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning scorecard_checks row: %v", err)
+		}
+		check.Details = strings.Split(detailsStr, "\n")
+		p.Scorecard.Checks = append(p.Scorecard.Checks, check)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scorecard_checks rows: %v", err)
+	}
+	return &p, nil
+}
+
+func (s *Store) InsertProject(p *models.Project) error {
+	if p == nil {
+		return fmt.Errorf("Nil project")
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	if err := insertProject(tx, p); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// InsertProjects stores every project in one transaction. Like the SQLite
+// store it keeps going past a failing project and reports all failures at the
+// end; each project runs under a savepoint so one failure doesn't abort the rest.
+func (s *Store) InsertProjects(projects []*models.Project) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var errs []error
+	for _, p := range projects {
+		if p == nil {
+			continue
+		}
+		if _, err := tx.Exec(`SAVEPOINT project`); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to create savepoint: %w", err)
+		}
+		if perr := insertProject(tx, p); perr != nil {
+			errs = append(errs, fmt.Errorf("failed to insert project (ID: %s): %w", p.ProjectKey.ID, perr))
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT project`); err != nil {
+				tx.Rollback()
+				return fmt.Errorf("failed to roll back to savepoint: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.Exec(`RELEASE SAVEPOINT project`); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to release savepoint: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if len(errs) > 0 {
+		var sb strings.Builder
+		sb.WriteString("Some inserts failed:\n")
+		for _, e := range errs {
+			sb.WriteString(e.Error())
+			sb.WriteString("\n")
+		}
+		return fmt.Errorf("%s", sb.String())
+	}
+	return nil
+}
+
+func insertProject(tx *sql.Tx, p *models.Project) error {
+	fetchedAt := nowIfZero(p.FetchedAt)
+	_, err := tx.Exec(upsertProjectSQL,
+		p.ProjectKey.ID,
+		p.OpenIssuesCount,
+		p.StarsCount,
+		p.ForksCount,
+		p.License,
+		p.Description,
+		p.Homepage,
+		p.Scorecard.Date,
+		p.Scorecard.Repository.Name,
+		p.Scorecard.Repository.Commit,
+		p.Scorecard.Scorecard.Version,
+		p.Scorecard.Scorecard.Commit,
+		p.Scorecard.OverallScore,
+		fetchedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert project: %v", err)
+	}
+
+	if err := insertSnapshot(tx, p, fetchedAt); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM scorecard_checks WHERE project_id = $1`, p.ProjectKey.ID); err != nil {
+		return fmt.Errorf("failed to replace scorecard checks: %v", err)
+	}
+	for _, check := range p.Scorecard.Checks {
+		_, err := tx.Exec(`
+			INSERT INTO scorecard_checks (
+				project_id, name, short_description, url, score, reason, details
+			) VALUES ($1,$2,$3,$4,$5,$6,$7)`,
+			p.ProjectKey.ID,
+			check.Name,
+			check.Documentation.ShortDescription,
+			check.Documentation.URL,
+			check.Score,
+			check.Reason,
+			strings.Join(check.Details, "\n"),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert scorecard check: %v", err)
+		}
+	}
+	return nil
+}
+
+// insertSnapshot keeps every distinct scorecard of a project, keyed by its date and
+// the repository commit it was computed on. Re-inserting a known scorecard is a no-op.
+func insertSnapshot(tx *sql.Tx, p *models.Project, fetchedAt time.Time) error {
+	if p.Scorecard.Date == "" {
+		return nil
+	}
+
+	var snapshotID int64
+	err := tx.QueryRow(`
+		INSERT INTO scorecard_snapshots (
+			project_id, scorecard_date, repo_commit, scorecard_version, scorecard_commit, overall_score, fetched_at
+		) VALUES ($1,$2,$3,$4,$5,$6,$7)
+		ON CONFLICT (project_id, scorecard_date, repo_commit) DO NOTHING
+		RETURNING id`,
+		p.ProjectKey.ID,
+		p.Scorecard.Date,
+		p.Scorecard.Repository.Commit,
+		p.Scorecard.Scorecard.Version,
+		p.Scorecard.Scorecard.Commit,
+		p.Scorecard.OverallScore,
+		fetchedAt,
+	).Scan(&snapshotID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to insert scorecard snapshot: %v", err)
+	}
+
+	for _, check := range p.Scorecard.Checks {
+		_, err := tx.Exec(`
+			INSERT INTO scorecard_snapshot_checks (snapshot_id, name, score, reason)
+			VALUES ($1,$2,$3,$4)`, snapshotID, check.Name, check.Score, check.Reason)
+		if err != nil {
+			return fmt.Errorf("failed to insert scorecard snapshot check: %v", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) GetScoresByProjectID(projectID string) (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT name, score FROM scorecard_checks WHERE project_id = $1`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %v", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var score float64
+		if err := rows.Scan(&name, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %v", err)
+		}
+		result[name] = int(score)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating through rows: %v", err)
+	}
+	return result, nil
+}
+
+// GetScorecardHistory returns every stored scorecard of a project, oldest first.
+func (s *Store) GetScorecardHistory(projectID string) ([]models.ScorecardSnapshot, error) {
+	rows, err := s.db.Query(`
+		SELECT id, scorecard_date, repo_commit, scorecard_version, overall_score, fetched_at
+		FROM scorecard_snapshots
+		WHERE project_id = $1
+		ORDER BY scorecard_date, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scorecard snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.ScorecardSnapshot
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var s models.ScorecardSnapshot
+		var fetchedAt sql.NullTime
+		if err := rows.Scan(&id, &s.Date, &s.RepositoryCommit, &s.ScorecardVersion, &s.OverallScore, &fetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scorecard snapshot: %v", err)
+		}
+		s.FetchedAt = timeOrZero(fetchedAt)
+		s.Checks = make(map[string]float64)
+		index[id] = len(snapshots)
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scorecard snapshots: %v", err)
+	}
+	rows.Close()
+
+	checkRows, err := s.db.Query(`
+		SELECT c.snapshot_id, c.name, c.score
+		FROM scorecard_snapshot_checks c
+		JOIN scorecard_snapshots s ON s.id = c.snapshot_id
+		WHERE s.project_id = $1`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scorecard snapshot checks: %v", err)
+	}
+	defer checkRows.Close()
+
+	for checkRows.Next() {
+		var id int64
+		var name string
+		var score float64
+		if err := checkRows.Scan(&id, &name, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan scorecard snapshot check: %v", err)
+		}
+		if i, ok := index[id]; ok {
+			snapshots[i].Checks[name] = score
+		}
+	}
+	if err := checkRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scorecard snapshot checks: %v", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import (
+	"codenotary/internal/models"
+	"fmt"
+)
+
+func (s *Store) SetVersionProject(key models.VersionKey, projectID string) error {
+	_, err := s.db.Exec(`
+		INSERT INTO package_version_projects (system, name, version, project_id) VALUES ($1, $2, $3, $4)
+		ON CONFLICT(system, name, version) DO UPDATE SET project_id=excluded.project_id`,
+		key.System, key.Name, key.Version, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to record the project of %s/%s@%s: %v", key.System, key.Name, key.Version, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package profile scores projects with weights of one's own over the OpenSSF
+// scorecard checks, for teams that care about some checks more than the overall
+// score does.
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profiles are the scoring profiles of a file, by name.
+type Profiles struct {
+	Profiles map[string]*Profile `yaml:"profiles" json:"profiles"`
+}
+
+type Profile struct {
+	Description string `yaml:"description" json:"description"`
+	// Weights maps scorecard check names (ScorecardCheck.Name, such as
+	// Code-Review) to their weight, compared case-insensitively.
+	Weights map[string]float64 `yaml:"weights" json:"weights"`
+	// DefaultWeight weighs the checks Weights doesn't list; 0 leaves them out.
+	DefaultWeight float64 `yaml:"default_weight" json:"default_weight"`
+}
+
+// Load reads a profiles file, JSON or YAML.
+func Load(path string) (*Profiles, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profiles: %v", err)
+	}
+	p, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return p, nil
+}
+
+// Parse decodes and validates profiles. Documents starting with "{" are read as
+// JSON, anything else as YAML; unknown fields are rejected in both.
+func Parse(data []byte) (*Profiles, error) {
+	var p Profiles
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid profiles JSON: %v", err)
+		}
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&p); err != nil {
+			return nil, fmt.Errorf("invalid profiles YAML: %v", err)
+		}
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Validate checks every profile.
+func (p *Profiles) Validate() error {
+	if len(p.Profiles) == 0 {
+		return fmt.Errorf("no profiles defined")
+	}
+	for _, name := range p.Names() {
+		if err := p.Profiles[name].validate(); err != nil {
+			return fmt.Errorf("profile %q: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (p *Profile) validate() error {
+	if p == nil {
+		return fmt.Errorf("empty profile")
+	}
+	if p.DefaultWeight < 0 {
+		return fmt.Errorf("default_weight must not be negative")
+	}
+	total := p.DefaultWeight
+	seen := make(map[string]string)
+	for check, weight := range p.Weights {
+		if weight < 0 {
+			return fmt.Errorf("weight of %s must not be negative", check)
+		}
+		if other, ok := seen[strings.ToLower(check)]; ok {
+			return fmt.Errorf("checks %s and %s are the same", other, check)
+		}
+		seen[strings.ToLower(check)] = check
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("every weight is 0")
+	}
+	return nil
+}
+
+// Names lists the profiles, sorted.
+func (p *Profiles) Names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named profile, nil if there is none.
+func (p *Profiles) Get(name string) *Profile {
+	if p == nil {
+		return nil
+	}
+	return p.Profiles[name]
+}
+
+// Weight returns the weight of a scorecard check.
+func (p *Profile) Weight(check string) float64 {
+	if weight, ok := p.Weights[check]; ok {
+		return weight
+	}
+	for name, weight := range p.Weights {
+		if strings.EqualFold(name, check) {
+			return weight
+		}
+	}
+	return p.DefaultWeight
+}
+
+// Score is the mean of a project's check scores, as returned by
+// Store.GetScoresByProjectID, by weight and rounded to two decimals. Checks
+// scorecard couldn't conclude on (-1) are left out; -1 means no weighed check has
+// a score.
+func (p *Profile) Score(checks map[string]int) float64 {
+	var sum, weights float64
+	for check, score := range checks {
+		weight := p.Weight(check)
+		if score < 0 || weight == 0 {
+			continue
+		}
+		sum += weight * float64(score)
+		weights += weight
+	}
+	if weights == 0 {
+		return -1
+	}
+	return math.Round(sum/weights*100) / 100
+}
@@ -0,0 +1,86 @@
+package profile_test
+
+import (
+	"codenotary/internal/profile"
+	"testing"
+)
+
+const testProfiles = `
+profiles:
+  security:
+    description: Reviews and known vulnerabilities first
+    weights:
+      Code-Review: 10
+      Vulnerabilities: 10
+      CII-Best-Practices: 1
+  everything:
+    default_weight: 1
+    weights:
+      fuzzing: 0
+`
+
+func TestParse(t *testing.T) {
+	p, err := profile.Parse([]byte(testProfiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := p.Names(); len(names) != 2 || names[0] != "everything" || names[1] != "security" {
+		t.Errorf("Names() = %v, want [everything security]", names)
+	}
+	if p.Get("security") == nil || p.Get("unknown") != nil {
+		t.Errorf("Get found the wrong profiles")
+	}
+
+	json := `{"profiles": {"reviews": {"weights": {"Code-Review": 1}}}}`
+	if p, err := profile.Parse([]byte(json)); err != nil || p.Get("reviews") == nil {
+		t.Errorf("Parse(JSON) = %+v, %v", p, err)
+	}
+
+	invalid := map[string]string{
+		"no profiles":     `profiles: {}`,
+		"unknown field":   `{"profiles": {"a": {"weights": {"Code-Review": 1}, "weight": 2}}}`,
+		"negative weight": `profiles: {a: {weights: {Code-Review: -1}}}`,
+		"negative other":  `profiles: {a: {default_weight: -1, weights: {Code-Review: 1}}}`,
+		"all zero":        `profiles: {a: {weights: {Code-Review: 0}}}`,
+		"empty profile":   `profiles: {a: }`,
+		"same check":      `profiles: {a: {weights: {Code-Review: 1, code-review: 2}}}`,
+	}
+	for name, doc := range invalid {
+		if _, err := profile.Parse([]byte(doc)); err == nil {
+			t.Errorf("%s: Parse succeeded, want an error", name)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	p, err := profile.Parse([]byte(testProfiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks := map[string]int{
+		"Code-Review":        2,
+		"Vulnerabilities":    10,
+		"CII-Best-Practices": 0,
+		"Fuzzing":            0,
+		"Maintained":         -1,
+		"License":            9,
+	}
+
+	tests := []struct {
+		profile string
+		checks  map[string]int
+		want    float64
+	}{
+		// (10*2 + 10*10 + 1*0) / 21
+		{"security", checks, 5.71},
+		// Fuzzing weighs 0 and Maintained is inconclusive: (2 + 10 + 0 + 9) / 4
+		{"everything", checks, 5.25},
+		{"security", map[string]int{"License": 10, "Code-Review": -1}, -1},
+		{"security", nil, -1},
+	}
+	for _, tt := range tests {
+		if got := p.Get(tt.profile).Score(tt.checks); got != tt.want {
+			t.Errorf("%s: Score(%v) = %v, want %v", tt.profile, tt.checks, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+// Package risk combines the OpenSSF scores of the nodes of a dependency graph into
+// one score for the whole tree, on the scorecard's 0 to 10 scale: the lower, the
+// riskier. Strategies differ in how they combine; all of them see every dependency
+// with the same weight and the same penalty for a missing score.
+package risk
+
+import (
+	"codenotary/internal/graph"
+	"codenotary/internal/models"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Names of the built-in strategies.
+const (
+	Min          = "min"
+	WeightedMean = "weighted-mean"
+	WorstPath    = "worst-path"
+)
+
+// DefaultStrategy is used when none is asked for.
+const DefaultStrategy = WeightedMean
+
+// Strategy combines the weighed dependencies of g into a score, and names the
+// nodes it mostly depends on. nodes holds every dependency, SELF excluded.
+type Strategy func(g *models.DependencyGraph, nodes []Node) (score float64, critical []int)
+
+// Strategies are the ways Aggregate can combine scores, by name. Add to it to
+// plug in another one.
+var Strategies = map[string]Strategy{
+	Min:          minScore,
+	WeightedMean: weightedMean,
+	WorstPath:    worstPath,
+}
+
+// Options say how much each dependency weighs.
+type Options struct {
+	// DirectWeight and IndirectWeight weigh nodes by relation.
+	DirectWeight   float64
+	IndirectWeight float64
+	// DepthDecay multiplies the weight once per level below the direct dependencies.
+	DepthDecay float64
+	// FanInWeight adds to the weight once per dependent beyond the first, so a
+	// package the tree leans on in many places counts more.
+	FanInWeight float64
+	// MissingScore stands in for the -1 of dependencies without a scorecard.
+	MissingScore float64
+}
+
+// DefaultOptions halve indirect dependencies and let weights fade by a fifth per
+// level, and count a dependency without a scorecard as 0.
+var DefaultOptions = Options{
+	DirectWeight:   1,
+	IndirectWeight: 0.5,
+	DepthDecay:     0.8,
+	FanInWeight:    0.1,
+	MissingScore:   0,
+}
+
+// Node is a dependency as the strategies see it.
+type Node struct {
+	Index  int
+	Score  float64 // the node's score, or Options.MissingScore
+	Known  bool    // whether the node has a score of its own
+	Depth  int     // -1 when SELF doesn't reach it
+	FanIn  int
+	Weight float64
+}
+
+// Result is the aggregate score of a graph, rounded to two decimals.
+type Result struct {
+	Strategy     string  `json:"strategy"`
+	Score        float64 `json:"score"` // -1 for a graph without dependencies
+	Dependencies int     `json:"dependencies"`
+	Unscored     int     `json:"unscored"`
+	// Critical are the indexes of the nodes the score comes from: the weakest node
+	// for min, the path from SELF for worst-path, none for weighted-mean.
+	Critical []int `json:"critical"`
+}
+
+// Aggregate combines scores, aligned with g.Nodes and -1 where unknown, with the
+// named strategy.
+func Aggregate(g *models.DependencyGraph, scores []float64, strategy string, opts Options) (Result, error) {
+	aggregate, ok := Strategies[strategy]
+	if !ok {
+		return Result{}, fmt.Errorf("unknown risk strategy %q, use one of %v", strategy, StrategyNames())
+	}
+	if err := opts.Validate(); err != nil {
+		return Result{}, err
+	}
+
+	nodes := Weigh(g, scores, opts)
+	result := Result{Strategy: strategy, Score: -1, Dependencies: len(nodes), Critical: []int{}}
+	for _, node := range nodes {
+		if !node.Known {
+			result.Unscored++
+		}
+	}
+	if len(nodes) == 0 {
+		return result, nil
+	}
+	score, critical := aggregate(g, nodes)
+	result.Score = math.Round(score*100) / 100
+	if critical != nil {
+		result.Critical = critical
+	}
+	return result, nil
+}
+
+// StrategyNames lists the registered strategies, sorted.
+func StrategyNames() []string {
+	names := make([]string, 0, len(Strategies))
+	for name := range Strategies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate reports weights out of range.
+func (o Options) Validate() error {
+	switch {
+	case o.DirectWeight < 0 || o.IndirectWeight < 0 || o.FanInWeight < 0:
+		return fmt.Errorf("risk weights must not be negative")
+	case o.DepthDecay <= 0 || o.DepthDecay > 1:
+		return fmt.Errorf("depth decay must be in (0, 1]")
+	case o.MissingScore < 0 || o.MissingScore > 10:
+		return fmt.Errorf("missing score must be between 0 and 10")
+	}
+	return nil
+}
+
+// Weigh returns every node but SELF with its weight. Nodes SELF doesn't reach
+// decay like the deepest level.
+func Weigh(g *models.DependencyGraph, scores []float64, opts Options) []Node {
+	depths := graph.Depths(g)
+	maxDepth := 1
+	for _, d := range depths {
+		if d > maxDepth {
+			maxDepth = d
+		}
+	}
+	parents := make([]map[int]bool, len(g.Nodes))
+	for i, children := range graph.Children(g) {
+		for _, edge := range children {
+			if parents[edge.ToNode] == nil {
+				parents[edge.ToNode] = make(map[int]bool)
+			}
+			parents[edge.ToNode][i] = true
+		}
+	}
+
+	var nodes []Node
+	for i, node := range g.Nodes {
+		if node.Relation == "SELF" {
+			continue
+		}
+		n := Node{Index: i, Score: scores[i], Known: scores[i] >= 0, Depth: depths[i], FanIn: len(parents[i])}
+		if !n.Known {
+			n.Score = opts.MissingScore
+		}
+
+		n.Weight = opts.IndirectWeight
+		if node.Relation == "DIRECT" {
+			n.Weight = opts.DirectWeight
+		}
+		depth := n.Depth
+		if depth < 0 {
+			depth = maxDepth + 1
+		}
+		if depth > 1 {
+			n.Weight *= math.Pow(opts.DepthDecay, float64(depth-1))
+		}
+		if n.FanIn > 1 {
+			n.Weight *= 1 + opts.FanInWeight*float64(n.FanIn-1)
+		}
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// minScore is the weakest link: the lowest score in the tree, whatever its weight.
+func minScore(g *models.DependencyGraph, nodes []Node) (float64, []int) {
+	weakest := nodes[0]
+	for _, n := range nodes[1:] {
+		if n.Score < weakest.Score {
+			weakest = n
+		}
+	}
+	return weakest.Score, []int{weakest.Index}
+}
+
+// weightedMean averages the scores by weight; a tree whose every weight is 0 gets
+// the plain mean.
+func weightedMean(g *models.DependencyGraph, nodes []Node) (float64, []int) {
+	var sum, weights, plain float64
+	for _, n := range nodes {
+		sum += n.Score * n.Weight
+		weights += n.Weight
+		plain += n.Score
+	}
+	if weights == 0 {
+		return plain / float64(len(nodes)), nil
+	}
+	return sum / weights, nil
+}
+
+// worstPath finds the chain of dependencies from SELF that is least trustworthy
+// as a whole. Each node on a chain is trusted as (score/10)^weight, a chain as the
+// product of its nodes, and the score is 10 times the lowest product. Chains only
+// follow edges one level deeper, which keeps cycles out; nodes SELF doesn't reach
+// are left out.
+func worstPath(g *models.DependencyGraph, nodes []Node) (float64, []int) {
+	root := graph.Root(g)
+	if root < 0 {
+		return weightedMean(g, nodes)
+	}
+	depths := graph.Depths(g)
+	trust := make(map[int]float64, len(nodes))
+	for _, n := range nodes {
+		trust[n.Index] = math.Pow(n.Score/10, n.Weight)
+	}
+
+	// Breadth-first order visits every parent on the shortest-path DAG before its
+	// children.
+	product := map[int]float64{root: 1}
+	previous := map[int]int{root: -1}
+	order := []int{root}
+	children := graph.Children(g)
+	for i := 0; i < len(order); i++ {
+		current := order[i]
+		for _, edge := range children[current] {
+			child := edge.ToNode
+			if depths[child] != depths[current]+1 {
+				continue
+			}
+			p := product[current] * trust[child]
+			if known, seen := product[child]; !seen {
+				order = append(order, child)
+				product[child], previous[child] = p, current
+			} else if p < known {
+				product[child], previous[child] = p, current
+			}
+		}
+	}
+
+	worst := -1
+	for _, index := range order[1:] {
+		if worst < 0 || product[index] < product[worst] {
+			worst = index
+		}
+	}
+	if worst < 0 {
+		return weightedMean(g, nodes)
+	}
+	var path []int
+	for at := worst; at >= 0; at = previous[at] {
+		path = append([]int{at}, path...)
+	}
+	return 10 * product[worst], path
+}
@@ -0,0 +1,88 @@
+package risk_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/risk"
+	"reflect"
+	"testing"
+)
+
+// testGraph is SELF -> a, b; a -> c; b -> c; c -> d, with d unscored.
+func testGraph() (*models.DependencyGraph, []float64) {
+	node := func(name, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "NPM", Name: name, Version: "1.0.0"}, Relation: relation}
+	}
+	g := &models.DependencyGraph{
+		Nodes: []models.Node{node("app", "SELF"), node("a", "DIRECT"), node("b", "DIRECT"), node("c", "INDIRECT"), node("d", "INDIRECT")},
+		Edges: []models.Edge{{FromNode: 0, ToNode: 1}, {FromNode: 0, ToNode: 2}, {FromNode: 1, ToNode: 3}, {FromNode: 2, ToNode: 3}, {FromNode: 3, ToNode: 4}},
+	}
+	return g, []float64{9, 8, 6, 2, -1}
+}
+
+func TestWeigh(t *testing.T) {
+	g, scores := testGraph()
+	nodes := risk.Weigh(g, scores, risk.DefaultOptions)
+	if len(nodes) != 4 {
+		t.Fatalf("got %d nodes, want the 4 dependencies", len(nodes))
+	}
+	c, d := nodes[2], nodes[3]
+	if c.FanIn != 2 || c.Depth != 2 || !near(c.Weight, 0.5*0.8*1.1) {
+		t.Errorf("c = %+v, want fan-in 2, depth 2, weight 0.44", c)
+	}
+	if d.Known || d.Score != 0 || !near(d.Weight, 0.5*0.8*0.8) {
+		t.Errorf("d = %+v, want unknown with score 0 and weight 0.32", d)
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	g, scores := testGraph()
+	withMissing := risk.DefaultOptions
+	withMissing.MissingScore = 5
+
+	for _, tt := range []struct {
+		strategy string
+		opts     risk.Options
+		score    float64
+		critical []int
+	}{
+		{risk.Min, risk.DefaultOptions, 0, []int{4}},
+		{risk.Min, withMissing, 2, []int{3}},
+		{risk.WeightedMean, risk.DefaultOptions, 5.39, []int{}},
+		{risk.WorstPath, risk.DefaultOptions, 0, []int{0, 2, 3, 4}},
+		{risk.WorstPath, withMissing, 2.37, []int{0, 2, 3, 4}},
+	} {
+		result, err := risk.Aggregate(g, scores, tt.strategy, tt.opts)
+		if err != nil {
+			t.Fatalf("Aggregate(%s): %v", tt.strategy, err)
+		}
+		if result.Score != tt.score || !reflect.DeepEqual(result.Critical, tt.critical) {
+			t.Errorf("%s with missing score %v = %v %v, want %v %v",
+				tt.strategy, tt.opts.MissingScore, result.Score, result.Critical, tt.score, tt.critical)
+		}
+		if result.Dependencies != 4 || result.Unscored != 1 {
+			t.Errorf("%s counted %d dependencies, %d unscored; want 4, 1", tt.strategy, result.Dependencies, result.Unscored)
+		}
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	g, scores := testGraph()
+	if _, err := risk.Aggregate(g, scores, "median", risk.DefaultOptions); err == nil {
+		t.Error("accepted an unknown strategy")
+	}
+	opts := risk.DefaultOptions
+	opts.DepthDecay = 0
+	if _, err := risk.Aggregate(g, scores, risk.Min, opts); err == nil {
+		t.Error("accepted a depth decay of 0")
+	}
+
+	self := &models.DependencyGraph{Nodes: g.Nodes[:1]}
+	result, err := risk.Aggregate(self, scores[:1], risk.WorstPath, risk.DefaultOptions)
+	if err != nil || result.Score != -1 {
+		t.Errorf("graph without dependencies = %+v, %v; want score -1", result, err)
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
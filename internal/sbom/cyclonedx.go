@@ -0,0 +1,325 @@
+package sbom
+
+import (
+	"codenotary/internal/models"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	CycloneDXVersion     = "1.5"
+	CycloneDXXMLNS       = "http://cyclonedx.org/schema/bom/1.5"
+	CycloneDXJSONContent = "application/vnd.cyclonedx+json; version=1.5"
+	CycloneDXXMLContent  = "application/vnd.cyclonedx+xml; version=1.5"
+)
+
+// Property names used for the OpenSSF scorecard of a component.
+const (
+	PropertyScore         = "codenotary:scorecard:score"
+	PropertyScorecardDate = "codenotary:scorecard:date"
+	PropertyCheckPrefix   = "codenotary:scorecard:check:"
+	PropertyRelation      = "codenotary:relation"
+)
+
+type CycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     CycloneDXMetadata     `json:"metadata"`
+	Components   []CycloneDXComponent  `json:"components"`
+	Dependencies []CycloneDXDependency `json:"dependencies"`
+}
+
+type CycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     CycloneDXTools     `json:"tools"`
+	Component CycloneDXComponent `json:"component"`
+}
+
+type CycloneDXTools struct {
+	Components []CycloneDXComponent `json:"components"`
+}
+
+type CycloneDXComponent struct {
+	Type       string              `json:"type"`
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Licenses   []CycloneDXLicense  `json:"licenses,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Properties []CycloneDXProperty `json:"properties,omitempty"`
+}
+
+// CycloneDXLicense holds either a license or an SPDX expression.
+type CycloneDXLicense struct {
+	License    *CycloneDXLicenseID `json:"license,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+}
+
+type CycloneDXLicenseID struct {
+	ID   string `json:"id,omitempty" xml:"id,omitempty"`
+	Name string `json:"name,omitempty" xml:"name,omitempty"`
+}
+
+type CycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// CycloneDX builds a CycloneDX 1.5 BOM. The SELF node becomes the metadata
+// component, every other node a library component referenced by its purl.
+func CycloneDX(in Input) *CycloneDXBOM {
+	timestamp := in.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	bom := &CycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  CycloneDXVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: CycloneDXMetadata{
+			Timestamp: timestamp.UTC().Format(time.RFC3339),
+			Tools:     CycloneDXTools{Components: []CycloneDXComponent{{Type: "application", Name: ToolName}}},
+		},
+		Components:   []CycloneDXComponent{},
+		Dependencies: []CycloneDXDependency{},
+	}
+
+	refs := make([]string, len(in.Graph.Nodes))
+	seen := make(map[string]bool)
+	rootFound := false
+	for i, node := range in.Graph.Nodes {
+		refs[i] = bomRef(node.VersionKey)
+		component := cycloneDXComponent(node, in.project(i))
+		if node.Relation == "SELF" && !rootFound {
+			rootFound = true
+			component.Type = "application"
+			bom.Metadata.Component = component
+			seen[refs[i]] = true
+			continue
+		}
+		if seen[refs[i]] {
+			continue
+		}
+		seen[refs[i]] = true
+		bom.Components = append(bom.Components, component)
+	}
+	if !rootFound {
+		key := models.VersionKey{System: in.System, Name: in.Name, Version: in.Version}
+		bom.Metadata.Component = CycloneDXComponent{Type: "application", BOMRef: bomRef(key), Name: in.Name, Version: in.Version, PURL: PURL(key)}
+	}
+
+	children := in.dependsOn()
+	listed := make(map[string]int)
+	for i := range in.Graph.Nodes {
+		dependsOn := []string{}
+		for _, child := range children[i] {
+			dependsOn = append(dependsOn, refs[child])
+		}
+		if at, ok := listed[refs[i]]; ok {
+			// Duplicate nodes share one component, so merge their dependencies.
+			bom.Dependencies[at].DependsOn = appendMissing(bom.Dependencies[at].DependsOn, dependsOn...)
+			continue
+		}
+		listed[refs[i]] = len(bom.Dependencies)
+		bom.Dependencies = append(bom.Dependencies, CycloneDXDependency{Ref: refs[i], DependsOn: dependsOn})
+	}
+	return bom
+}
+
+func bomRef(key models.VersionKey) string {
+	if purl := PURL(key); purl != "" {
+		return purl
+	}
+	return key.System + "/" + key.Name + "@" + key.Version
+}
+
+func cycloneDXComponent(node models.Node, project *models.Project) CycloneDXComponent {
+	component := CycloneDXComponent{
+		Type:    "library",
+		BOMRef:  bomRef(node.VersionKey),
+		Name:    node.VersionKey.Name,
+		Version: node.VersionKey.Version,
+		PURL:    PURL(node.VersionKey),
+	}
+	if node.Relation != "" {
+		component.Properties = append(component.Properties, CycloneDXProperty{Name: PropertyRelation, Value: node.Relation})
+	}
+	if project == nil {
+		return component
+	}
+
+	if license := cycloneDXLicense(project.License); license != nil {
+		component.Licenses = []CycloneDXLicense{*license}
+	}
+	if project.Scorecard.Date != "" {
+		component.Properties = append(component.Properties,
+			CycloneDXProperty{Name: PropertyScore, Value: formatFloat(project.Scorecard.OverallScore)},
+			CycloneDXProperty{Name: PropertyScorecardDate, Value: project.Scorecard.Date},
+		)
+		for _, check := range project.Scorecard.Checks {
+			component.Properties = append(component.Properties,
+				CycloneDXProperty{Name: PropertyCheckPrefix + check.Name, Value: formatFloat(check.Score)})
+		}
+	}
+	return component
+}
+
+// cycloneDXLicense maps deps.dev's license field, usually an SPDX expression,
+// to a CycloneDX license. Compound expressions become expressions, "non-standard"
+// and other unrecognised values are kept as license names.
+func cycloneDXLicense(license string) *CycloneDXLicense {
+	license = strings.TrimSpace(license)
+	switch {
+	case license == "":
+		return nil
+	case strings.Contains(license, " "):
+		return &CycloneDXLicense{Expression: license}
+	case isSPDXID(license):
+		return &CycloneDXLicense{License: &CycloneDXLicenseID{ID: license}}
+	default:
+		return &CycloneDXLicense{License: &CycloneDXLicenseID{Name: license}}
+	}
+}
+
+// isSPDXID reports whether s looks like an SPDX license identifier.
+func isSPDXID(s string) bool {
+	if s == "non-standard" || strings.HasPrefix(s, "LicenseRef-") {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '.' || r == '+') {
+			return false
+		}
+	}
+	return true
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func appendMissing(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func (bom *CycloneDXBOM) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bom)
+}
+
+// The XML schema nests and attributes things differently from JSON, so the
+// document is converted to these types before encoding.
+type cycloneDXXML struct {
+	XMLName      xml.Name                 `xml:"bom"`
+	XMLNS        string                   `xml:"xmlns,attr"`
+	SerialNumber string                   `xml:"serialNumber,attr"`
+	Version      int                      `xml:"version,attr"`
+	Timestamp    string                   `xml:"metadata>timestamp"`
+	Tools        []cycloneDXComponentXML  `xml:"metadata>tools>components>component"`
+	Component    cycloneDXComponentXML    `xml:"metadata>component"`
+	Components   []cycloneDXComponentXML  `xml:"components>component"`
+	Dependencies []cycloneDXDependencyXML `xml:"dependencies>dependency"`
+}
+
+type cycloneDXComponentXML struct {
+	Type       string                  `xml:"type,attr"`
+	BOMRef     string                  `xml:"bom-ref,attr,omitempty"`
+	Name       string                  `xml:"name"`
+	Version    string                  `xml:"version,omitempty"`
+	Licenses   *cycloneDXLicensesXML   `xml:"licenses,omitempty"`
+	PURL       string                  `xml:"purl,omitempty"`
+	Properties *cycloneDXPropertiesXML `xml:"properties,omitempty"`
+}
+
+type cycloneDXPropertiesXML struct {
+	Property []cycloneDXPropertyXML `xml:"property"`
+}
+
+type cycloneDXLicensesXML struct {
+	License    *CycloneDXLicenseID `xml:"license,omitempty"`
+	Expression string              `xml:"expression,omitempty"`
+}
+
+type cycloneDXPropertyXML struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type cycloneDXDependencyXML struct {
+	Ref       string                   `xml:"ref,attr"`
+	DependsOn []cycloneDXDependencyXML `xml:"dependency,omitempty"`
+}
+
+func toCycloneDXComponentXML(c CycloneDXComponent) cycloneDXComponentXML {
+	out := cycloneDXComponentXML{Type: c.Type, BOMRef: c.BOMRef, Name: c.Name, Version: c.Version, PURL: c.PURL}
+	if len(c.Licenses) > 0 {
+		out.Licenses = &cycloneDXLicensesXML{License: c.Licenses[0].License, Expression: c.Licenses[0].Expression}
+	}
+	if len(c.Properties) > 0 {
+		out.Properties = &cycloneDXPropertiesXML{}
+		for _, p := range c.Properties {
+			out.Properties.Property = append(out.Properties.Property, cycloneDXPropertyXML{Name: p.Name, Value: p.Value})
+		}
+	}
+	return out
+}
+
+func (bom *CycloneDXBOM) WriteXML(w io.Writer) error {
+	doc := cycloneDXXML{
+		XMLNS:        CycloneDXXMLNS,
+		SerialNumber: bom.SerialNumber,
+		Version:      bom.Version,
+		Timestamp:    bom.Metadata.Timestamp,
+		Component:    toCycloneDXComponentXML(bom.Metadata.Component),
+	}
+	for _, tool := range bom.Metadata.Tools.Components {
+		doc.Tools = append(doc.Tools, toCycloneDXComponentXML(tool))
+	}
+	for _, c := range bom.Components {
+		doc.Components = append(doc.Components, toCycloneDXComponentXML(c))
+	}
+	for _, d := range bom.Dependencies {
+		dependency := cycloneDXDependencyXML{Ref: d.Ref}
+		for _, ref := range d.DependsOn {
+			dependency.DependsOn = append(dependency.DependsOn, cycloneDXDependencyXML{Ref: ref})
+		}
+		doc.Dependencies = append(doc.Dependencies, dependency)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode CycloneDX XML: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,385 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"codenotary/internal/models"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Formats recognised by Parse.
+const (
+	FormatCycloneDXJSON = "cyclonedx-json"
+	FormatCycloneDXXML  = "cyclonedx-xml"
+	FormatSPDXJSON      = "spdx-json"
+	FormatSPDXTagValue  = "spdx-tag-value"
+)
+
+// Imported is a dependency graph read from an SBOM.
+type Imported struct {
+	Format string
+	// Root is the package the SBOM describes. Its System is empty when the
+	// SBOM gives it no purl and its components span several ecosystems.
+	Root  models.VersionKey
+	Graph *models.DependencyGraph
+	// Skipped lists components without a purl deps.dev knows, by name or purl.
+	Skipped []string
+}
+
+// element is a package or component before it is mapped to a node.
+type element struct {
+	name, version, purl string
+}
+
+// document is the format-independent shape of an SBOM.
+type document struct {
+	root      string // id of the described element, "" if unknown
+	elements  map[string]element
+	order     []string
+	dependsOn map[string][]string
+}
+
+func newDocument() *document {
+	return &document{elements: make(map[string]element), dependsOn: make(map[string][]string)}
+}
+
+func (d *document) add(id string, e element) {
+	if _, ok := d.elements[id]; !ok {
+		d.order = append(d.order, id)
+	}
+	d.elements[id] = e
+}
+
+// Parse detects the format of an SBOM (CycloneDX JSON or XML, SPDX JSON or
+// tag-value) and turns it into a dependency graph. The described package is the
+// SELF node, what it depends on directly is DIRECT and everything else INDIRECT.
+// An SBOM without dependency information is read as a flat list of direct
+// dependencies.
+func Parse(data []byte) (*Imported, error) {
+	trimmed := bytes.TrimSpace(data)
+	var format string
+	var doc *document
+	var err error
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var probe struct {
+			BOMFormat   string `json:"bomFormat"`
+			SPDXVersion string `json:"spdxVersion"`
+		}
+		if err := json.Unmarshal(trimmed, &probe); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %v", err)
+		}
+		switch {
+		case probe.BOMFormat == "CycloneDX":
+			format = FormatCycloneDXJSON
+			doc, err = parseCycloneDXJSON(trimmed)
+		case probe.SPDXVersion != "":
+			format = FormatSPDXJSON
+			doc, err = parseSPDXJSON(trimmed)
+		default:
+			return nil, fmt.Errorf("JSON document is neither CycloneDX nor SPDX")
+		}
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		format = FormatCycloneDXXML
+		doc, err = parseCycloneDXXML(trimmed)
+	case bytes.Contains(trimmed, []byte("SPDXVersion:")):
+		format = FormatSPDXTagValue
+		doc, err = parseSPDXTagValue(trimmed)
+	default:
+		return nil, fmt.Errorf("unrecognised SBOM format, expected CycloneDX (JSON or XML) or SPDX (JSON or tag-value)")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	imported := doc.toGraph()
+	imported.Format = format
+	return imported, nil
+}
+
+func (d *document) toGraph() *Imported {
+	imported := &Imported{Graph: &models.DependencyGraph{Nodes: []models.Node{}, Edges: []models.Edge{}}}
+
+	root, hasRoot := d.elements[d.root]
+	if hasRoot {
+		if key, ok := ParsePURL(root.purl); ok {
+			imported.Root = key
+		} else {
+			imported.Root = models.VersionKey{Name: root.name, Version: root.version}
+		}
+	}
+	if imported.Root.Version == "" {
+		imported.Root.Version = "local"
+	}
+
+	// Direct dependencies: those of the root, or every element for flat SBOMs.
+	direct := make(map[string]bool)
+	if len(d.dependsOn[d.root]) > 0 {
+		for _, id := range d.dependsOn[d.root] {
+			direct[id] = true
+		}
+	} else {
+		for _, id := range d.order {
+			direct[id] = id != d.root
+		}
+	}
+
+	imported.Graph.Nodes = append(imported.Graph.Nodes, models.Node{VersionKey: imported.Root, Relation: "SELF", Errors: []string{}})
+	index := map[string]int{d.root: 0}
+	systems := make(map[string]bool)
+	for _, id := range d.order {
+		if id == d.root {
+			continue
+		}
+		e := d.elements[id]
+		key, ok := ParsePURL(e.purl)
+		if !ok {
+			name := e.purl
+			if name == "" {
+				name = e.name
+			}
+			imported.Skipped = append(imported.Skipped, name)
+			continue
+		}
+		if key.Version == "" {
+			key.Version = e.version
+		}
+		relation := "INDIRECT"
+		if direct[id] {
+			relation = "DIRECT"
+		}
+		systems[key.System] = true
+		index[id] = len(imported.Graph.Nodes)
+		imported.Graph.Nodes = append(imported.Graph.Nodes, models.Node{VersionKey: key, Relation: relation, Errors: []string{}})
+	}
+
+	if imported.Root.System == "" && len(systems) == 1 {
+		for system := range systems {
+			imported.Root.System = system
+		}
+		imported.Graph.Nodes[0].VersionKey.System = imported.Root.System
+	}
+
+	addEdge := func(from, to string) {
+		fromIndex, ok := index[from]
+		toIndex, ok2 := index[to]
+		if ok && ok2 && fromIndex != toIndex {
+			imported.Graph.Edges = append(imported.Graph.Edges, models.Edge{
+				FromNode:    fromIndex,
+				ToNode:      toIndex,
+				Requirement: imported.Graph.Nodes[toIndex].VersionKey.Version,
+			})
+		}
+	}
+	if len(d.dependsOn[d.root]) == 0 {
+		for _, id := range d.order {
+			addEdge(d.root, id)
+		}
+	}
+	for _, from := range append([]string{d.root}, d.order...) {
+		for _, to := range d.dependsOn[from] {
+			addEdge(from, to)
+		}
+		delete(d.dependsOn, from)
+	}
+	return imported
+}
+
+type cycloneDXImportComponent struct {
+	BOMRef     string                     `json:"bom-ref" xml:"bom-ref,attr"`
+	Name       string                     `json:"name" xml:"name"`
+	Version    string                     `json:"version" xml:"version"`
+	PURL       string                     `json:"purl" xml:"purl"`
+	Components []cycloneDXImportComponent `json:"components" xml:"components>component"`
+}
+
+func (c cycloneDXImportComponent) id() string {
+	if c.BOMRef != "" {
+		return c.BOMRef
+	}
+	if c.PURL != "" {
+		return c.PURL
+	}
+	return c.Name + "@" + c.Version
+}
+
+func (d *document) addCycloneDX(components []cycloneDXImportComponent) {
+	for _, c := range components {
+		d.add(c.id(), element{name: c.Name, version: c.Version, purl: c.PURL})
+		d.addCycloneDX(c.Components)
+	}
+}
+
+func parseCycloneDXJSON(data []byte) (*document, error) {
+	var bom struct {
+		Metadata struct {
+			Component *cycloneDXImportComponent `json:"component"`
+		} `json:"metadata"`
+		Components   []cycloneDXImportComponent `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX JSON: %v", err)
+	}
+
+	doc := newDocument()
+	if root := bom.Metadata.Component; root != nil {
+		doc.root = root.id()
+		doc.add(doc.root, element{name: root.Name, version: root.Version, purl: root.PURL})
+	}
+	doc.addCycloneDX(bom.Components)
+	for _, dep := range bom.Dependencies {
+		doc.dependsOn[dep.Ref] = append(doc.dependsOn[dep.Ref], dep.DependsOn...)
+	}
+	return doc, nil
+}
+
+type cycloneDXImportDependency struct {
+	Ref       string                      `xml:"ref,attr"`
+	DependsOn []cycloneDXImportDependency `xml:"dependency"`
+}
+
+func parseCycloneDXXML(data []byte) (*document, error) {
+	var bom struct {
+		XMLName      xml.Name                    `xml:"bom"`
+		Component    *cycloneDXImportComponent   `xml:"metadata>component"`
+		Components   []cycloneDXImportComponent  `xml:"components>component"`
+		Dependencies []cycloneDXImportDependency `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal(data, &bom); err != nil {
+		return nil, fmt.Errorf("invalid CycloneDX XML: %v", err)
+	}
+
+	doc := newDocument()
+	if root := bom.Component; root != nil {
+		doc.root = root.id()
+		doc.add(doc.root, element{name: root.Name, version: root.Version, purl: root.PURL})
+	}
+	doc.addCycloneDX(bom.Components)
+	for _, dep := range bom.Dependencies {
+		for _, child := range dep.DependsOn {
+			doc.dependsOn[dep.Ref] = append(doc.dependsOn[dep.Ref], child.Ref)
+		}
+	}
+	return doc, nil
+}
+
+func parseSPDXJSON(data []byte) (*document, error) {
+	var spdx struct {
+		DocumentDescribes []string           `json:"documentDescribes"`
+		Packages          []SPDXPackage      `json:"packages"`
+		Relationships     []SPDXRelationship `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, fmt.Errorf("invalid SPDX JSON: %v", err)
+	}
+
+	doc := newDocument()
+	for _, pkg := range spdx.Packages {
+		e := element{name: pkg.Name, version: pkg.VersionInfo}
+		for _, ref := range pkg.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				e.purl = ref.ReferenceLocator
+			}
+		}
+		doc.add(pkg.SPDXID, e)
+	}
+	if len(spdx.DocumentDescribes) > 0 {
+		doc.root = spdx.DocumentDescribes[0]
+	}
+	for _, r := range spdx.Relationships {
+		doc.addSPDXRelationship(r)
+	}
+	return doc, nil
+}
+
+func (d *document) addSPDXRelationship(r SPDXRelationship) {
+	switch r.RelationshipType {
+	case "DESCRIBES":
+		if r.SPDXElementID == spdxDocumentID && d.root == "" {
+			d.root = r.RelatedSPDXElement
+		}
+	case "DESCRIBED_BY":
+		if r.RelatedSPDXElement == spdxDocumentID && d.root == "" {
+			d.root = r.SPDXElementID
+		}
+	case "DEPENDS_ON", "CONTAINS":
+		d.dependsOn[r.SPDXElementID] = append(d.dependsOn[r.SPDXElementID], r.RelatedSPDXElement)
+	case "DEPENDENCY_OF", "CONTAINED_BY":
+		d.dependsOn[r.RelatedSPDXElement] = append(d.dependsOn[r.RelatedSPDXElement], r.SPDXElementID)
+	}
+}
+
+func parseSPDXTagValue(data []byte) (*document, error) {
+	doc := newDocument()
+	var current *SPDXPackage
+	flush := func() {
+		if current == nil {
+			return
+		}
+		e := element{name: current.Name, version: current.VersionInfo}
+		for _, ref := range current.ExternalRefs {
+			e.purl = ref.ReferenceLocator
+		}
+		doc.add(current.SPDXID, e)
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 10<<20)
+	inText := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Multi-line <text> values carry nothing we need.
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+		tag, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "<text>") && !strings.Contains(value, "</text>") {
+			inText = true
+			continue
+		}
+
+		switch strings.TrimSpace(tag) {
+		case "PackageName":
+			flush()
+			current = &SPDXPackage{Name: value}
+		case "SPDXID":
+			if current != nil {
+				current.SPDXID = value
+			}
+		case "PackageVersion":
+			if current != nil {
+				current.VersionInfo = value
+			}
+		case "ExternalRef":
+			fields := strings.Fields(value)
+			if current != nil && len(fields) == 3 && fields[1] == "purl" {
+				current.ExternalRefs = append(current.ExternalRefs, SPDXExternalRef{fields[0], fields[1], fields[2]})
+			}
+		case "FileName", "SnippetSPDXID", "LicenseID":
+			flush()
+		case "Relationship":
+			fields := strings.Fields(value)
+			if len(fields) == 3 {
+				doc.addSPDXRelationship(SPDXRelationship{fields[0], fields[1], fields[2]})
+			}
+		}
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid SPDX tag-value: %v", err)
+	}
+	return doc, nil
+}
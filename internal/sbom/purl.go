@@ -0,0 +1,105 @@
+package sbom
+
+import (
+	"codenotary/internal/models"
+	"net/url"
+	"strings"
+)
+
+// purlTypes maps deps.dev systems to package URL types.
+var purlTypes = map[string]string{
+	"GO":       "golang",
+	"NPM":      "npm",
+	"PYPI":     "pypi",
+	"MAVEN":    "maven",
+	"CARGO":    "cargo",
+	"NUGET":    "nuget",
+	"RUBYGEMS": "gem",
+}
+
+// PURL returns the package URL of a package version, or "" for systems without
+// a purl type.
+func PURL(key models.VersionKey) string {
+	purlType, ok := purlTypes[strings.ToUpper(key.System)]
+	if !ok {
+		return ""
+	}
+
+	name := key.Name
+	switch purlType {
+	case "maven":
+		// deps.dev names Maven packages "group:artifact".
+		name = strings.Replace(name, ":", "/", 1)
+	case "pypi":
+		name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	}
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSegment(segment)
+	}
+	purl := "pkg:" + purlType + "/" + strings.Join(segments, "/")
+	if key.Version != "" {
+		purl += "@" + escapeSegment(key.Version)
+	}
+	return purl
+}
+
+func escapeSegment(s string) string {
+	return strings.ReplaceAll(url.PathEscape(s), "@", "%40")
+}
+
+// ParsePURL maps a package URL to a deps.dev package version. Qualifiers and
+// subpaths are ignored; ok is false for malformed purls and for package types
+// deps.dev doesn't index.
+func ParsePURL(purl string) (key models.VersionKey, ok bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return key, false
+	}
+	rest = strings.TrimLeft(rest, "/")
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+	purlType, path, found := strings.Cut(rest, "/")
+	if !found || path == "" {
+		return key, false
+	}
+
+	for system, t := range purlTypes {
+		if strings.EqualFold(t, purlType) {
+			key.System = system
+		}
+	}
+	if key.System == "" {
+		return key, false
+	}
+
+	if at := strings.LastIndex(path, "@"); at > strings.LastIndex(path, "/") {
+		version, err := url.PathUnescape(path[at+1:])
+		if err != nil {
+			return key, false
+		}
+		key.Version = version
+		path = path[:at]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil || unescaped == "" {
+			return key, false
+		}
+		segments[i] = unescaped
+	}
+
+	switch key.System {
+	case "MAVEN":
+		if len(segments) != 2 {
+			return key, false
+		}
+		key.Name = segments[0] + ":" + segments[1]
+	default:
+		key.Name = strings.Join(segments, "/")
+	}
+	return key, true
+}
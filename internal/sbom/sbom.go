@@ -0,0 +1,57 @@
+// Package sbom converts stored dependency graphs to and from software bills of
+// materials (CycloneDX and SPDX).
+package sbom
+
+import (
+	"codenotary/internal/models"
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+// ToolName identifies this service as the SBOM author.
+const ToolName = "codenotary"
+
+// Input is everything an exported SBOM is built from.
+type Input struct {
+	System  string
+	Name    string
+	Version string
+	Graph   *models.DependencyGraph
+	// Projects is aligned with Graph.Nodes; nil where the project is unknown.
+	Projects  []*models.Project
+	Timestamp time.Time
+}
+
+func (in Input) project(i int) *models.Project {
+	if i < 0 || i >= len(in.Projects) {
+		return nil
+	}
+	return in.Projects[i]
+}
+
+// dependsOn returns the distinct children of every node, in edge order.
+func (in Input) dependsOn() [][]int {
+	children := make([][]int, len(in.Graph.Nodes))
+	seen := make(map[[2]int]bool)
+	for _, edge := range in.Graph.Edges {
+		if edge.FromNode < 0 || edge.FromNode >= len(children) || edge.ToNode < 0 || edge.ToNode >= len(children) {
+			continue
+		}
+		key := [2]int{edge.FromNode, edge.ToNode}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		children[edge.FromNode] = append(children[edge.FromNode], edge.ToNode)
+	}
+	return children
+}
+
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
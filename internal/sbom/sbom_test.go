@@ -0,0 +1,283 @@
+package sbom_test
+
+import (
+	"bytes"
+	"codenotary/internal/models"
+	"codenotary/internal/sbom"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestPURL(t *testing.T) {
+	tests := []struct {
+		key  models.VersionKey
+		want string
+	}{
+		{models.VersionKey{System: "GO", Name: "github.com/spf13/cobra", Version: "v1.8.0"}, "pkg:golang/github.com/spf13/cobra@v1.8.0"},
+		{models.VersionKey{System: "NPM", Name: "@babel/core", Version: "7.23.0"}, "pkg:npm/%40babel/core@7.23.0"},
+		{models.VersionKey{System: "PYPI", Name: "Django_Rest", Version: "3.14.0"}, "pkg:pypi/django-rest@3.14.0"},
+		{models.VersionKey{System: "MAVEN", Name: "org.apache.commons:commons-lang3", Version: "3.14.0"}, "pkg:maven/org.apache.commons/commons-lang3@3.14.0"},
+		{models.VersionKey{System: "RUBYGEMS", Name: "rails", Version: "7.1.2"}, "pkg:gem/rails@7.1.2"},
+		{models.VersionKey{System: "CARGO", Name: "serde"}, "pkg:cargo/serde"},
+		{models.VersionKey{System: "UNKNOWN", Name: "x", Version: "1"}, ""},
+	}
+	for _, tt := range tests {
+		if got := sbom.PURL(tt.key); got != tt.want {
+			t.Errorf("PURL(%+v) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func testInput() sbom.Input {
+	node := func(name, version, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "NPM", Name: name, Version: version}, Relation: relation}
+	}
+	return sbom.Input{
+		System:  "NPM",
+		Name:    "express",
+		Version: "4.18.2",
+		Graph: &models.DependencyGraph{
+			Nodes: []models.Node{
+				node("express", "4.18.2", "SELF"),
+				node("body-parser", "1.20.1", "DIRECT"),
+				node("bytes", "3.1.2", "INDIRECT"),
+			},
+			Edges: []models.Edge{
+				{FromNode: 0, ToNode: 1, Requirement: "1.20.1"},
+				{FromNode: 1, ToNode: 2, Requirement: "3.1.2"},
+			},
+		},
+		Projects: []*models.Project{
+			{ProjectKey: models.ProjectKey{ID: "github.com/expressjs/express"}, License: "MIT"},
+			{
+				ProjectKey: models.ProjectKey{ID: "github.com/expressjs/body-parser"},
+				License:    "MIT OR Apache-2.0",
+				Scorecard: models.Scorecard{
+					Date:         "2024-01-08T00:00:00Z",
+					OverallScore: 5.5,
+					Checks:       []models.ScorecardCheck{{Name: "Maintained", Score: 3}},
+				},
+			},
+			nil,
+		},
+		Timestamp: time.Date(2024, 1, 9, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestCycloneDX(t *testing.T) {
+	bom := sbom.CycloneDX(testInput())
+
+	if bom.Metadata.Component.PURL != "pkg:npm/express@4.18.2" || bom.Metadata.Component.Type != "application" {
+		t.Errorf("metadata component = %+v, want express as the application", bom.Metadata.Component)
+	}
+	if len(bom.Components) != 2 {
+		t.Fatalf("got %d components, want 2", len(bom.Components))
+	}
+	bodyParser := bom.Components[0]
+	if len(bodyParser.Licenses) != 1 || bodyParser.Licenses[0].Expression != "MIT OR Apache-2.0" {
+		t.Errorf("body-parser licenses = %+v, want the expression", bodyParser.Licenses)
+	}
+	properties := make(map[string]string)
+	for _, p := range bodyParser.Properties {
+		properties[p.Name] = p.Value
+	}
+	if properties[sbom.PropertyScore] != "5.5" || properties[sbom.PropertyCheckPrefix+"Maintained"] != "3" {
+		t.Errorf("body-parser properties = %v, want the scorecard", properties)
+	}
+
+	dependencies := make(map[string][]string)
+	for _, d := range bom.Dependencies {
+		dependencies[d.Ref] = d.DependsOn
+	}
+	if got := dependencies["pkg:npm/body-parser@1.20.1"]; len(got) != 1 || got[0] != "pkg:npm/bytes@3.1.2" {
+		t.Errorf("body-parser depends on %v, want bytes", got)
+	}
+	if got, ok := dependencies["pkg:npm/bytes@3.1.2"]; !ok || len(got) != 0 {
+		t.Errorf("bytes dependencies = %v (listed %v), want an empty entry", got, ok)
+	}
+
+	var out bytes.Buffer
+	if err := bom.WriteJSON(&out); err != nil {
+		t.Fatalf("WriteJSON: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON output doesn't parse: %v", err)
+	}
+	if decoded["bomFormat"] != "CycloneDX" || decoded["specVersion"] != "1.5" {
+		t.Errorf("bomFormat/specVersion = %v/%v", decoded["bomFormat"], decoded["specVersion"])
+	}
+
+	out.Reset()
+	if err := bom.WriteXML(&out); err != nil {
+		t.Fatalf("WriteXML: %v", err)
+	}
+	var doc struct {
+		XMLName    xml.Name
+		Components []struct {
+			PURL string `xml:"purl"`
+		} `xml:"components>component"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("XML output doesn't parse: %v", err)
+	}
+	if doc.XMLName.Space != sbom.CycloneDXXMLNS || len(doc.Components) != 2 {
+		t.Errorf("XML has namespace %q and %d components", doc.XMLName.Space, len(doc.Components))
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	doc := sbom.SPDX(testInput())
+
+	if doc.SPDXVersion != "SPDX-2.3" || doc.Name != "express@4.18.2" {
+		t.Errorf("document %s %q", doc.SPDXVersion, doc.Name)
+	}
+	if len(doc.Packages) != 3 {
+		t.Fatalf("got %d packages, want 3", len(doc.Packages))
+	}
+	if got := doc.Packages[1].LicenseDeclared; got != "MIT OR Apache-2.0" {
+		t.Errorf("body-parser licenseDeclared = %q", got)
+	}
+	if got := doc.Packages[2].LicenseDeclared; got != "NOASSERTION" {
+		t.Errorf("bytes has no project, licenseDeclared = %q, want NOASSERTION", got)
+	}
+
+	want := []sbom.SPDXRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: "SPDXRef-Package-0"},
+		{SPDXElementID: "SPDXRef-Package-0", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-1"},
+		{SPDXElementID: "SPDXRef-Package-1", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Package-2"},
+	}
+	if len(doc.Relationships) != len(want) {
+		t.Fatalf("relationships = %+v, want %+v", doc.Relationships, want)
+	}
+	for i := range want {
+		if doc.Relationships[i] != want[i] {
+			t.Errorf("relationship %d = %+v, want %+v", i, doc.Relationships[i], want[i])
+		}
+	}
+
+	var out bytes.Buffer
+	if err := doc.WriteTagValue(&out); err != nil {
+		t.Fatalf("WriteTagValue: %v", err)
+	}
+	for _, line := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"PackageName: body-parser\n",
+		"ExternalRef: PACKAGE-MANAGER purl pkg:npm/bytes@3.1.2\n",
+		"Relationship: SPDXRef-Package-1 DEPENDS_ON SPDXRef-Package-2\n",
+	} {
+		if !bytes.Contains(out.Bytes(), []byte(line)) {
+			t.Errorf("tag-value output lacks %q", line)
+		}
+	}
+}
+
+func TestParsePURL(t *testing.T) {
+	tests := []struct {
+		purl string
+		want models.VersionKey
+		ok   bool
+	}{
+		{"pkg:golang/github.com/spf13/cobra@v1.8.0", models.VersionKey{System: "GO", Name: "github.com/spf13/cobra", Version: "v1.8.0"}, true},
+		{"pkg:npm/%40babel/core@7.23.0", models.VersionKey{System: "NPM", Name: "@babel/core", Version: "7.23.0"}, true},
+		{"pkg:npm/@babel/core", models.VersionKey{System: "NPM", Name: "@babel/core"}, true},
+		{"pkg:maven/org.apache.commons/commons-lang3@3.14.0?type=jar", models.VersionKey{System: "MAVEN", Name: "org.apache.commons:commons-lang3", Version: "3.14.0"}, true},
+		{"pkg:gem/rails@7.1.2#lib", models.VersionKey{System: "RUBYGEMS", Name: "rails", Version: "7.1.2"}, true},
+		{"pkg:deb/debian/curl@7.50.3", models.VersionKey{}, false},
+		{"pkg:maven/commons-lang3@3.14.0", models.VersionKey{}, false},
+		{"github.com/spf13/cobra", models.VersionKey{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := sbom.ParsePURL(tt.purl)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("ParsePURL(%q) = %+v, %v, want %+v, %v", tt.purl, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	in := testInput()
+	var cdxJSON, cdxXML, spdxJSON, spdxTV bytes.Buffer
+	if err := sbom.CycloneDX(in).WriteJSON(&cdxJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := sbom.CycloneDX(in).WriteXML(&cdxXML); err != nil {
+		t.Fatal(err)
+	}
+	if err := sbom.SPDX(in).WriteJSON(&spdxJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := sbom.SPDX(in).WriteTagValue(&spdxTV); err != nil {
+		t.Fatal(err)
+	}
+
+	documents := map[string][]byte{
+		sbom.FormatCycloneDXJSON: cdxJSON.Bytes(),
+		sbom.FormatCycloneDXXML:  cdxXML.Bytes(),
+		sbom.FormatSPDXJSON:      spdxJSON.Bytes(),
+		sbom.FormatSPDXTagValue:  spdxTV.Bytes(),
+	}
+	for format, data := range documents {
+		imported, err := sbom.Parse(data)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if imported.Format != format {
+			t.Errorf("detected %s, want %s", imported.Format, format)
+		}
+		if want := (models.VersionKey{System: "NPM", Name: "express", Version: "4.18.2"}); imported.Root != want {
+			t.Errorf("%s: root = %+v, want %+v", format, imported.Root, want)
+		}
+		if len(imported.Graph.Nodes) != len(in.Graph.Nodes) {
+			t.Fatalf("%s: got %d nodes, want %d", format, len(imported.Graph.Nodes), len(in.Graph.Nodes))
+		}
+		for i, node := range imported.Graph.Nodes {
+			want := in.Graph.Nodes[i]
+			if node.VersionKey != want.VersionKey || node.Relation != want.Relation {
+				t.Errorf("%s: node %d = %+v %s, want %+v %s", format, i, node.VersionKey, node.Relation, want.VersionKey, want.Relation)
+			}
+		}
+		if len(imported.Graph.Edges) != len(in.Graph.Edges) {
+			t.Fatalf("%s: got edges %+v, want %+v", format, imported.Graph.Edges, in.Graph.Edges)
+		}
+		for i, edge := range imported.Graph.Edges {
+			if want := in.Graph.Edges[i]; edge.FromNode != want.FromNode || edge.ToNode != want.ToNode {
+				t.Errorf("%s: edge %d = %+v, want %+v", format, i, edge, want)
+			}
+		}
+	}
+}
+
+func TestParseFlat(t *testing.T) {
+	data := []byte(`{
+		"bomFormat": "CycloneDX",
+		"specVersion": "1.4",
+		"metadata": {"tools": [{"vendor": "acme", "name": "scanner"}]},
+		"components": [
+			{"name": "requests", "version": "2.31.0", "purl": "pkg:pypi/requests@2.31.0",
+			 "components": [{"name": "urllib3", "version": "2.0.7", "purl": "pkg:pypi/urllib3@2.0.7"}]},
+			{"name": "libc", "version": "2.36", "purl": "pkg:deb/debian/libc6@2.36"},
+			{"name": "vendored-thing"}
+		]
+	}`)
+	imported, err := sbom.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Root.System != "PYPI" || imported.Root.Name != "" || imported.Root.Version != "local" {
+		t.Errorf("root = %+v, want an unnamed local PYPI package", imported.Root)
+	}
+	if len(imported.Graph.Nodes) != 3 || len(imported.Graph.Edges) != 2 {
+		t.Fatalf("got %d nodes and %d edges, want 3 and 2", len(imported.Graph.Nodes), len(imported.Graph.Edges))
+	}
+	for _, node := range imported.Graph.Nodes[1:] {
+		if node.Relation != "DIRECT" {
+			t.Errorf("%s is %s, want DIRECT", node.VersionKey.Name, node.Relation)
+		}
+	}
+	if want := []string{"pkg:deb/debian/libc6@2.36", "vendored-thing"}; len(imported.Skipped) != 2 || imported.Skipped[0] != want[0] || imported.Skipped[1] != want[1] {
+		t.Errorf("skipped = %v, want %v", imported.Skipped, want)
+	}
+}
@@ -0,0 +1,213 @@
+package sbom
+
+import (
+	"codenotary/internal/models"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	SPDXVersion            = "SPDX-2.3"
+	SPDXJSONContent        = "application/spdx+json"
+	SPDXTagValueContent    = "text/spdx; charset=utf-8"
+	spdxNoAssertion        = "NOASSERTION"
+	spdxDocumentID         = "SPDXRef-DOCUMENT"
+	spdxNamespaceURLPrefix = "https://spdx.org/spdxdocs/codenotary/"
+)
+
+type SPDXDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      SPDXCreationInfo   `json:"creationInfo"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+type SPDXCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type SPDXPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	Homepage         string            `json:"homepage,omitempty"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type SPDXRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDX builds an SPDX 2.3 document with one package per node. The document
+// DESCRIBES the SELF package and every edge becomes a DEPENDS_ON relationship.
+func SPDX(in Input) *SPDXDocument {
+	timestamp := in.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	name := in.Name
+	if in.Version != "" {
+		name += "@" + in.Version
+	}
+	doc := &SPDXDocument{
+		SPDXVersion:       SPDXVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            spdxDocumentID,
+		Name:              name,
+		DocumentNamespace: spdxNamespaceURLPrefix + strings.ToLower(in.System) + "/" + escapeSegment(name) + "-" + newUUID(),
+		CreationInfo: SPDXCreationInfo{
+			Created:  timestamp.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: " + ToolName},
+		},
+		Packages:      []SPDXPackage{},
+		Relationships: []SPDXRelationship{},
+	}
+
+	// Nodes with the same purl share one package.
+	ids := make([]string, len(in.Graph.Nodes))
+	byRef := make(map[string]string)
+	for i, node := range in.Graph.Nodes {
+		ref := bomRef(node.VersionKey)
+		if id, ok := byRef[ref]; ok {
+			ids[i] = id
+			continue
+		}
+		ids[i] = fmt.Sprintf("SPDXRef-Package-%d", len(doc.Packages))
+		byRef[ref] = ids[i]
+		doc.Packages = append(doc.Packages, spdxPackage(ids[i], node, in.project(i)))
+	}
+
+	for i, node := range in.Graph.Nodes {
+		if node.Relation == "SELF" {
+			doc.Relationships = append(doc.Relationships, SPDXRelationship{spdxDocumentID, "DESCRIBES", ids[i]})
+			break
+		}
+	}
+	seen := make(map[[2]string]bool)
+	for from, children := range in.dependsOn() {
+		for _, to := range children {
+			pair := [2]string{ids[from], ids[to]}
+			if seen[pair] || pair[0] == pair[1] {
+				continue
+			}
+			seen[pair] = true
+			doc.Relationships = append(doc.Relationships, SPDXRelationship{ids[from], "DEPENDS_ON", ids[to]})
+		}
+	}
+	return doc
+}
+
+func spdxPackage(id string, node models.Node, project *models.Project) SPDXPackage {
+	pkg := SPDXPackage{
+		Name:             node.VersionKey.Name,
+		SPDXID:           id,
+		VersionInfo:      node.VersionKey.Version,
+		DownloadLocation: spdxNoAssertion,
+		LicenseConcluded: spdxNoAssertion,
+		LicenseDeclared:  spdxNoAssertion,
+		CopyrightText:    spdxNoAssertion,
+	}
+	if purl := PURL(node.VersionKey); purl != "" {
+		pkg.ExternalRefs = []SPDXExternalRef{{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}}
+	}
+	if project == nil {
+		return pkg
+	}
+
+	if license := strings.TrimSpace(project.License); license != "" && (strings.Contains(license, " ") || isSPDXID(license)) {
+		pkg.LicenseDeclared = license
+	}
+	if strings.HasPrefix(project.Homepage, "http://") || strings.HasPrefix(project.Homepage, "https://") {
+		pkg.Homepage = project.Homepage
+	}
+	if project.Scorecard.Date != "" {
+		checks := make([]string, 0, len(project.Scorecard.Checks))
+		for _, check := range project.Scorecard.Checks {
+			checks = append(checks, check.Name+"="+formatFloat(check.Score))
+		}
+		pkg.Comment = fmt.Sprintf("OpenSSF Scorecard %s (%s): %s",
+			formatFloat(project.Scorecard.OverallScore), project.Scorecard.Date, strings.Join(checks, ", "))
+	}
+	return pkg
+}
+
+func (doc *SPDXDocument) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// WriteTagValue writes the document in the SPDX tag-value format.
+func (doc *SPDXDocument) WriteTagValue(w io.Writer) error {
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value == "" {
+			return
+		}
+		if strings.Contains(value, "\n") {
+			value = "<text>" + value + "</text>"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", name, value)
+	}
+
+	tag("SPDXVersion", doc.SPDXVersion)
+	tag("DataLicense", doc.DataLicense)
+	tag("SPDXID", doc.SPDXID)
+	tag("DocumentName", doc.Name)
+	tag("DocumentNamespace", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		tag("Creator", creator)
+	}
+	tag("Created", doc.CreationInfo.Created)
+
+	for _, pkg := range doc.Packages {
+		b.WriteString("\n")
+		tag("PackageName", pkg.Name)
+		tag("SPDXID", pkg.SPDXID)
+		tag("PackageVersion", pkg.VersionInfo)
+		tag("PackageDownloadLocation", pkg.DownloadLocation)
+		tag("FilesAnalyzed", fmt.Sprint(pkg.FilesAnalyzed))
+		tag("PackageHomePage", pkg.Homepage)
+		tag("PackageLicenseConcluded", pkg.LicenseConcluded)
+		tag("PackageLicenseDeclared", pkg.LicenseDeclared)
+		tag("PackageCopyrightText", pkg.CopyrightText)
+		if pkg.Comment != "" {
+			fmt.Fprintf(&b, "PackageComment: <text>%s</text>\n", pkg.Comment)
+		}
+		for _, ref := range pkg.ExternalRefs {
+			tag("ExternalRef", ref.ReferenceCategory+" "+ref.ReferenceType+" "+ref.ReferenceLocator)
+		}
+	}
+
+	if len(doc.Relationships) > 0 {
+		b.WriteString("\n")
+	}
+	for _, r := range doc.Relationships {
+		tag("Relationship", r.SPDXElementID+" "+r.RelationshipType+" "+r.RelatedSPDXElement)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,201 @@
+package sqlite
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InsertAdvisories adds or replaces advisories together with their affected versions.
+func InsertAdvisories(db *sql.DB, advisories []models.Advisory) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, a := range advisories {
+		_, err := tx.Exec(`
+			INSERT INTO advisories (id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at)
+			VALUES (?,?,?,?,?,?,?,?,?)
+			ON CONFLICT(id) DO UPDATE SET
+				source=excluded.source, title=excluded.title, url=excluded.url, aliases=excluded.aliases,
+				severity=excluded.severity, cvss3_score=excluded.cvss3_score, cvss3_vector=excluded.cvss3_vector,
+				fetched_at=excluded.fetched_at`,
+			a.AdvisoryKey.ID, a.Source, a.Title, a.URL, strings.Join(a.Aliases, ";"),
+			a.Severity, a.CVSS3Score, a.CVSS3Vector, formatTime(a.FetchedAt))
+		if err != nil {
+			return fmt.Errorf("failed to insert advisory %s: %v", a.AdvisoryKey.ID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM advisory_affected WHERE advisory_id = ?`, a.AdvisoryKey.ID); err != nil {
+			return fmt.Errorf("failed to replace affected versions of %s: %v", a.AdvisoryKey.ID, err)
+		}
+		for _, af := range a.Affected {
+			_, err := tx.Exec(`
+				INSERT INTO advisory_affected (advisory_id, system, name, version, introduced, fixed, last_affected)
+				VALUES (?,?,?,?,?,?,?)`,
+				a.AdvisoryKey.ID, af.System, af.Name, af.Version, af.Introduced, af.Fixed, af.LastAffected)
+			if err != nil {
+				return fmt.Errorf("failed to insert affected versions of %s: %v", a.AdvisoryKey.ID, err)
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// GetAdvisories returns the stored advisories among ids, ordered by id.
+func GetAdvisories(db *sql.DB, ids []string) ([]models.Advisory, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return queryAdvisories(db, `
+		SELECT id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at
+		FROM advisories
+		WHERE id IN (?`+strings.Repeat(",?", len(ids)-1)+`)
+		ORDER BY id`, args...)
+}
+
+// ListPackageAdvisories returns the advisories with affected versions of a package.
+func ListPackageAdvisories(db *sql.DB, system, name string) ([]models.Advisory, error) {
+	return queryAdvisories(db, `
+		SELECT id, source, title, url, aliases, severity, cvss3_score, cvss3_vector, fetched_at
+		FROM advisories
+		WHERE id IN (SELECT advisory_id FROM advisory_affected WHERE system = ? AND name = ?)
+		ORDER BY id`, system, name)
+}
+
+func queryAdvisories(db *sql.DB, query string, args ...interface{}) ([]models.Advisory, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error querying advisories: %v", err)
+	}
+	defer rows.Close()
+
+	var advisories []models.Advisory
+	for rows.Next() {
+		var a models.Advisory
+		var aliases string
+		var fetchedAt sql.NullString
+		if err := rows.Scan(&a.AdvisoryKey.ID, &a.Source, &a.Title, &a.URL, &aliases,
+			&a.Severity, &a.CVSS3Score, &a.CVSS3Vector, &fetchedAt); err != nil {
+			return nil, fmt.Errorf("error scanning advisories row: %v", err)
+		}
+		if aliases != "" {
+			a.Aliases = strings.Split(aliases, ";")
+		}
+		a.FetchedAt = parseTime(fetchedAt)
+		advisories = append(advisories, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating advisories rows: %v", err)
+	}
+	rows.Close()
+
+	for i := range advisories {
+		affected, err := getAffected(db, advisories[i].AdvisoryKey.ID)
+		if err != nil {
+			return nil, err
+		}
+		advisories[i].Affected = affected
+	}
+	return advisories, nil
+}
+
+func getAffected(db *sql.DB, advisoryID string) ([]models.Affected, error) {
+	rows, err := db.Query(`
+		SELECT system, name, version, introduced, fixed, last_affected
+		FROM advisory_affected
+		WHERE advisory_id = ?`, advisoryID)
+	if err != nil {
+		return nil, fmt.Errorf("error querying advisory_affected: %v", err)
+	}
+	defer rows.Close()
+
+	var affected []models.Affected
+	for rows.Next() {
+		var af models.Affected
+		if err := rows.Scan(&af.System, &af.Name, &af.Version, &af.Introduced, &af.Fixed, &af.LastAffected); err != nil {
+			return nil, fmt.Errorf("error scanning advisory_affected row: %v", err)
+		}
+		affected = append(affected, af)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating advisory_affected rows: %v", err)
+	}
+	return affected, nil
+}
+
+// SetVersionAdvisories replaces the advisory keys deps.dev reported for a package version.
+func SetVersionAdvisories(db *sql.DB, key models.VersionKey, advisoryIDs []string, fetchedAt time.Time) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO package_version_advisory_checks (system, name, version, fetched_at) VALUES (?,?,?,?)
+		ON CONFLICT(system, name, version) DO UPDATE SET fetched_at=excluded.fetched_at`,
+		key.System, key.Name, key.Version, formatTime(fetchedAt))
+	if err != nil {
+		return fmt.Errorf("failed to record advisory check: %v", err)
+	}
+	_, err = tx.Exec(`DELETE FROM package_version_advisories WHERE system = ? AND name = ? AND version = ?`,
+		key.System, key.Name, key.Version)
+	if err != nil {
+		return fmt.Errorf("failed to replace version advisories: %v", err)
+	}
+	for _, id := range advisoryIDs {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO package_version_advisories (system, name, version, advisory_id)
+			VALUES (?,?,?,?)`, key.System, key.Name, key.Version, id)
+		if err != nil {
+			return fmt.Errorf("failed to insert version advisory: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetVersionAdvisories returns the advisory keys of a package version and when
+// they were fetched, the zero time if never.
+func GetVersionAdvisories(db *sql.DB, key models.VersionKey) ([]string, time.Time, error) {
+	var fetchedAt sql.NullString
+	err := db.QueryRow(`
+		SELECT fetched_at FROM package_version_advisory_checks
+		WHERE system = ? AND name = ? AND version = ?`,
+		key.System, key.Name, key.Version).Scan(&fetchedAt)
+	if err == sql.ErrNoRows {
+		return nil, time.Time{}, nil
+	} else if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error querying package_version_advisory_checks: %v", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT advisory_id FROM package_version_advisories
+		WHERE system = ? AND name = ? AND version = ?
+		ORDER BY advisory_id`,
+		key.System, key.Name, key.Version)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("error querying package_version_advisories: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, time.Time{}, fmt.Errorf("error scanning package_version_advisories row: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, time.Time{}, fmt.Errorf("error iterating package_version_advisories rows: %v", err)
+	}
+	return ids, parseTime(fetchedAt), nil
+}
@@ -7,43 +7,49 @@ import (
 	"strings"
 )
 
+// AddOrUpdateDependency keeps manually managed dependencies in the graph whose
+// graph_id is the project id, one node per dependency name. New nodes are
+// numbered after the last one, as in the PostgreSQL store.
 func AddOrUpdateDependency(db *sql.DB, projectID string, dep models.Node) error {
-	query := `
-		INSERT INTO dependency_nodes (
-			project_id, graph_id, node_index, system, name, version, bundled, relation, errors, ossf_score
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-		ON CONFLICT(project_id, graph_id, node_index) DO UPDATE SET
-			system=excluded.system,
-			name=excluded.name,
-			version=excluded.version,
-			bundled=excluded.bundled,
-			relation=excluded.relation,
-			errors=excluded.errors,
-			ossf_score=excluded.ossf_score;
-	`
-	ossfScore, err := CalculateOpenSSF(db, dep.VersionKey.Name) 
+	ossfScore, err := CalculateOpenSSF(db, dep.VersionKey.Name)
 	if err != nil {
 		ossfScore = -1
 	}
-	_, err = db.Exec(query,
-		projectID,
-		projectID,           
-		dep.VersionKey.Name, 
-		dep.VersionKey.System,
-		dep.VersionKey.Name,
-		dep.VersionKey.Version,
-		dep.Bundled,
-		dep.Relation,
-		strings.Join(dep.Errors, ";"),
-		ossfScore,
-	)
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE dependency_nodes SET
+			system = ?, version = ?, bundled = ?, relation = ?, errors = ?, ossf_score = ?
+		WHERE project_id = ? AND graph_id = ? AND name = ?`,
+		dep.VersionKey.System, dep.VersionKey.Version, dep.Bundled, dep.Relation,
+		strings.Join(dep.Errors, ";"), ossfScore, projectID, projectID, dep.VersionKey.Name)
 	if err != nil {
 		return fmt.Errorf("failed to add or update dependency: %v", err)
 	}
-	return nil
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		_, err = tx.Exec(`
+			INSERT INTO dependency_nodes (
+				project_id, graph_id, node_index, system, name, version, bundled, relation, errors, ossf_score
+			)
+			SELECT ?, ?, COALESCE(MAX(node_index) + 1, 0), ?, ?, ?, ?, ?, ?, ?
+			FROM dependency_nodes WHERE project_id = ? AND graph_id = ?`,
+			projectID, projectID, dep.VersionKey.System, dep.VersionKey.Name, dep.VersionKey.Version,
+			dep.Bundled, dep.Relation, strings.Join(dep.Errors, ";"), ossfScore, projectID, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to add or update dependency: %v", err)
+		}
+	}
+	return tx.Commit()
 }
 
-func CalculateOpenSSF(db *sql.DB, depName string) (float64, error) {
+func CalculateOpenSSF(db interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, depName string) (float64, error) {
 	query := `SELECT scorecard_overall_score FROM project WHERE id = ? LIMIT 1`
 	var score float64
 	err := db.QueryRow(query, depName).Scan(&score)
@@ -105,48 +111,3 @@ func DeleteDependency(db *sql.DB, projectID, depName string) error {
 	}
 	return nil
 }
-
-
-func ListDependencies(db *sql.DB, name string, minScore float64) ([]models.Node, error) {
-	query := `
-		SELECT system, name, version, bundled, relation, errors, ossf_score
-		FROM dependency_nodes
-		WHERE 1=1
-	`
-	args := []interface{}{}
-
-	if name != "" {
-		query += " AND name LIKE ?"
-		args = append(args, "%"+name+"%")
-	}
-
-	if minScore > 0 {
-		query += " AND ossf_score >= ?"
-		args = append(args, minScore)
-	}
-
-	rows, err := db.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list dependencies: %v", err)
-	}
-	defer rows.Close()
-
-	var deps []models.Node
-	for rows.Next() {
-		var dep models.Node
-		var errorsStr string
-		var ossfScore float64
-		if err := rows.Scan(&dep.VersionKey.System, &dep.VersionKey.Name, &dep.VersionKey.Version, &dep.Bundled, &dep.Relation, &errorsStr, &ossfScore); err != nil {
-			return nil, fmt.Errorf("failed to scan dependency: %v", err)
-		}
-		dep.Errors = strings.Split(errorsStr, ";")
-		
-		deps = append(deps, dep)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating dependencies: %v", err)
-	}
-
-	return deps, nil
-}
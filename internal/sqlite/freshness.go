@@ -0,0 +1,122 @@
+package sqlite
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// timeLayout matches SQLite's datetime() output so stored values can be compared in SQL.
+const timeLayout = "2006-01-02 15:04:05"
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+// parseTime returns the zero time for rows written before fetched_at existed,
+// which makes them count as expired.
+func parseTime(s sql.NullString) time.Time {
+	if !s.Valid {
+		return time.Time{}
+	}
+	t, err := time.Parse(timeLayout, s.String)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func ListStaleProjects(db *sql.DB, fetchedBefore time.Time, limit int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT id FROM project
+		WHERE fetched_at IS NULL OR fetched_at < ?
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at)
+		LIMIT ?`, formatTime(fetchedBefore), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale projects: %v", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan stale project: %v", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func ListStalePackages(db *sql.DB, fetchedBefore time.Time, limit int) ([]models.PackageKey, error) {
+	rows, err := db.Query(`
+		SELECT system, name FROM packages
+		WHERE fetched_at IS NULL OR fetched_at < ?
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at)
+		LIMIT ?`, formatTime(fetchedBefore), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale packages: %v", err)
+	}
+	defer rows.Close()
+
+	var keys []models.PackageKey
+	for rows.Next() {
+		var key models.PackageKey
+		if err := rows.Scan(&key.System, &key.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan stale package: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// ListStaleGraphs only returns graphs fetched from deps.dev; uploaded graphs can't be re-fetched.
+func ListStaleGraphs(db *sql.DB, fetchedBefore time.Time, limit int) ([]store.StaleGraph, error) {
+	rows, err := db.Query(`
+		SELECT system, project_id, version FROM dependency_graphs
+		WHERE source = ? AND (fetched_at IS NULL OR fetched_at < ?)
+		ORDER BY COALESCE(refresh_attempted_at, fetched_at)
+		LIMIT ?`, store.SourceDepsDev, formatTime(fetchedBefore), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stale graphs: %v", err)
+	}
+	defer rows.Close()
+
+	var graphs []store.StaleGraph
+	for rows.Next() {
+		var g store.StaleGraph
+		if err := rows.Scan(&g.System, &g.ProjectID, &g.Version); err != nil {
+			return nil, fmt.Errorf("failed to scan stale graph: %v", err)
+		}
+		graphs = append(graphs, g)
+	}
+	return graphs, rows.Err()
+}
+
+func MarkProjectAttempt(db *sql.DB, projectID string, at time.Time) error {
+	if _, err := db.Exec(`UPDATE project SET refresh_attempted_at = ? WHERE id = ?`, formatTime(at), projectID); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of project %s: %v", projectID, err)
+	}
+	return nil
+}
+
+func MarkPackageAttempt(db *sql.DB, key models.PackageKey, at time.Time) error {
+	if _, err := db.Exec(`UPDATE packages SET refresh_attempted_at = ? WHERE system = ? AND name = ?`,
+		formatTime(at), key.System, key.Name); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of package %s/%s: %v", key.System, key.Name, err)
+	}
+	return nil
+}
+
+func MarkGraphAttempt(db *sql.DB, g store.StaleGraph, at time.Time) error {
+	graphID := store.GraphID(g.System, g.ProjectID, g.Version)
+	if _, err := db.Exec(`UPDATE dependency_graphs SET refresh_attempted_at = ? WHERE graph_id = ?`, formatTime(at), graphID); err != nil {
+		return fmt.Errorf("failed to record refresh attempt of graph %s: %v", graphID, err)
+	}
+	return nil
+}
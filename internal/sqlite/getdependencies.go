@@ -2,19 +2,19 @@ package sqlite
 
 import (
 	"codenotary/internal/models"
+	"codenotary/internal/store"
 	"database/sql"
 	"fmt"
 	"strings"
 )
 
-
-
-func GetDependencyGraph(db *sql.DB, projectID string) (*models.DependencyGraph, error) {
+func GetDependencyGraph(db *sql.DB, system, projectID, version string) (*models.DependencyGraph, error) {
 	
 	nodeRows, err := db.Query(`
 			SELECT node_index, system, name, version, bundled, relation, errors
 			FROM dependency_nodes
-			WHERE project_id = ?`, projectID)
+			WHERE project_id = ? AND graph_id = ?
+			ORDER BY node_index`, projectID, store.GraphID(system, projectID, version))
 	if err != nil {
 		return nil, fmt.Errorf("error querying dependency_nodes: %v", err)
 	}
@@ -64,7 +64,7 @@ func GetDependencyGraph(db *sql.DB, projectID string) (*models.DependencyGraph,
 	edgeRows, err := db.Query(`
 			SELECT from_node_index, to_node_index, requirement
 			FROM dependency_edges
-			WHERE project_id = ?`, projectID)
+			WHERE project_id = ? AND graph_id = ?`, projectID, store.GraphID(system, projectID, version))
 	if err != nil {
 		return nil, fmt.Errorf("error querying dependency_edges: %v", err)
 	}
@@ -92,10 +92,41 @@ func GetDependencyGraph(db *sql.DB, projectID string) (*models.DependencyGraph,
 		return nil, fmt.Errorf("error iterating dependency_edges rows: %v", err)
 	}
 
+	var fetchedAt sql.NullString
+	err = db.QueryRow(`SELECT fetched_at FROM dependency_graphs WHERE graph_id = ?`, store.GraphID(system, projectID, version)).Scan(&fetchedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("error querying dependency_graphs: %v", err)
+	}
+
 	graph := &models.DependencyGraph{
-		Nodes: nodes,
-		Edges: edges,
+		Nodes:     nodes,
+		Edges:     edges,
+		FetchedAt: parseTime(fetchedAt),
 	}
 
 	return graph, nil
 }
+
+func ListGraphVersions(db *sql.DB, system, projectID string) ([]string, error) {
+	rows, err := db.Query(`
+			SELECT DISTINCT version
+			FROM dependency_nodes
+			WHERE project_id = ? AND system = ? AND relation = 'SELF'`, projectID, system)
+	if err != nil {
+		return nil, fmt.Errorf("error querying stored graph versions: %v", err)
+	}
+	defer rows.Close()
+
+	versions := []string{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("error scanning stored graph version: %v", err)
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating stored graph versions: %v", err)
+	}
+	return versions, nil
+}
@@ -8,14 +8,14 @@ import (
 
 
 
-func GetPackageVersions(db *sql.DB, name string) (*models.PackageVersions, error) {
+func GetPackageVersions(db *sql.DB, system, name string) (*models.PackageVersions, error) {
 	
-	system := "GO"
 	var pkgName string
+	var fetchedAt sql.NullString
 	err := db.QueryRow(`
-		SELECT name FROM packages
+		SELECT name, fetched_at FROM packages
 		WHERE system = ? AND name = ?`,
-		system, name).Scan(&pkgName)
+		system, name).Scan(&pkgName, &fetchedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil 
 	} else if err != nil {
@@ -64,7 +64,8 @@ func GetPackageVersions(db *sql.DB, name string) (*models.PackageVersions, error
 			System: system,
 			Name:   name,
 		},
-		Versions: versions,
+		Versions:  versions,
+		FetchedAt: parseTime(fetchedAt),
 	}
 
 	return pv, nil
@@ -14,12 +14,13 @@ func GetProject(db *sql.DB, projectKey string) (*models.Project, error) {
 	var p models.Project
 	var scorecardDate, repoName, repoCommit, scorecardVersion, scorecardCommit string
 	var overallScore float64
+	var fetchedAt sql.NullString
 
 	projectQuery := `
 		SELECT 
 			id, open_issues_count, stars_count, forks_count, license, description, homepage,
 			scorecard_date, scorecard_repo_name, scorecard_repo_commit, scorecard_version,
-			scorecard_commit, scorecard_overall_score
+			scorecard_commit, scorecard_overall_score, fetched_at
 		FROM project
 		WHERE id = ?
 	`
@@ -38,6 +39,7 @@ func GetProject(db *sql.DB, projectKey string) (*models.Project, error) {
 		&scorecardVersion,
 		&scorecardCommit,
 		&overallScore,
+		&fetchedAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -52,6 +54,7 @@ func GetProject(db *sql.DB, projectKey string) (*models.Project, error) {
 	p.Scorecard.Scorecard.Version = scorecardVersion
 	p.Scorecard.Scorecard.Commit = scorecardCommit
 	p.Scorecard.OverallScore = overallScore
+	p.FetchedAt = parseTime(fetchedAt)
 
 	
 	checksQuery := `
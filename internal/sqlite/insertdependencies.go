@@ -2,16 +2,68 @@ package sqlite
 
 import (
 	"codenotary/internal/models"
+	"codenotary/internal/store"
 	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 )
 
-func InsertDependencyGraph(db *sql.DB, projectID string, graph *models.DependencyGraph) error {
+// graphWriter is a *sql.DB or the *sql.Tx a graph is written in.
+type graphWriter interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// InsertDependencyGraph stores a graph and its nodes and edges in one transaction.
+func InsertDependencyGraph(db *sql.DB, system, projectID, version, source string, graph *models.DependencyGraph) error {
+	if graph == nil {
+		return fmt.Errorf("nil graph")
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := insertDependencyGraph(tx, system, projectID, version, source, graph); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReplaceDependencyGraph deletes the stored graph of a version and stores graph in
+// its place, in one transaction: on failure the old graph is left as it was.
+func ReplaceDependencyGraph(db *sql.DB, system, projectID, version, source string, graph *models.DependencyGraph) error {
 	if graph == nil {
 		return fmt.Errorf("nil graph")
 	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := deleteDependencyGraph(tx, system, projectID, version); err != nil {
+		return err
+	}
+	if err := insertDependencyGraph(tx, system, projectID, version, source, graph); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func insertDependencyGraph(db graphWriter, system, projectID, version, source string, graph *models.DependencyGraph) error {
+	graphID := store.GraphID(system, projectID, version)
+
+	_, err := db.Exec(`
+		INSERT INTO dependency_graphs (graph_id, project_id, system, version, source, fetched_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(graph_id) DO UPDATE SET
+			source=excluded.source,
+			fetched_at=excluded.fetched_at`,
+		graphID, projectID, system, version, source, formatTime(graph.FetchedAt))
+	if err != nil {
+		return fmt.Errorf("failed to record graph %s: %v", graphID, err)
+	}
 
 	for idx, node := range graph.Nodes {
 		errors := strings.Join(node.Errors, ";")
@@ -19,7 +71,7 @@ func InsertDependencyGraph(db *sql.DB, projectID string, graph *models.Dependenc
 		// Calculate ossf_score for the dependency
 		ossfScore, err := CalculateOpenSSF(db, node.VersionKey.Name)
 		if err != nil {
-			log.Printf("Error calculating OpenSSF score for %s: %v. Setting ossf_score to -1.", node.VersionKey.Name, err)
+			slog.Warn("calculating OpenSSF score, storing -1", "name", node.VersionKey.Name, "err", err)
 			ossfScore = -1
 		}
 
@@ -29,7 +81,7 @@ func InsertDependencyGraph(db *sql.DB, projectID string, graph *models.Dependenc
             ) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
 		args := []interface{}{
 			projectID,
-			projectID,
+			graphID,
 			idx,
 			node.VersionKey.System,
 			node.VersionKey.Name,
@@ -42,7 +94,6 @@ func InsertDependencyGraph(db *sql.DB, projectID string, graph *models.Dependenc
 
 		_, err = db.Exec(query, args...)
 		if err != nil {
-			log.Printf("Error inserting node at index %d: %v", idx, err)
 			return fmt.Errorf("failed to insert node at index %d: %v", idx, err)
 		}
 	}
@@ -53,16 +104,41 @@ func InsertDependencyGraph(db *sql.DB, projectID string, graph *models.Dependenc
 			VALUES (?, ?, ?, ?, ?)`
 		args := []interface{}{
 			projectID,
-			projectID, edge.FromNode, edge.ToNode, edge.Requirement,
+			graphID, edge.FromNode, edge.ToNode, edge.Requirement,
 		}
 
 		_, err := db.Exec(query, args...)
 		if err != nil {
-			log.Printf("Error inserting edge from %d to %d: %v", edge.FromNode, edge.ToNode, err)
 			return fmt.Errorf("failed to insert edge from %d to %d: %v", edge.FromNode, edge.ToNode, err)
 		}
 	}
 
-	log.Println("Dependency graph inserted successfully.")
+	slog.Debug("dependency graph stored", "graph", graphID, "nodes", len(graph.Nodes))
+	return nil
+}
+
+func DeleteDependencyGraph(db *sql.DB, system, projectID, version string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := deleteDependencyGraph(tx, system, projectID, version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func deleteDependencyGraph(db graphWriter, system, projectID, version string) error {
+	graphID := store.GraphID(system, projectID, version)
+	if _, err := db.Exec(`DELETE FROM dependency_nodes WHERE project_id = ? AND graph_id = ?`, projectID, graphID); err != nil {
+		return fmt.Errorf("failed to delete nodes of graph %s: %v", graphID, err)
+	}
+	if _, err := db.Exec(`DELETE FROM dependency_edges WHERE project_id = ? AND graph_id = ?`, projectID, graphID); err != nil {
+		return fmt.Errorf("failed to delete edges of graph %s: %v", graphID, err)
+	}
+	if _, err := db.Exec(`DELETE FROM dependency_graphs WHERE graph_id = ?`, graphID); err != nil {
+		return fmt.Errorf("failed to delete graph %s: %v", graphID, err)
+	}
 	return nil
 }
@@ -10,13 +10,21 @@ import (
 func StorePackageVersions(db *sql.DB, pv *models.PackageVersions) error {
 	
 	_, err := db.Exec(`
-			INSERT OR IGNORE INTO packages (system, name) VALUES (?, ?)`,
-		pv.PackageKey.System, pv.PackageKey.Name)
+			INSERT INTO packages (system, name, fetched_at) VALUES (?, ?, ?)
+			ON CONFLICT(system, name) DO UPDATE SET fetched_at=excluded.fetched_at`,
+		pv.PackageKey.System, pv.PackageKey.Name, formatTime(pv.FetchedAt))
 	if err != nil {
 		return fmt.Errorf("failed to insert package: %v", err)
 	}
 
 	
+	_, err = db.Exec(`DELETE FROM package_versions WHERE system = ? AND name = ?`,
+		pv.PackageKey.System, pv.PackageKey.Name)
+	if err != nil {
+		return fmt.Errorf("failed to replace versions: %v", err)
+	}
+
+	
 	for _, v := range pv.Versions {
 		isDefault := 0
 		if v.IsDefault {
@@ -17,8 +17,22 @@ func InsertProject(db *sql.DB, p *models.Project) error {
 	INSERT INTO project (
 			id, open_issues_count, stars_count, forks_count, license, description, homepage,
 			scorecard_date, scorecard_repo_name, scorecard_repo_commit, scorecard_version,
-			scorecard_commit, scorecard_overall_score
-	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?);
+			scorecard_commit, scorecard_overall_score, fetched_at
+	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+	ON CONFLICT(id) DO UPDATE SET
+			open_issues_count=excluded.open_issues_count,
+			stars_count=excluded.stars_count,
+			forks_count=excluded.forks_count,
+			license=excluded.license,
+			description=excluded.description,
+			homepage=excluded.homepage,
+			scorecard_date=excluded.scorecard_date,
+			scorecard_repo_name=excluded.scorecard_repo_name,
+			scorecard_repo_commit=excluded.scorecard_repo_commit,
+			scorecard_version=excluded.scorecard_version,
+			scorecard_commit=excluded.scorecard_commit,
+			scorecard_overall_score=excluded.scorecard_overall_score,
+			fetched_at=excluded.fetched_at;
 	`
 	_, err := db.Exec(insertProjectSQL,
 		p.ProjectKey.ID,
@@ -34,11 +48,20 @@ func InsertProject(db *sql.DB, p *models.Project) error {
 		p.Scorecard.Scorecard.Version,
 		p.Scorecard.Scorecard.Commit,
 		p.Scorecard.OverallScore,
+		formatTime(p.FetchedAt),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert project: %v", err)
 	}
 
+	if err := insertSnapshot(db, p); err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM scorecard_checks WHERE project_id = ?`, p.ProjectKey.ID); err != nil {
+		return fmt.Errorf("failed to replace scorecard checks: %v", err)
+	}
+
 	
 	insertCheckSQL := `
 	INSERT INTO scorecard_checks (
@@ -73,11 +96,25 @@ func InsertProjects(db *sql.DB, projects []*models.Project) error {
 
 	
 	insertProjectStmt, err := tx.Prepare(`
-		INSERT INTO project (
+	INSERT INTO project (
 			id, open_issues_count, stars_count, forks_count, license, description, homepage,
 			scorecard_date, scorecard_repo_name, scorecard_repo_commit, scorecard_version,
-			scorecard_commit, scorecard_overall_score
-		) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)
+			scorecard_commit, scorecard_overall_score, fetched_at
+	) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+	ON CONFLICT(id) DO UPDATE SET
+			open_issues_count=excluded.open_issues_count,
+			stars_count=excluded.stars_count,
+			forks_count=excluded.forks_count,
+			license=excluded.license,
+			description=excluded.description,
+			homepage=excluded.homepage,
+			scorecard_date=excluded.scorecard_date,
+			scorecard_repo_name=excluded.scorecard_repo_name,
+			scorecard_repo_commit=excluded.scorecard_repo_commit,
+			scorecard_version=excluded.scorecard_version,
+			scorecard_commit=excluded.scorecard_commit,
+			scorecard_overall_score=excluded.scorecard_overall_score,
+			fetched_at=excluded.fetched_at
 	`)
 	if err != nil {
 		tx.Rollback()
@@ -97,6 +134,13 @@ func InsertProjects(db *sql.DB, projects []*models.Project) error {
 	}
 	defer insertCheckStmt.Close()
 
+	deleteChecksStmt, err := tx.Prepare(`DELETE FROM scorecard_checks WHERE project_id = ?`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to prepare scorecard checks delete statement: %w", err)
+	}
+	defer deleteChecksStmt.Close()
+
 	var errs []error
 
 	for _, p := range projects {
@@ -119,6 +163,7 @@ func InsertProjects(db *sql.DB, projects []*models.Project) error {
 			p.Scorecard.Scorecard.Version,
 			p.Scorecard.Scorecard.Commit,
 			p.Scorecard.OverallScore,
+			formatTime(p.FetchedAt),
 		)
 		if perr != nil {
 			
@@ -127,6 +172,15 @@ func InsertProjects(db *sql.DB, projects []*models.Project) error {
 			continue
 		}
 
+		if serr := insertSnapshot(tx, p); serr != nil {
+			errs = append(errs, fmt.Errorf("failed to snapshot scorecard of project (ID: %s): %w", p.ProjectKey.ID, serr))
+		}
+
+		if _, derr := deleteChecksStmt.Exec(p.ProjectKey.ID); derr != nil {
+			errs = append(errs, fmt.Errorf("failed to replace scorecard checks of project (ID: %s): %w", p.ProjectKey.ID, derr))
+			continue
+		}
+
 		
 		for _, check := range p.Scorecard.Checks {
 			detailsStr := strings.Join(check.Details, "\n")
@@ -0,0 +1,13 @@
+package sqlite
+
+import (
+	"codenotary/internal/store"
+	"codenotary/internal/store/sqlquery"
+	"database/sql"
+)
+
+// ListDependencies returns the page of dependency nodes q selects, with the
+// number of matches on every page.
+func ListDependencies(db *sql.DB, q store.DependencyQuery) (*store.DependencyPage, error) {
+	return sqlquery.ListDependencies(db, sqlquery.Question, q)
+}
@@ -0,0 +1,188 @@
+package sqlite
+
+import (
+	"codenotary/internal/store"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+type migration struct {
+	store.Migration
+	SQL string
+	// Func is used instead of SQL for steps plain SQLite can't express, like
+	// adding a column only when it is missing.
+	Func func(tx *sql.Tx) error
+}
+
+// goMigrations are the steps that live in Go rather than in migrations/*.sql.
+var goMigrations = []migration{
+	{
+		Migration: store.Migration{Version: 2, Name: "add_missing_columns"},
+		Func: func(tx *sql.Tx) error {
+			// ossf_score was added to dependency_nodes without touching existing
+			// databases, fetched_at came with cache freshness.
+			columns := []struct{ table, column, decl string }{
+				{"dependency_nodes", "ossf_score", "REAL"},
+				{"project", "fetched_at", "TEXT"},
+				{"packages", "fetched_at", "TEXT"},
+			}
+			for _, c := range columns {
+				if err := ensureColumn(tx, c.table, c.column, c.decl); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+}
+
+// migrations returns every known migration ordered by version.
+func migrations() ([]migration, error) {
+	files, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %v", err)
+	}
+
+	all := append([]migration{}, goMigrations...)
+	for _, file := range files {
+		version, name, ok := strings.Cut(strings.TrimSuffix(file.Name(), ".sql"), "_")
+		n, err := strconv.Atoi(version)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s is not named NNNN_name.sql", file.Name())
+		}
+		body, err := migrationFiles.ReadFile(path.Join("migrations", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %v", file.Name(), err)
+		}
+		all = append(all, migration{Migration: store.Migration{Version: n, Name: name}, SQL: string(body)})
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+	for i, m := range all {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migrations must be numbered 1..n without gaps, found %d at position %d", m.Version, i+1)
+		}
+	}
+	return all, nil
+}
+
+// SchemaVersion returns the highest applied migration, 0 for a fresh database.
+func SchemaVersion(db *sql.DB) (int, error) {
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT,
+			applied_at TEXT
+		)`); err != nil {
+		return 0, fmt.Errorf("failed to create schema_version table: %v", err)
+	}
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// Migrate applies pending migrations in order, each in its own transaction, and
+// returns the ones it applied (or would apply, with DryRun). It then sets up the
+// full-text search index, which depends on how SQLite was built (see
+// ensureSearchIndex).
+func Migrate(db *sql.DB, opts store.MigrateOptions) ([]store.Migration, error) {
+	all, err := migrations()
+	if err != nil {
+		return nil, err
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if current > len(all) {
+		return nil, fmt.Errorf("database schema version %d is newer than this binary (%d)", current, len(all))
+	}
+
+	var pending []migration
+	var infos []store.Migration
+	for _, m := range all {
+		if m.Version <= current || (opts.Target > 0 && m.Version > opts.Target) {
+			continue
+		}
+		pending = append(pending, m)
+		infos = append(infos, m.Migration)
+	}
+	if opts.DryRun {
+		return infos, nil
+	}
+
+	for i, m := range pending {
+		if err := apply(db, m); err != nil {
+			return infos[:i], fmt.Errorf("migration %04d_%s failed: %v", m.Version, m.Name, err)
+		}
+	}
+	if err := ensureSearchIndex(db); err != nil {
+		return infos, err
+	}
+	return infos, nil
+}
+
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if m.Func != nil {
+		err = m.Func(tx)
+	} else {
+		_, err = tx.Exec(m.SQL)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.Version, m.Name, formatTime(time.Now())); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// ensureColumn adds a column that databases created by older versions lack.
+func ensureColumn(db querier, table, column, decl string) error {
+	rows, err := db.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %v", table, err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %v", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to inspect %s table: %v", table, err)
+	}
+	rows.Close()
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %v", table, column, err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package sqlite_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMigrateUpgradesLegacyDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	defer db.Close()
+
+	// A database from before ossf_score, fetched_at and migrations existed.
+	_, err = db.Exec(`
+		CREATE TABLE project (id TEXT PRIMARY KEY, scorecard_date TEXT, scorecard_repo_commit TEXT,
+			scorecard_version TEXT, scorecard_commit TEXT, scorecard_overall_score REAL, stars_count INTEGER,
+			description TEXT, homepage TEXT);
+		CREATE TABLE scorecard_checks (project_id TEXT, name TEXT, score REAL, reason TEXT);
+		CREATE TABLE packages (system TEXT, name TEXT, PRIMARY KEY (system, name));
+		CREATE TABLE dependency_nodes (id INTEGER PRIMARY KEY AUTOINCREMENT, project_id TEXT, graph_id TEXT,
+			node_index INTEGER, system TEXT, name TEXT, version TEXT, bundled BOOLEAN, relation TEXT, errors TEXT,
+			UNIQUE(project_id, graph_id, node_index));
+		CREATE TABLE dependency_edges (id INTEGER PRIMARY KEY AUTOINCREMENT, project_id TEXT, graph_id TEXT,
+			from_node_index INTEGER, to_node_index INTEGER, requirement TEXT);
+		INSERT INTO dependency_nodes (project_id, graph_id, node_index, system, name, version, bundled, relation, errors) VALUES
+			('github.com/spf13/cobra', 'github.com/spf13/cobra', 0, 'GO', 'github.com/spf13/cobra', 'v1.8.0', 0, 'SELF', ''),
+			('github.com/spf13/cobra', 'github.com/spf13/cobra', 1, 'GO', 'github.com/spf13/pflag', 'v1.0.5', 0, 'DIRECT', ''),
+			('my-project', 'my-project', 'gopkg.in/yaml.v3', 'GO', 'gopkg.in/yaml.v3', 'v3.0.1', 0, 'DIRECT', ''),
+			('my-project', 'my-project', 'github.com/spf13/pflag', 'GO', 'github.com/spf13/pflag', 'v1.0.5', 0, 'DIRECT', '');
+		INSERT INTO dependency_edges (project_id, graph_id, from_node_index, to_node_index, requirement) VALUES
+			('github.com/spf13/cobra', 'github.com/spf13/cobra', 0, 1, 'v1.0.5');
+		INSERT INTO project VALUES ('github.com/spf13/cobra', '2024-01-08T00:00:00Z', 'aaa', 'v4', 'bbb', 5.9, 37000, 'A Commander for modern Go CLI interactions', 'https://cobra.dev');
+		INSERT INTO scorecard_checks VALUES ('github.com/spf13/cobra', 'Maintained', 10, 'active');`)
+	if err != nil {
+		t.Fatalf("creating legacy schema: %v", err)
+	}
+
+	pending, err := sqlite.Migrate(db, store.MigrateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if version, _ := sqlite.SchemaVersion(db); version != 0 {
+		t.Fatalf("dry run moved the schema to version %d", version)
+	}
+
+	applied, err := sqlite.Migrate(db, store.MigrateOptions{})
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(applied) != len(pending) {
+		t.Errorf("applied %d migrations, dry run announced %d", len(applied), len(pending))
+	}
+	if _, err := db.Exec(`UPDATE dependency_nodes SET ossf_score = 1`); err != nil {
+		t.Errorf("dependency_nodes.ossf_score missing after migrating: %v", err)
+	}
+
+	history, err := sqlite.GetScorecardHistory(db, "github.com/spf13/cobra")
+	if err != nil {
+		t.Fatalf("GetScorecardHistory: %v", err)
+	}
+	if len(history) != 1 || history[0].Checks["Maintained"] != 10 {
+		t.Errorf("existing scorecard was not backfilled into history: %+v", history)
+	}
+
+	// Legacy graphs were keyed by project id; they are now found by version.
+	graph, err := sqlite.GetDependencyGraph(db, "GO", "github.com/spf13/cobra", "v1.8.0")
+	if err != nil {
+		t.Fatalf("GetDependencyGraph: %v", err)
+	}
+	if graph == nil {
+		t.Fatal("legacy graph not found by version after migrating")
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[1].VersionKey.Name != "github.com/spf13/pflag" || len(graph.Edges) != 1 || graph.Edges[0].Requirement != "v1.0.5" {
+		t.Errorf("legacy graph after migrating = %+v", graph)
+	}
+	versions, err := sqlite.ListGraphVersions(db, "GO", "github.com/spf13/cobra")
+	if err != nil || len(versions) != 1 || versions[0] != "v1.8.0" {
+		t.Errorf("ListGraphVersions = %v, %v; want [v1.8.0]", versions, err)
+	}
+	stale, err := sqlite.ListStaleGraphs(db, time.Now(), 10)
+	if err != nil || len(stale) != 1 || stale[0].Version != "v1.8.0" {
+		t.Errorf("ListStaleGraphs = %+v, %v; want the legacy graph, its age being unknown", stale, err)
+	}
+
+	// Manually added dependencies were numbered by name; they are now numbered
+	// like the ones added after migrating.
+	dep := models.Node{VersionKey: models.VersionKey{System: "GO", Name: "github.com/spf13/pflag", Version: "v1.0.6"}, Relation: "DIRECT"}
+	if err := sqlite.AddOrUpdateDependency(db, "my-project", dep); err != nil {
+		t.Fatalf("AddOrUpdateDependency(pflag): %v", err)
+	}
+	dep.VersionKey = models.VersionKey{System: "GO", Name: "golang.org/x/mod", Version: "v0.22.0"}
+	if err := sqlite.AddOrUpdateDependency(db, "my-project", dep); err != nil {
+		t.Fatalf("AddOrUpdateDependency(mod): %v", err)
+	}
+	rows, err := db.Query(`SELECT node_index, name, version FROM dependency_nodes WHERE project_id = 'my-project' ORDER BY id`)
+	if err != nil {
+		t.Fatalf("reading manual dependencies: %v", err)
+	}
+	defer rows.Close()
+	var manual []string
+	for rows.Next() {
+		var index any
+		var name, version string
+		if err := rows.Scan(&index, &name, &version); err != nil {
+			t.Fatalf("reading manual dependencies: %v", err)
+		}
+		manual = append(manual, fmt.Sprintf("%v %s@%s", index, name, version))
+	}
+	want := []string{"0 gopkg.in/yaml.v3@v3.0.1", "1 github.com/spf13/pflag@v1.0.6", "2 golang.org/x/mod@v0.22.0"}
+	if !reflect.DeepEqual(manual, want) {
+		t.Errorf("manual dependencies after migrating = %q, want %q", manual, want)
+	}
+
+	again, err := sqlite.Migrate(db, store.MigrateOptions{})
+	if err != nil || len(again) != 0 {
+		t.Errorf("second Migrate applied %d migrations, err %v; want none", len(again), err)
+	}
+}
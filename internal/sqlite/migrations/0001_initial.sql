@@ -0,0 +1,65 @@
+-- Schema as created by sqlite.Create before migrations existed.
+CREATE TABLE IF NOT EXISTS project (
+	id TEXT PRIMARY KEY,
+	open_issues_count INTEGER,
+	stars_count INTEGER,
+	forks_count INTEGER,
+	license TEXT,
+	description TEXT,
+	homepage TEXT,
+	scorecard_date TEXT,
+	scorecard_repo_name TEXT,
+	scorecard_repo_commit TEXT,
+	scorecard_version TEXT,
+	scorecard_commit TEXT,
+	scorecard_overall_score REAL
+);
+
+CREATE TABLE IF NOT EXISTS scorecard_checks (
+	project_id TEXT,
+	name TEXT,
+	short_description TEXT,
+	url TEXT,
+	score REAL,
+	reason TEXT,
+	details TEXT,
+	FOREIGN KEY (project_id) REFERENCES project(id)
+);
+
+CREATE TABLE IF NOT EXISTS packages (
+	system TEXT,
+	name TEXT,
+	PRIMARY KEY (system, name)
+);
+
+CREATE TABLE IF NOT EXISTS package_versions (
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	is_default INTEGER,
+	PRIMARY KEY (system, name, version),
+	FOREIGN KEY (system, name) REFERENCES packages(system, name)
+);
+
+CREATE TABLE IF NOT EXISTS dependency_nodes (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id TEXT,
+	graph_id TEXT,       -- {system}/{name}@{version}
+	node_index INTEGER,  -- index of this node in the original response
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	bundled BOOLEAN,
+	relation TEXT,       -- SELF, DIRECT, INDIRECT
+	errors TEXT,         -- ';' separated
+	UNIQUE(project_id, graph_id, node_index)
+);
+
+CREATE TABLE IF NOT EXISTS dependency_edges (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id TEXT,
+	graph_id TEXT,
+	from_node_index INTEGER,
+	to_node_index INTEGER,
+	requirement TEXT
+);
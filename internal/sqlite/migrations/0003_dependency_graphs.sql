@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS dependency_graphs (
+	graph_id TEXT PRIMARY KEY,
+	project_id TEXT,
+	system TEXT,
+	version TEXT,
+	source TEXT,  -- deps.dev, go.mod
+	fetched_at TEXT
+);
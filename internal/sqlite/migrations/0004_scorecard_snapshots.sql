@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS scorecard_snapshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	project_id TEXT,
+	scorecard_date TEXT,
+	repo_commit TEXT,
+	scorecard_version TEXT,
+	scorecard_commit TEXT,
+	overall_score REAL,
+	fetched_at TEXT,
+	UNIQUE(project_id, scorecard_date, repo_commit),
+	FOREIGN KEY (project_id) REFERENCES project(id)
+);
+
+CREATE TABLE IF NOT EXISTS scorecard_snapshot_checks (
+	snapshot_id INTEGER,
+	name TEXT,
+	score REAL,
+	reason TEXT,
+	FOREIGN KEY (snapshot_id) REFERENCES scorecard_snapshots(id)
+);
+
+-- History starts with the scorecards already stored in project/scorecard_checks.
+INSERT OR IGNORE INTO scorecard_snapshots (
+	project_id, scorecard_date, repo_commit, scorecard_version, scorecard_commit, overall_score, fetched_at
+)
+SELECT id, scorecard_date, scorecard_repo_commit, scorecard_version, scorecard_commit, scorecard_overall_score, fetched_at
+FROM project
+WHERE scorecard_date IS NOT NULL AND scorecard_date != '';
+
+INSERT INTO scorecard_snapshot_checks (snapshot_id, name, score, reason)
+SELECT s.id, c.name, c.score, c.reason
+FROM scorecard_snapshots s
+JOIN project p ON p.id = s.project_id AND p.scorecard_date = s.scorecard_date AND p.scorecard_repo_commit = s.repo_commit
+JOIN scorecard_checks c ON c.project_id = p.id
+WHERE NOT EXISTS (SELECT 1 FROM scorecard_snapshot_checks x WHERE x.snapshot_id = s.id);
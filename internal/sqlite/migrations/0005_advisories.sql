@@ -0,0 +1,43 @@
+CREATE TABLE IF NOT EXISTS advisories (
+	id TEXT PRIMARY KEY,
+	source TEXT,        -- deps.dev, osv
+	title TEXT,
+	url TEXT,
+	aliases TEXT,       -- ';' separated
+	severity TEXT,      -- CRITICAL, HIGH, MEDIUM, LOW, UNKNOWN
+	cvss3_score REAL,   -- -1 when unknown
+	cvss3_vector TEXT,
+	fetched_at TEXT
+);
+
+-- Versions an imported OSV record affects: a single version or a range.
+CREATE TABLE IF NOT EXISTS advisory_affected (
+	advisory_id TEXT,
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	introduced TEXT,
+	fixed TEXT,
+	last_affected TEXT,
+	FOREIGN KEY (advisory_id) REFERENCES advisories(id)
+);
+
+CREATE INDEX IF NOT EXISTS advisory_affected_package ON advisory_affected (system, name);
+
+-- Advisory keys deps.dev reports for a package version.
+CREATE TABLE IF NOT EXISTS package_version_advisories (
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	advisory_id TEXT,
+	PRIMARY KEY (system, name, version, advisory_id)
+);
+
+-- When the advisory keys of a package version were last fetched.
+CREATE TABLE IF NOT EXISTS package_version_advisory_checks (
+	system TEXT,
+	name TEXT,
+	version TEXT,
+	fetched_at TEXT,
+	PRIMARY KEY (system, name, version)
+);
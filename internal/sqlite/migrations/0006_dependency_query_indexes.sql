@@ -0,0 +1,7 @@
+-- Indexes behind ListDependencies: dependencies are joined to their project and
+-- checks by name, filtered by system and relation, and sorted by name.
+CREATE INDEX IF NOT EXISTS dependency_nodes_name ON dependency_nodes (name);
+CREATE INDEX IF NOT EXISTS dependency_nodes_system_relation ON dependency_nodes (system, relation);
+CREATE INDEX IF NOT EXISTS scorecard_checks_project_name ON scorecard_checks (project_id, name);
+CREATE INDEX IF NOT EXISTS project_overall_score ON project (scorecard_overall_score);
+CREATE INDEX IF NOT EXISTS project_stars ON project (stars_count);
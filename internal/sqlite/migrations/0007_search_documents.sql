@@ -0,0 +1,48 @@
+-- Everything GET /search looks through: one document per project and per package
+-- ever stored, kept up to date by triggers. The FTS5 index over it is created by
+-- ensureSearchIndex, since not every SQLite build has FTS5.
+CREATE TABLE IF NOT EXISTS search_documents (
+	id INTEGER PRIMARY KEY,
+	kind TEXT,         -- project, package
+	system TEXT,       -- '' for projects
+	name TEXT,         -- project ID or package name
+	description TEXT,
+	homepage TEXT,
+	UNIQUE(kind, system, name)
+);
+
+CREATE TRIGGER IF NOT EXISTS search_project_insert AFTER INSERT ON project BEGIN
+	INSERT INTO search_documents (kind, system, name, description, homepage)
+	VALUES ('project', '', new.id, COALESCE(new.description, ''), COALESCE(new.homepage, ''))
+	ON CONFLICT(kind, system, name) DO UPDATE SET description = excluded.description, homepage = excluded.homepage;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_project_update AFTER UPDATE OF description, homepage ON project BEGIN
+	INSERT INTO search_documents (kind, system, name, description, homepage)
+	VALUES ('project', '', new.id, COALESCE(new.description, ''), COALESCE(new.homepage, ''))
+	ON CONFLICT(kind, system, name) DO UPDATE SET description = excluded.description, homepage = excluded.homepage;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_package_insert AFTER INSERT ON packages BEGIN
+	INSERT INTO search_documents (kind, system, name, description, homepage)
+	VALUES ('package', COALESCE(new.system, ''), new.name, '', '')
+	ON CONFLICT(kind, system, name) DO NOTHING;
+END;
+
+CREATE TRIGGER IF NOT EXISTS search_dependency_insert AFTER INSERT ON dependency_nodes BEGIN
+	INSERT INTO search_documents (kind, system, name, description, homepage)
+	VALUES ('package', COALESCE(new.system, ''), new.name, '', '')
+	ON CONFLICT(kind, system, name) DO NOTHING;
+END;
+
+INSERT INTO search_documents (kind, system, name, description, homepage)
+SELECT 'project', '', id, COALESCE(description, ''), COALESCE(homepage, '') FROM project WHERE true
+ON CONFLICT(kind, system, name) DO NOTHING;
+
+INSERT INTO search_documents (kind, system, name, description, homepage)
+SELECT 'package', COALESCE(system, ''), name, '', '' FROM packages WHERE name IS NOT NULL
+ON CONFLICT(kind, system, name) DO NOTHING;
+
+INSERT INTO search_documents (kind, system, name, description, homepage)
+SELECT 'package', COALESCE(system, ''), name, '', '' FROM dependency_nodes WHERE name IS NOT NULL
+ON CONFLICT(kind, system, name) DO NOTHING;
@@ -0,0 +1,5 @@
+-- When the refresher last tried to re-fetch an entry. The stale listings order
+-- by it, so entries that keep failing don't hold up the ones behind them.
+ALTER TABLE project ADD COLUMN refresh_attempted_at TEXT;
+ALTER TABLE packages ADD COLUMN refresh_attempted_at TEXT;
+ALTER TABLE dependency_graphs ADD COLUMN refresh_attempted_at TEXT;
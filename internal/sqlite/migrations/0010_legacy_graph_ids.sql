@@ -0,0 +1,28 @@
+-- Before graphs were keyed by version, a graph's nodes and edges were stored with
+-- graph_id set to its project id and no dependency_graphs row. Rename each such
+-- graph after its SELF node, {system}/{name}@{version}, and record it. Its
+-- fetched_at is unknown, so the refresher treats it as stale.
+CREATE TEMP TABLE legacy_graphs AS
+SELECT n.project_id AS old_id, n.system, n.name, n.version,
+	n.system || '/' || n.name || '@' || n.version AS graph_id
+FROM dependency_nodes n
+WHERE n.graph_id = n.project_id AND n.relation = 'SELF'
+	AND n.node_index = (
+		SELECT MIN(s.node_index) FROM dependency_nodes s
+		WHERE s.project_id = n.project_id AND s.graph_id = n.graph_id AND s.relation = 'SELF'
+	);
+
+UPDATE dependency_nodes
+SET project_id = (SELECT name FROM legacy_graphs WHERE old_id = dependency_nodes.project_id),
+	graph_id = (SELECT graph_id FROM legacy_graphs WHERE old_id = dependency_nodes.project_id)
+WHERE graph_id = project_id AND project_id IN (SELECT old_id FROM legacy_graphs);
+
+UPDATE dependency_edges
+SET project_id = (SELECT name FROM legacy_graphs WHERE old_id = dependency_edges.project_id),
+	graph_id = (SELECT graph_id FROM legacy_graphs WHERE old_id = dependency_edges.project_id)
+WHERE graph_id = project_id AND project_id IN (SELECT old_id FROM legacy_graphs);
+
+INSERT OR IGNORE INTO dependency_graphs (graph_id, project_id, system, version, source)
+SELECT graph_id, name, system, version, 'deps.dev' FROM legacy_graphs;
+
+DROP TABLE legacy_graphs;
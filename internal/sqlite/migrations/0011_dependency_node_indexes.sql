@@ -0,0 +1,18 @@
+-- Manually added dependencies used to be stored with their name as node_index.
+-- Number them after the last numbered node of their graph, in the order they were
+-- added, as new ones are.
+CREATE TEMP TABLE named_nodes AS
+SELECT n.id,
+	(SELECT COALESCE(MAX(i.node_index), -1) FROM dependency_nodes i
+		WHERE i.project_id = n.project_id AND i.graph_id = n.graph_id AND typeof(i.node_index) = 'integer')
+	+ (SELECT COUNT(*) FROM dependency_nodes o
+		WHERE o.project_id = n.project_id AND o.graph_id = n.graph_id AND typeof(o.node_index) = 'text' AND o.id <= n.id)
+	AS node_index
+FROM dependency_nodes n
+WHERE typeof(n.node_index) = 'text';
+
+UPDATE dependency_nodes
+SET node_index = (SELECT node_index FROM named_nodes WHERE named_nodes.id = dependency_nodes.id)
+WHERE id IN (SELECT id FROM named_nodes);
+
+DROP TABLE named_nodes;
@@ -0,0 +1,179 @@
+package sqlite
+
+import (
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+)
+
+// Matches are marked with these in the FTS5 output, so the text can be escaped
+// before they become <mark> tags.
+const (
+	matchStart = "\x01"
+	matchEnd   = "\x02"
+)
+
+// searchIndexTriggers keep search_index in step with search_documents.
+var searchIndexTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS search_index_insert AFTER INSERT ON search_documents BEGIN
+		INSERT INTO search_index (rowid, name, description, homepage) VALUES (new.id, new.name, new.description, new.homepage);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS search_index_delete AFTER DELETE ON search_documents BEGIN
+		INSERT INTO search_index (search_index, rowid, name, description, homepage) VALUES ('delete', old.id, old.name, old.description, old.homepage);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS search_index_update AFTER UPDATE ON search_documents BEGIN
+		INSERT INTO search_index (search_index, rowid, name, description, homepage) VALUES ('delete', old.id, old.name, old.description, old.homepage);
+		INSERT INTO search_index (rowid, name, description, homepage) VALUES (new.id, new.name, new.description, new.homepage);
+	END`,
+}
+
+// ftsAvailable reports whether the SQLite library was built with FTS5, which
+// mattn/go-sqlite3 only does with -tags sqlite_fts5.
+func ftsAvailable(db *sql.DB) (bool, error) {
+	var enabled bool
+	if err := db.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("failed to check for FTS5: %v", err)
+	}
+	return enabled, nil
+}
+
+// ensureSearchIndex creates the FTS5 index over search_documents once the schema
+// has it, and fills it when its triggers are new. Without FTS5 it drops the
+// triggers instead, which would otherwise make every insert fail on a database
+// indexed by another build; the next build with FTS5 rebuilds the index.
+func ensureSearchIndex(db *sql.DB) error {
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'search_documents'`).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to look for search_documents: %v", err)
+	}
+	if tables == 0 {
+		return nil
+	}
+
+	enabled, err := ftsAvailable(db)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		for _, trigger := range []string{"search_index_insert", "search_index_delete", "search_index_update"} {
+			if _, err := db.Exec(`DROP TRIGGER IF EXISTS ` + trigger); err != nil {
+				return fmt.Errorf("failed to drop %s: %v", trigger, err)
+			}
+		}
+		return nil
+	}
+
+	var triggers int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'search_index_%'`).Scan(&triggers); err != nil {
+		return fmt.Errorf("failed to look for the search index triggers: %v", err)
+	}
+	if triggers == len(searchIndexTriggers) {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	statements := append([]string{`
+		CREATE VIRTUAL TABLE IF NOT EXISTS search_index USING fts5 (
+			name, description, homepage,
+			content = 'search_documents', content_rowid = 'id',
+			tokenize = 'unicode61 remove_diacritics 2'
+		)`}, searchIndexTriggers...)
+	statements = append(statements, `INSERT INTO search_index (search_index) VALUES ('rebuild')`)
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to create the search index: %v", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Search looks for q.Text in the names, descriptions and homepages of every
+// project and package, best match first. Names weigh the most.
+func Search(db *sql.DB, q store.SearchQuery) ([]store.SearchResult, error) {
+	enabled, err := ftsAvailable(db)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, store.ErrSearchUnavailable
+	}
+	match := matchExpression(q.Text)
+	if match == "" {
+		return nil, fmt.Errorf("nothing to search for")
+	}
+
+	query := `
+		SELECT d.kind, d.system, d.name, d.description, d.homepage,
+			highlight(search_index, 0, ?, ?),
+			snippet(search_index, 1, ?, ?, '…', 24),
+			highlight(search_index, 2, ?, ?),
+			bm25(search_index, 10.0, 1.0, 2.0) AS rank
+		FROM search_index
+		JOIN search_documents d ON d.id = search_index.rowid
+		WHERE search_index MATCH ?`
+	args := []interface{}{matchStart, matchEnd, matchStart, matchEnd, matchStart, matchEnd, match}
+	if q.Kind != "" {
+		query += " AND d.kind = ?"
+		args = append(args, q.Kind)
+	}
+	if q.System != "" {
+		query += " AND d.system = ?"
+		args = append(args, q.System)
+	}
+	query += " ORDER BY rank, d.name"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %v", err)
+	}
+	defer rows.Close()
+
+	results := []store.SearchResult{}
+	for rows.Next() {
+		var r store.SearchResult
+		var name, description, homepage string
+		var rank float64
+		if err := rows.Scan(&r.Kind, &r.System, &r.Name, &r.Description, &r.Homepage, &name, &description, &homepage, &rank); err != nil {
+			return nil, fmt.Errorf("failed to scan search result: %v", err)
+		}
+		// bm25 is negative, the lower the better.
+		r.Score = math.Round(-rank*1000) / 1000
+		r.Highlights = make(map[string]string)
+		for field, text := range map[string]string{"name": name, "description": description, "homepage": homepage} {
+			if strings.Contains(text, matchStart) {
+				r.Highlights[field] = markMatches(text)
+			}
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating search results: %v", err)
+	}
+	return results, nil
+}
+
+// matchExpression turns free text into an FTS5 query: every word has to match,
+// as a prefix, and is quoted so that no character has a special meaning.
+func matchExpression(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+func markMatches(text string) string {
+	text = html.EscapeString(text)
+	return strings.NewReplacer(matchStart, "<mark>", matchEnd, "</mark>").Replace(text)
+}
@@ -0,0 +1,97 @@
+package sqlite_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
+	"errors"
+	"testing"
+	"time"
+)
+
+// Full-text search needs FTS5: go test -tags sqlite_fts5 ./internal/sqlite
+func TestSearch(t *testing.T) {
+	db := newTestDB(t)
+
+	cobra := scorecardProject("2024-01-08T00:00:00Z", "aaa", 5.9, 10)
+	cobra.Description = "A Commander for modern Go CLI interactions"
+	cobra.Homepage = "https://cobra.dev"
+	if err := sqlite.InsertProject(db, cobra); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	express := &models.PackageVersions{PackageKey: models.PackageKey{System: "NPM", Name: "express"}, FetchedAt: time.Now()}
+	if err := sqlite.StorePackageVersions(db, express); err != nil {
+		t.Fatalf("StorePackageVersions: %v", err)
+	}
+	graph := &models.DependencyGraph{Nodes: []models.Node{
+		{VersionKey: models.VersionKey{System: "GO", Name: "github.com/spf13/cobra", Version: "v1.8.0"}, Relation: "SELF"},
+		{VersionKey: models.VersionKey{System: "GO", Name: "github.com/cpuguy83/go-md2man/v2", Version: "v2.0.3"}, Relation: "DIRECT"},
+	}}
+	if err := sqlite.InsertDependencyGraph(db, "GO", "github.com/spf13/cobra", "v1.8.0", store.SourceDepsDev, graph); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+
+	_, err := sqlite.Search(db, store.SearchQuery{Text: "cobra"})
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		t.Skip("SQLite was built without FTS5, run with -tags sqlite_fts5")
+	}
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+
+	names := func(results []store.SearchResult) []string {
+		var names []string
+		for _, r := range results {
+			names = append(names, r.Kind+":"+r.System+":"+r.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		query store.SearchQuery
+		want  []string
+	}{
+		// The name weighs more than the homepage.
+		{store.SearchQuery{Text: "cobra"}, []string{"package:GO:github.com/spf13/cobra", "project::github.com/spf13/cobra"}},
+		{store.SearchQuery{Text: "commander CLI"}, []string{"project::github.com/spf13/cobra"}},
+		{store.SearchQuery{Text: "md2"}, []string{"package:GO:github.com/cpuguy83/go-md2man/v2"}},
+		{store.SearchQuery{Text: "expr", System: "NPM"}, []string{"package:NPM:express"}},
+		{store.SearchQuery{Text: "cobra", Kind: store.SearchProject}, []string{"project::github.com/spf13/cobra"}},
+		{store.SearchQuery{Text: `spf13/cobra" OR`}, nil},
+		{store.SearchQuery{Text: "cobra", Limit: 1}, []string{"package:GO:github.com/spf13/cobra"}},
+	}
+	for _, tt := range tests {
+		results, err := sqlite.Search(db, tt.query)
+		if err != nil {
+			t.Errorf("Search(%+v): %v", tt.query, err)
+			continue
+		}
+		if got := names(results); len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+			t.Errorf("Search(%+v) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	results, _ := sqlite.Search(db, store.SearchQuery{Text: "commander", Kind: store.SearchProject})
+	if len(results) != 1 || results[0].Highlights["description"] != "A <mark>Commander</mark> for modern Go CLI interactions" {
+		t.Errorf("highlights = %+v, want the description with the match marked", results)
+	}
+	if _, ok := results[0].Highlights["name"]; ok {
+		t.Errorf("the name is highlighted without a match: %v", results[0].Highlights)
+	}
+
+	// Updated projects are searched by their new description.
+	cobra.Description = "Framework for <b>command line</b> apps"
+	if err := sqlite.InsertProject(db, cobra); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	if results, _ := sqlite.Search(db, store.SearchQuery{Text: "commander"}); len(results) != 0 {
+		t.Errorf("the old description still matches: %v", names(results))
+	}
+	results, _ = sqlite.Search(db, store.SearchQuery{Text: "framework"})
+	if len(results) != 1 || results[0].Highlights["description"] != "<mark>Framework</mark> for &lt;b&gt;command line&lt;/b&gt; apps" {
+		t.Errorf("Search(framework) = %+v, want the new, escaped description", results)
+	}
+
+	if _, err := sqlite.Search(db, store.SearchQuery{Text: "  "}); err == nil {
+		t.Error("an empty search succeeded")
+	}
+}
@@ -0,0 +1,112 @@
+package sqlite
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+)
+
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// insertSnapshot keeps every distinct scorecard of a project, keyed by its date and
+// the repository commit it was computed on. Re-inserting a known scorecard is a no-op.
+func insertSnapshot(db execer, p *models.Project) error {
+	if p.Scorecard.Date == "" {
+		return nil
+	}
+
+	result, err := db.Exec(`
+		INSERT OR IGNORE INTO scorecard_snapshots (
+			project_id, scorecard_date, repo_commit, scorecard_version, scorecard_commit, overall_score, fetched_at
+		) VALUES (?,?,?,?,?,?,?)`,
+		p.ProjectKey.ID,
+		p.Scorecard.Date,
+		p.Scorecard.Repository.Commit,
+		p.Scorecard.Scorecard.Version,
+		p.Scorecard.Scorecard.Commit,
+		p.Scorecard.OverallScore,
+		formatTime(p.FetchedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert scorecard snapshot: %v", err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil || inserted == 0 {
+		return err
+	}
+	snapshotID, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get scorecard snapshot id: %v", err)
+	}
+
+	for _, check := range p.Scorecard.Checks {
+		_, err := db.Exec(`
+			INSERT INTO scorecard_snapshot_checks (snapshot_id, name, score, reason)
+			VALUES (?,?,?,?)`, snapshotID, check.Name, check.Score, check.Reason)
+		if err != nil {
+			return fmt.Errorf("failed to insert scorecard snapshot check: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetScorecardHistory returns every stored scorecard of a project, oldest first.
+func GetScorecardHistory(db *sql.DB, projectID string) ([]models.ScorecardSnapshot, error) {
+	rows, err := db.Query(`
+		SELECT id, scorecard_date, repo_commit, scorecard_version, overall_score, fetched_at
+		FROM scorecard_snapshots
+		WHERE project_id = ?
+		ORDER BY scorecard_date, id`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scorecard snapshots: %v", err)
+	}
+	defer rows.Close()
+
+	var snapshots []models.ScorecardSnapshot
+	index := make(map[int64]int)
+	for rows.Next() {
+		var id int64
+		var s models.ScorecardSnapshot
+		var fetchedAt sql.NullString
+		if err := rows.Scan(&id, &s.Date, &s.RepositoryCommit, &s.ScorecardVersion, &s.OverallScore, &fetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scorecard snapshot: %v", err)
+		}
+		s.FetchedAt = parseTime(fetchedAt)
+		s.Checks = make(map[string]float64)
+		index[id] = len(snapshots)
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scorecard snapshots: %v", err)
+	}
+	rows.Close()
+
+	checkRows, err := db.Query(`
+		SELECT c.snapshot_id, c.name, c.score
+		FROM scorecard_snapshot_checks c
+		JOIN scorecard_snapshots s ON s.id = c.snapshot_id
+		WHERE s.project_id = ?`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scorecard snapshot checks: %v", err)
+	}
+	defer checkRows.Close()
+
+	for checkRows.Next() {
+		var id int64
+		var name string
+		var score float64
+		if err := checkRows.Scan(&id, &name, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan scorecard snapshot check: %v", err)
+		}
+		if i, ok := index[id]; ok {
+			snapshots[i].Checks[name] = score
+		}
+	}
+	if err := checkRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scorecard snapshot checks: %v", err)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,73 @@
+package sqlite_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/sqlite"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := sqlite.Create(db); err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	return db
+}
+
+func scorecardProject(date, commit string, overall, maintained float64) *models.Project {
+	return &models.Project{
+		ProjectKey: models.ProjectKey{ID: "github.com/spf13/cobra"},
+		Scorecard: models.Scorecard{
+			Date:         date,
+			Repository:   models.Repository{Name: "github.com/spf13/cobra", Commit: commit},
+			OverallScore: overall,
+			Checks:       []models.ScorecardCheck{{Name: "Maintained", Score: maintained}},
+		},
+	}
+}
+
+func TestScorecardHistoryKeepsDistinctSnapshots(t *testing.T) {
+	db := newTestDB(t)
+
+	inserts := []*models.Project{
+		scorecardProject("2024-01-08T00:00:00Z", "aaa", 5.9, 10),
+		scorecardProject("2024-01-08T00:00:00Z", "aaa", 5.9, 10),
+		scorecardProject("2024-01-15T00:00:00Z", "bbb", 4.2, 3),
+	}
+	for _, p := range inserts {
+		if err := sqlite.InsertProject(db, p); err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+	}
+
+	history, err := sqlite.GetScorecardHistory(db, "github.com/spf13/cobra")
+	if err != nil {
+		t.Fatalf("GetScorecardHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(history))
+	}
+	if history[0].OverallScore != 5.9 || history[1].OverallScore != 4.2 {
+		t.Errorf("overall scores = %v, %v; want 5.9, 4.2", history[0].OverallScore, history[1].OverallScore)
+	}
+	if got := history[1].Checks["Maintained"]; got != 3 {
+		t.Errorf("Maintained in the second snapshot = %v, want 3", got)
+	}
+
+	project, err := sqlite.GetProject(db, "github.com/spf13/cobra")
+	if err != nil {
+		t.Fatalf("GetProject: %v", err)
+	}
+	if len(project.Scorecard.Checks) != 1 || project.Scorecard.OverallScore != 4.2 {
+		t.Errorf("project holds %d checks and score %v, want the latest scorecard only", len(project.Scorecard.Checks), project.Scorecard.OverallScore)
+	}
+}
@@ -0,0 +1,167 @@
+package sqlite
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Store implements store.Store on top of the package's functions.
+type Store struct {
+	db *sql.DB
+}
+
+var (
+	_ store.Store    = (*Store)(nil)
+	_ store.Searcher = (*Store)(nil)
+)
+
+func NewStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Open opens the SQLite database file at path without migrating it.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	return NewStore(db), nil
+}
+
+// DB exposes the underlying connection for SQLite-only features.
+func (s *Store) DB() *sql.DB {
+	return s.db
+}
+
+func (s *Store) GetProject(projectID string) (*models.Project, error) {
+	return GetProject(s.db, projectID)
+}
+
+func (s *Store) InsertProject(p *models.Project) error {
+	return InsertProject(s.db, p)
+}
+
+func (s *Store) InsertProjects(projects []*models.Project) error {
+	return InsertProjects(s.db, projects)
+}
+
+func (s *Store) GetScoresByProjectID(projectID string) (map[string]int, error) {
+	return GetScoresByProjectID(s.db, projectID)
+}
+
+func (s *Store) GetScorecardHistory(projectID string) ([]models.ScorecardSnapshot, error) {
+	return GetScorecardHistory(s.db, projectID)
+}
+
+func (s *Store) GetPackageVersions(system, name string) (*models.PackageVersions, error) {
+	return GetPackageVersions(s.db, system, name)
+}
+
+func (s *Store) StorePackageVersions(pv *models.PackageVersions) error {
+	return StorePackageVersions(s.db, pv)
+}
+
+func (s *Store) GetDependencyGraph(system, projectID, version string) (*models.DependencyGraph, error) {
+	return GetDependencyGraph(s.db, system, projectID, version)
+}
+
+func (s *Store) InsertDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error {
+	return InsertDependencyGraph(s.db, system, projectID, version, source, graph)
+}
+
+func (s *Store) ReplaceDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error {
+	return ReplaceDependencyGraph(s.db, system, projectID, version, source, graph)
+}
+
+func (s *Store) DeleteDependencyGraph(system, projectID, version string) error {
+	return DeleteDependencyGraph(s.db, system, projectID, version)
+}
+
+func (s *Store) ListGraphVersions(system, projectID string) ([]string, error) {
+	return ListGraphVersions(s.db, system, projectID)
+}
+
+func (s *Store) AddOrUpdateDependency(projectID string, dep models.Node) error {
+	return AddOrUpdateDependency(s.db, projectID, dep)
+}
+
+func (s *Store) GetDependency(projectID, depName string) (*models.Node, error) {
+	return GetDependency(s.db, projectID, depName)
+}
+
+func (s *Store) DeleteDependency(projectID, depName string) error {
+	return DeleteDependency(s.db, projectID, depName)
+}
+
+func (s *Store) SetVersionProject(key models.VersionKey, projectID string) error {
+	return SetVersionProject(s.db, key, projectID)
+}
+
+func (s *Store) ListDependencies(q store.DependencyQuery) (*store.DependencyPage, error) {
+	return ListDependencies(s.db, q)
+}
+
+func (s *Store) Search(q store.SearchQuery) ([]store.SearchResult, error) {
+	return Search(s.db, q)
+}
+
+func (s *Store) InsertAdvisories(advisories []models.Advisory) error {
+	return InsertAdvisories(s.db, advisories)
+}
+
+func (s *Store) GetAdvisories(ids []string) ([]models.Advisory, error) {
+	return GetAdvisories(s.db, ids)
+}
+
+func (s *Store) ListPackageAdvisories(system, name string) ([]models.Advisory, error) {
+	return ListPackageAdvisories(s.db, system, name)
+}
+
+func (s *Store) SetVersionAdvisories(key models.VersionKey, advisoryIDs []string, fetchedAt time.Time) error {
+	return SetVersionAdvisories(s.db, key, advisoryIDs, fetchedAt)
+}
+
+func (s *Store) GetVersionAdvisories(key models.VersionKey) ([]string, time.Time, error) {
+	return GetVersionAdvisories(s.db, key)
+}
+
+func (s *Store) ListStaleProjects(fetchedBefore time.Time, limit int) ([]string, error) {
+	return ListStaleProjects(s.db, fetchedBefore, limit)
+}
+
+func (s *Store) ListStalePackages(fetchedBefore time.Time, limit int) ([]models.PackageKey, error) {
+	return ListStalePackages(s.db, fetchedBefore, limit)
+}
+
+func (s *Store) ListStaleGraphs(fetchedBefore time.Time, limit int) ([]store.StaleGraph, error) {
+	return ListStaleGraphs(s.db, fetchedBefore, limit)
+}
+
+func (s *Store) MarkProjectAttempt(projectID string, at time.Time) error {
+	return MarkProjectAttempt(s.db, projectID, at)
+}
+
+func (s *Store) MarkPackageAttempt(key models.PackageKey, at time.Time) error {
+	return MarkPackageAttempt(s.db, key, at)
+}
+
+func (s *Store) MarkGraphAttempt(g store.StaleGraph, at time.Time) error {
+	return MarkGraphAttempt(s.db, g, at)
+}
+
+func (s *Store) SchemaVersion() (int, error) {
+	return SchemaVersion(s.db)
+}
+
+func (s *Store) Migrate(opts store.MigrateOptions) ([]store.Migration, error) {
+	return Migrate(s.db, opts)
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
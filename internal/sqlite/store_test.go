@@ -0,0 +1,64 @@
+package sqlite_test
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
+	"codenotary/internal/store/storetest"
+	"path/filepath"
+	"testing"
+)
+
+func TestStoreConformance(t *testing.T) {
+	storetest.Run(t, func(t *testing.T) store.Store {
+		s, err := sqlite.Open(filepath.Join(t.TempDir(), "store.db"))
+		if err != nil {
+			t.Fatalf("opening store: %v", err)
+		}
+		t.Cleanup(func() { s.Close() })
+		if _, err := s.Migrate(store.MigrateOptions{}); err != nil {
+			t.Fatalf("migrating: %v", err)
+		}
+		return s
+	})
+}
+
+func TestReplaceDependencyGraphRollsBack(t *testing.T) {
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	defer s.Close()
+	if _, err := s.Migrate(store.MigrateOptions{}); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	old := &models.DependencyGraph{Nodes: []models.Node{
+		{VersionKey: models.VersionKey{System: "GO", Name: "example.com/app", Version: "v1.0.0"}, Relation: "SELF"},
+		{VersionKey: models.VersionKey{System: "GO", Name: "github.com/spf13/pflag", Version: "v1.0.5"}, Relation: "DIRECT"},
+	}, Edges: []models.Edge{{FromNode: 0, ToNode: 1, Requirement: "v1.0.5"}}}
+	if err := s.InsertDependencyGraph("GO", "example.com/app", "v1.0.0", store.SourceGoMod, old); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+
+	// Fail on the last node of the new graph, after the old one is deleted.
+	if _, err := s.DB().Exec(`CREATE TRIGGER fail_node BEFORE INSERT ON dependency_nodes
+		WHEN new.name = 'example.com/broken' BEGIN SELECT RAISE(ABORT, 'broken node'); END`); err != nil {
+		t.Fatalf("creating trigger: %v", err)
+	}
+	replacement := &models.DependencyGraph{Nodes: append(old.Nodes[:1:1],
+		models.Node{VersionKey: models.VersionKey{System: "GO", Name: "gopkg.in/yaml.v3", Version: "v3.0.1"}, Relation: "DIRECT"},
+		models.Node{VersionKey: models.VersionKey{System: "GO", Name: "example.com/broken", Version: "v0.1.0"}, Relation: "DIRECT"},
+	)}
+	if err := s.ReplaceDependencyGraph("GO", "example.com/app", "v1.0.0", store.SourceGoMod, replacement); err == nil {
+		t.Fatal("ReplaceDependencyGraph succeeded, want the trigger's error")
+	}
+
+	g, err := s.GetDependencyGraph("GO", "example.com/app", "v1.0.0")
+	if err != nil || g == nil {
+		t.Fatalf("GetDependencyGraph = %v, %v; want the old graph", g, err)
+	}
+	if len(g.Nodes) != 2 || g.Nodes[1].VersionKey.Name != "github.com/spf13/pflag" || len(g.Edges) != 1 {
+		t.Errorf("after a failed replace the graph is %+v, want the old one", g)
+	}
+}
@@ -0,0 +1,19 @@
+package sqlite
+
+import (
+	"codenotary/internal/models"
+	"database/sql"
+	"fmt"
+)
+
+// SetVersionProject records the project a package version resolves to.
+func SetVersionProject(db *sql.DB, key models.VersionKey, projectID string) error {
+	_, err := db.Exec(`
+		INSERT INTO package_version_projects (system, name, version, project_id) VALUES (?,?,?,?)
+		ON CONFLICT(system, name, version) DO UPDATE SET project_id=excluded.project_id`,
+		key.System, key.Name, key.Version, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to record the project of %s/%s@%s: %v", key.System, key.Name, key.Version, err)
+	}
+	return nil
+}
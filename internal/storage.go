@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"codenotary/internal/postgres"
+	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
+	"fmt"
+)
+
+// Storage backends accepted by OpenStore.
+const (
+	BackendSQLite   = "sqlite"
+	BackendPostgres = "postgres"
+)
+
+// OpenStore opens the named backend. dsn is a file path for SQLite and a
+// connection string for PostgreSQL; an empty dsn with SQLite means Database.
+// The schema is not migrated.
+func OpenStore(backend, dsn string) (store.Store, error) {
+	switch backend {
+	case "", BackendSQLite:
+		if dsn == "" {
+			dsn = Database
+		}
+		return sqlite.Open(dsn)
+	case BackendPostgres:
+		if dsn == "" {
+			return nil, fmt.Errorf("the postgres backend needs a connection string")
+		}
+		return postgres.Open(dsn)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q, use %s or %s", backend, BackendSQLite, BackendPostgres)
+	}
+}
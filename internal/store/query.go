@@ -0,0 +1,209 @@
+package store
+
+import (
+	"codenotary/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Fields dependencies can be sorted by.
+const (
+	SortName     = "name"
+	SortSystem   = "system"
+	SortVersion  = "version"
+	SortRelation = "relation"
+	SortScore    = "score"
+	SortStars    = "stars"
+	SortLicense  = "license"
+)
+
+var SortFields = []string{SortName, SortSystem, SortVersion, SortRelation, SortScore, SortStars, SortLicense}
+
+// CheckOperators compare a scorecard check score in a CheckFilter.
+var CheckOperators = []string{"<", "<=", ">", ">=", "=", "!="}
+
+// DependencyQuery selects stored dependency nodes. A dependency's project, for
+// its score, license, stars and checks, is the one its version resolves to: a Go
+// module is its own project, other packages are the source repository
+// SetVersionProject recorded for them. Zero fields don't filter.
+type DependencyQuery struct {
+	// Name keeps dependencies whose name contains it.
+	Name      string
+	Systems   []string
+	Relations []string
+	// Licenses are compared with the whole license of the project, ignoring case.
+	Licenses []string
+	MinScore *float64
+	MaxScore *float64
+	// MinOSSFScore compares the ossf_score stored with the node when it was added.
+	MinOSSFScore *float64
+	MinStars     *int
+	MaxStars     *int
+	// Checks all have to hold; dependencies without the check never match.
+	Checks []CheckFilter
+	// Sort orders the result, by name when empty. Ties are broken by the order
+	// the dependencies were stored in.
+	Sort []SortKey
+	// Limit caps the number of dependencies returned; 0 returns all of them.
+	Limit int
+	// Cursor continues a listing after the page that returned it.
+	Cursor string
+}
+
+type CheckFilter struct {
+	Check string
+	Op    string
+	Score float64
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// Dependency is a stored dependency node with the data it can be queried by.
+type Dependency struct {
+	ID        int64
+	GraphID   string
+	ProjectID string
+	Node      models.Node
+	// SourceProjectID is the project the node resolves to, empty when unknown.
+	SourceProjectID string
+	Score           float64 // overall scorecard score of the source project, -1 when unknown
+	License         string
+	Stars           int // -1 when unknown
+}
+
+type DependencyPage struct {
+	Dependencies []Dependency
+	// Total counts every match, on all pages.
+	Total int
+	// NextCursor is empty on the last page.
+	NextCursor string
+}
+
+// Validate reports unknown sort fields and operators, and fills in the default sort.
+func (q *DependencyQuery) Validate() error {
+	if q.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	for _, check := range q.Checks {
+		if check.Check == "" {
+			return fmt.Errorf("check filter without a check name")
+		}
+		if !contains(CheckOperators, check.Op) {
+			return fmt.Errorf("unknown operator %q, use one of %s", check.Op, strings.Join(CheckOperators, " "))
+		}
+	}
+	if len(q.Sort) == 0 {
+		q.Sort = []SortKey{{Field: SortName}}
+	}
+	seen := make(map[string]bool)
+	for _, key := range q.Sort {
+		if !contains(SortFields, key.Field) {
+			return fmt.Errorf("unknown sort field %q, use one of %s", key.Field, strings.Join(SortFields, ", "))
+		}
+		if seen[key.Field] {
+			return fmt.Errorf("sort field %q is given twice", key.Field)
+		}
+		seen[key.Field] = true
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// SortValue returns the value of a sort field of d, as the backends compare it.
+func (d *Dependency) SortValue(field string) interface{} {
+	switch field {
+	case SortSystem:
+		return d.Node.VersionKey.System
+	case SortVersion:
+		return d.Node.VersionKey.Version
+	case SortRelation:
+		return d.Node.Relation
+	case SortScore:
+		return d.Score
+	case SortStars:
+		return int64(d.Stars)
+	case SortLicense:
+		return d.License
+	default:
+		return d.Node.VersionKey.Name
+	}
+}
+
+// cursor is what a page cursor encodes: the sort, so a cursor can't be reused
+// with another one, and the sort values and ID of the page's last dependency.
+type cursor struct {
+	Sort   string        `json:"sort"`
+	Values []interface{} `json:"values"`
+	ID     int64         `json:"id"`
+}
+
+func sortString(keys []SortKey) string {
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = key.Field
+		if key.Desc {
+			fields[i] = "-" + key.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// EncodeCursor returns the cursor of the page ending with last.
+func (q *DependencyQuery) EncodeCursor(last Dependency) string {
+	c := cursor{Sort: sortString(q.Sort), ID: last.ID}
+	for _, key := range q.Sort {
+		c.Values = append(c.Values, last.SortValue(key.Field))
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor returns the sort values and ID q.Cursor continues after, typed
+// like SortValue returns them. It returns nil values without a cursor.
+func (q *DependencyQuery) DecodeCursor() ([]interface{}, int64, error) {
+	if q.Cursor == "" {
+		return nil, 0, nil
+	}
+	invalid := fmt.Errorf("invalid cursor")
+	data, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, 0, invalid
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || len(c.Values) != len(q.Sort) {
+		return nil, 0, invalid
+	}
+	if c.Sort != sortString(q.Sort) {
+		return nil, 0, fmt.Errorf("the cursor belongs to sort=%s", c.Sort)
+	}
+	for i, key := range q.Sort {
+		switch value := c.Values[i].(type) {
+		case float64:
+			if key.Field == SortStars {
+				c.Values[i] = int64(value)
+			} else if key.Field != SortScore {
+				return nil, 0, invalid
+			}
+		case string:
+			if key.Field == SortScore || key.Field == SortStars {
+				return nil, 0, invalid
+			}
+		default:
+			return nil, 0, invalid
+		}
+	}
+	return c.Values, c.ID, nil
+}
@@ -0,0 +1,40 @@
+package store
+
+import "errors"
+
+// Kinds of search results.
+const (
+	SearchProject = "project"
+	SearchPackage = "package"
+)
+
+// ErrSearchUnavailable is returned by Search when the database can't search.
+var ErrSearchUnavailable = errors.New("full-text search needs SQLite built with FTS5 (go build -tags sqlite_fts5)")
+
+type SearchQuery struct {
+	// Text is matched word by word, each word as a prefix.
+	Text string
+	// Kind and System narrow the results; empty means any.
+	Kind   string
+	System string
+	Limit  int
+}
+
+type SearchResult struct {
+	Kind        string
+	System      string // empty for projects
+	Name        string // project ID or package name
+	Description string
+	Homepage    string
+	// Score ranks the results, higher is better.
+	Score float64
+	// Highlights holds the fields that matched, HTML-escaped, with the matches
+	// in <mark> tags; a long description is cut around its matches.
+	Highlights map[string]string
+}
+
+// Searcher is implemented by the stores with a full-text index over every project
+// and package they hold.
+type Searcher interface {
+	Search(q SearchQuery) ([]SearchResult, error)
+}
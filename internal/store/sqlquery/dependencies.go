@@ -0,0 +1,203 @@
+// Package sqlquery holds the SQL the SQLite and PostgreSQL backends share. The
+// backends only differ in how they write placeholders.
+package sqlquery
+
+import (
+	"codenotary/internal/store"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Placeholder returns the placeholder of the nth argument of a query, from 1.
+type Placeholder func(n int) string
+
+// Question writes SQLite placeholders.
+func Question(int) string { return "?" }
+
+// Dollar writes PostgreSQL placeholders.
+func Dollar(n int) string { return fmt.Sprintf("$%d", n) }
+
+// args collects the arguments of a query as their placeholders are written.
+type args struct {
+	values      []interface{}
+	placeholder Placeholder
+}
+
+func (a *args) add(value interface{}) string {
+	a.values = append(a.values, value)
+	return a.placeholder(len(a.values))
+}
+
+// Querier is a *sql.DB or *sql.Tx.
+type Querier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// sourceProject is the project a dependency node resolves to, as
+// deps.Client.GetProjectForPackage resolves it: a Go module path is its own
+// project ID, other packages go through the source repository recorded by
+// SetVersionProject.
+const sourceProject = `COALESCE(vp.project_id, CASE WHEN COALESCE(dn.system, '') IN ('GO', '') THEN dn.name END)`
+
+const dependencyFrom = `
+	FROM dependency_nodes dn
+	LEFT JOIN package_version_projects vp ON vp.system = dn.system AND vp.name = dn.name AND vp.version = dn.version
+	LEFT JOIN project p ON p.id = ` + sourceProject
+
+// dependencyColumns are the expressions behind the sort fields. Columns of the
+// project, which may be missing, fall back to values that sort first.
+var dependencyColumns = map[string]string{
+	store.SortName:     "dn.name",
+	store.SortSystem:   "dn.system",
+	store.SortVersion:  "dn.version",
+	store.SortRelation: "dn.relation",
+	store.SortScore:    "COALESCE(p.scorecard_overall_score, -1)",
+	store.SortStars:    "COALESCE(p.stars_count, -1)",
+	store.SortLicense:  "COALESCE(p.license, '')",
+}
+
+// ListDependencies returns the page of dependency nodes q selects, with the
+// number of matches on every page.
+func ListDependencies(db Querier, placeholder Placeholder, q store.DependencyQuery) (*store.DependencyPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	after, afterID, err := q.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	a := &args{placeholder: placeholder}
+	where := dependencyFilters(q, a)
+	page := &store.DependencyPage{Dependencies: []store.Dependency{}}
+	if err := db.QueryRow(`SELECT COUNT(*)`+dependencyFrom+where, a.values...).Scan(&page.Total); err != nil {
+		return nil, fmt.Errorf("failed to count dependencies: %v", err)
+	}
+
+	// Keyset pagination: rows sorting after the cursor's, on the first sort key
+	// that differs, with the ID breaking ties.
+	if after != nil {
+		var or []string
+		for i := 0; i <= len(q.Sort); i++ {
+			var and []string
+			for j := 0; j < i; j++ {
+				and = append(and, dependencyColumns[q.Sort[j].Field]+" = "+a.add(after[j]))
+			}
+			if i < len(q.Sort) {
+				op := ">"
+				if q.Sort[i].Desc {
+					op = "<"
+				}
+				and = append(and, dependencyColumns[q.Sort[i].Field]+" "+op+" "+a.add(after[i]))
+			} else {
+				and = append(and, "dn.id > "+a.add(afterID))
+			}
+			or = append(or, "("+strings.Join(and, " AND ")+")")
+		}
+		where += " AND (" + strings.Join(or, " OR ") + ")"
+	}
+
+	var order []string
+	for _, key := range q.Sort {
+		column := dependencyColumns[key.Field]
+		if key.Desc {
+			column += " DESC"
+		}
+		order = append(order, column)
+	}
+	query := `
+		SELECT dn.id, COALESCE(dn.graph_id, ''), COALESCE(dn.project_id, ''), dn.system, dn.name, dn.version,
+			COALESCE(dn.bundled, FALSE), dn.relation, dn.errors, COALESCE(p.id, ''), ` + dependencyColumns[store.SortScore] + `,
+			` + dependencyColumns[store.SortLicense] + `, ` + dependencyColumns[store.SortStars] +
+		dependencyFrom + where + `
+		ORDER BY ` + strings.Join(order, ", ") + `, dn.id`
+	if q.Limit > 0 {
+		// One more than asked for tells whether there is a next page.
+		query += " LIMIT " + a.add(q.Limit+1)
+	}
+
+	rows, err := db.Query(query, a.values...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dependencies: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dep store.Dependency
+		var errorsStr sql.NullString
+		if err := rows.Scan(&dep.ID, &dep.GraphID, &dep.ProjectID, &dep.Node.VersionKey.System, &dep.Node.VersionKey.Name,
+			&dep.Node.VersionKey.Version, &dep.Node.Bundled, &dep.Node.Relation, &errorsStr,
+			&dep.SourceProjectID, &dep.Score, &dep.License, &dep.Stars); err != nil {
+			return nil, fmt.Errorf("failed to scan dependency: %v", err)
+		}
+		dep.Node.Errors = []string{}
+		if errorsStr.String != "" {
+			dep.Node.Errors = strings.Split(errorsStr.String, ";")
+		}
+		page.Dependencies = append(page.Dependencies, dep)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dependencies: %v", err)
+	}
+
+	if q.Limit > 0 && len(page.Dependencies) > q.Limit {
+		page.Dependencies = page.Dependencies[:q.Limit]
+		page.NextCursor = q.EncodeCursor(page.Dependencies[q.Limit-1])
+	}
+	return page, nil
+}
+
+// dependencyFilters turns the filters of q into a WHERE clause. Filters on the
+// project leave out dependencies without one.
+func dependencyFilters(q store.DependencyQuery, a *args) string {
+	where := " WHERE 1=1"
+	in := func(column string, values []string) {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = a.add(value)
+		}
+		where += " AND " + column + " IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	if q.Name != "" {
+		where += " AND dn.name LIKE " + a.add("%"+q.Name+"%")
+	}
+	if len(q.Systems) > 0 {
+		in("dn.system", q.Systems)
+	}
+	if len(q.Relations) > 0 {
+		in("dn.relation", q.Relations)
+	}
+	if len(q.Licenses) > 0 {
+		lower := make([]string, len(q.Licenses))
+		for i, license := range q.Licenses {
+			lower[i] = strings.ToLower(license)
+		}
+		in("LOWER(p.license)", lower)
+	}
+	if q.MinScore != nil {
+		where += " AND p.scorecard_overall_score >= " + a.add(*q.MinScore)
+	}
+	if q.MaxScore != nil {
+		where += " AND p.scorecard_overall_score >= 0 AND p.scorecard_overall_score <= " + a.add(*q.MaxScore)
+	}
+	if q.MinOSSFScore != nil {
+		where += " AND dn.ossf_score >= " + a.add(*q.MinOSSFScore)
+	}
+	if q.MinStars != nil {
+		where += " AND p.stars_count >= " + a.add(*q.MinStars)
+	}
+	if q.MaxStars != nil {
+		where += " AND p.stars_count <= " + a.add(*q.MaxStars)
+	}
+	// Checks scorecard couldn't conclude on (-1) match no filter.
+	for _, check := range q.Checks {
+		where += ` AND EXISTS (
+			SELECT 1 FROM scorecard_checks c
+			WHERE c.project_id = ` + sourceProject + ` AND LOWER(c.name) = LOWER(` + a.add(check.Check) + `)
+				AND c.score >= 0 AND c.score ` + check.Op + ` ` + a.add(check.Score) + `)`
+	}
+	return where
+}
@@ -0,0 +1,93 @@
+// Package store defines the storage the application needs, independent of the
+// database behind it. internal/sqlite and internal/postgres implement it.
+package store
+
+import (
+	"codenotary/internal/models"
+	"time"
+)
+
+// Where a stored dependency graph came from.
+const (
+	SourceDepsDev = "deps.dev"
+	SourceGoMod   = "go.mod"
+	SourceSBOM    = "sbom"
+)
+
+// GraphID is the graph_id under which the dependency graph of a package version is stored.
+func GraphID(system, name, version string) string {
+	return system + "/" + name + "@" + version
+}
+
+type StaleGraph struct {
+	System    string
+	ProjectID string
+	Version   string
+}
+
+type Migration struct {
+	Version int
+	Name    string
+}
+
+type MigrateOptions struct {
+	// DryRun reports the pending migrations without applying them.
+	DryRun bool
+	// Target stops after this version; 0 migrates to the latest one.
+	Target int
+}
+
+type Store interface {
+	GetProject(projectID string) (*models.Project, error)
+	InsertProject(p *models.Project) error
+	InsertProjects(projects []*models.Project) error
+	GetScoresByProjectID(projectID string) (map[string]int, error)
+	GetScorecardHistory(projectID string) ([]models.ScorecardSnapshot, error)
+
+	GetPackageVersions(system, name string) (*models.PackageVersions, error)
+	StorePackageVersions(pv *models.PackageVersions) error
+
+	GetDependencyGraph(system, projectID, version string) (*models.DependencyGraph, error)
+	InsertDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error
+	// ReplaceDependencyGraph deletes the stored graph of a version and inserts graph
+	// in one transaction, so a failure leaves the old graph in place.
+	ReplaceDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error
+	DeleteDependencyGraph(system, projectID, version string) error
+	ListGraphVersions(system, projectID string) ([]string, error)
+
+	AddOrUpdateDependency(projectID string, dep models.Node) error
+	GetDependency(projectID, depName string) (*models.Node, error)
+	DeleteDependency(projectID, depName string) error
+	// SetVersionProject records the source repository project deps.dev reports
+	// for a package version, which ListDependencies joins the version's nodes to.
+	SetVersionProject(key models.VersionKey, projectID string) error
+	// ListDependencies returns the page of stored dependency nodes q selects.
+	ListDependencies(q DependencyQuery) (*DependencyPage, error)
+
+	// InsertAdvisories adds or replaces advisories, their affected versions included.
+	InsertAdvisories(advisories []models.Advisory) error
+	// GetAdvisories returns the stored advisories among ids; unknown ids are left out.
+	GetAdvisories(ids []string) ([]models.Advisory, error)
+	// ListPackageAdvisories returns the advisories with affected versions of a package.
+	ListPackageAdvisories(system, name string) ([]models.Advisory, error)
+	// SetVersionAdvisories records the advisory keys deps.dev reported for a version.
+	SetVersionAdvisories(key models.VersionKey, advisoryIDs []string, fetchedAt time.Time) error
+	// GetVersionAdvisories returns them, with a zero time if they were never fetched.
+	GetVersionAdvisories(key models.VersionKey) ([]string, time.Time, error)
+
+	// ListStaleProjects, ListStalePackages and ListStaleGraphs list expired
+	// entries, the ones fetched or tried to refresh longest ago first.
+	ListStaleProjects(fetchedBefore time.Time, limit int) ([]string, error)
+	ListStalePackages(fetchedBefore time.Time, limit int) ([]models.PackageKey, error)
+	ListStaleGraphs(fetchedBefore time.Time, limit int) ([]StaleGraph, error)
+	// MarkProjectAttempt, MarkPackageAttempt and MarkGraphAttempt record when
+	// the refresher tried to re-fetch an entry, which moves it to the back of
+	// the stale listings whether or not the refresh succeeded.
+	MarkProjectAttempt(projectID string, at time.Time) error
+	MarkPackageAttempt(key models.PackageKey, at time.Time) error
+	MarkGraphAttempt(g StaleGraph, at time.Time) error
+
+	SchemaVersion() (int, error)
+	Migrate(opts MigrateOptions) ([]Migration, error)
+	Close() error
+}
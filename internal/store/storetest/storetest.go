@@ -0,0 +1,592 @@
+// Package storetest checks that a store.Store implementation behaves like the
+// others. Each backend's tests call Run with a constructor for empty stores.
+package storetest
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cobra = "github.com/spf13/cobra"
+
+// Run runs every conformance test. newStore must return an empty, migrated
+// store that is closed when the test ends.
+func Run(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s store.Store)
+	}{
+		{"Migrate", testMigrate},
+		{"Projects", testProjects},
+		{"ScorecardHistory", testScorecardHistory},
+		{"PackageVersions", testPackageVersions},
+		{"DependencyGraphs", testDependencyGraphs},
+		{"DependencyCRUD", testDependencyCRUD},
+		{"DependencyQuery", testDependencyQuery},
+		{"DependencyProjects", testDependencyProjects},
+		{"Staleness", testStaleness},
+		{"Advisories", testAdvisories},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+func project(date, commit string, overall, maintained float64) *models.Project {
+	return &models.Project{
+		ProjectKey:  models.ProjectKey{ID: cobra},
+		License:     "Apache-2.0",
+		StarsCount:  37000,
+		Description: "A Commander for modern Go CLI interactions",
+		Scorecard: models.Scorecard{
+			Date:         date,
+			Repository:   models.Repository{Name: cobra, Commit: commit},
+			OverallScore: overall,
+			Checks: []models.ScorecardCheck{
+				{Name: "Maintained", Score: maintained, Reason: "active", Details: []string{"a", "b"}},
+				{Name: "License", Score: 10},
+			},
+		},
+		FetchedAt: time.Now(),
+	}
+}
+
+func testMigrate(t *testing.T, s store.Store) {
+	version, err := s.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if version == 0 {
+		t.Fatal("schema version is 0 after migrating")
+	}
+	applied, err := s.Migrate(store.MigrateOptions{})
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if len(applied) != 0 {
+		t.Errorf("migrating twice applied %v", applied)
+	}
+}
+
+func testProjects(t *testing.T, s store.Store) {
+	if p, err := s.GetProject(cobra); p != nil || err != nil {
+		t.Fatalf("GetProject on an empty store = %v, %v; want nil, nil", p, err)
+	}
+	if err := s.InsertProject(project("2024-01-08T00:00:00Z", "aaa", 5.9, 10)); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+
+	p, err := s.GetProject(cobra)
+	if err != nil || p == nil {
+		t.Fatalf("GetProject = %v, %v", p, err)
+	}
+	if p.License != "Apache-2.0" || p.StarsCount != 37000 || p.Scorecard.OverallScore != 5.9 {
+		t.Errorf("GetProject = %+v, fields were not stored", p)
+	}
+	if len(p.Scorecard.Checks) != 2 {
+		t.Errorf("GetProject returned %d checks, want 2", len(p.Scorecard.Checks))
+	}
+	if p.FetchedAt.IsZero() {
+		t.Error("GetProject lost fetched_at")
+	}
+
+	other := project("2024-01-08T00:00:00Z", "aaa", 7, 8)
+	other.ProjectKey.ID = "github.com/spf13/pflag"
+	if err := s.InsertProjects([]*models.Project{project("2024-01-08T00:00:00Z", "aaa", 5.9, 9), nil, other}); err != nil {
+		t.Fatalf("InsertProjects: %v", err)
+	}
+	scores, err := s.GetScoresByProjectID(cobra)
+	if err != nil {
+		t.Fatalf("GetScoresByProjectID: %v", err)
+	}
+	if len(scores) != 2 || scores["Maintained"] != 9 {
+		t.Errorf("GetScoresByProjectID = %v, want the checks of the last insert", scores)
+	}
+	if p, _ := s.GetProject("github.com/spf13/pflag"); p == nil {
+		t.Error("InsertProjects did not store every project")
+	}
+}
+
+func testScorecardHistory(t *testing.T, s store.Store) {
+	for _, p := range []*models.Project{
+		project("2024-01-08T00:00:00Z", "aaa", 5.9, 10),
+		project("2024-01-08T00:00:00Z", "aaa", 5.9, 10),
+		project("2024-01-15T00:00:00Z", "bbb", 4.2, 3),
+	} {
+		if err := s.InsertProject(p); err != nil {
+			t.Fatalf("InsertProject: %v", err)
+		}
+	}
+
+	history, err := s.GetScorecardHistory(cobra)
+	if err != nil {
+		t.Fatalf("GetScorecardHistory: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("got %d snapshots, want 2", len(history))
+	}
+	if history[0].OverallScore != 5.9 || history[1].OverallScore != 4.2 {
+		t.Errorf("overall scores = %v, %v; want 5.9, 4.2", history[0].OverallScore, history[1].OverallScore)
+	}
+	if got := history[1].Checks["Maintained"]; got != 3 {
+		t.Errorf("Maintained in the second snapshot = %v, want 3", got)
+	}
+}
+
+func testPackageVersions(t *testing.T, s store.Store) {
+	if pv, err := s.GetPackageVersions("GO", cobra); pv != nil || err != nil {
+		t.Fatalf("GetPackageVersions on an empty store = %v, %v; want nil, nil", pv, err)
+	}
+	versions := func(vs ...string) []models.Version {
+		var out []models.Version
+		for i, v := range vs {
+			out = append(out, models.Version{
+				VersionKey: models.VersionKey{System: "GO", Name: cobra, Version: v},
+				IsDefault:  i == len(vs)-1,
+			})
+		}
+		return out
+	}
+	pv := &models.PackageVersions{PackageKey: models.PackageKey{System: "GO", Name: cobra}, Versions: versions("v1.7.0", "v1.8.0")}
+	if err := s.StorePackageVersions(pv); err != nil {
+		t.Fatalf("StorePackageVersions: %v", err)
+	}
+	pv.Versions = versions("v1.7.0", "v1.8.0", "v1.9.0")
+	if err := s.StorePackageVersions(pv); err != nil {
+		t.Fatalf("StorePackageVersions again: %v", err)
+	}
+
+	got, err := s.GetPackageVersions("GO", cobra)
+	if err != nil || got == nil {
+		t.Fatalf("GetPackageVersions = %v, %v", got, err)
+	}
+	if len(got.Versions) != 3 {
+		t.Fatalf("got %d versions, want 3", len(got.Versions))
+	}
+	for _, v := range got.Versions {
+		if v.IsDefault != (v.VersionKey.Version == "v1.9.0") {
+			t.Errorf("%s IsDefault = %v", v.VersionKey.Version, v.IsDefault)
+		}
+	}
+}
+
+func graph() *models.DependencyGraph {
+	node := func(name, version, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "GO", Name: name, Version: version}, Relation: relation, Errors: []string{}}
+	}
+	return &models.DependencyGraph{
+		Nodes: []models.Node{
+			node(cobra, "v1.8.0", "SELF"),
+			node("github.com/spf13/pflag", "v1.0.5", "DIRECT"),
+			node("github.com/inconshreveable/mousetrap", "v1.1.0", "DIRECT"),
+		},
+		Edges: []models.Edge{
+			{FromNode: 0, ToNode: 1, Requirement: "v1.0.5"},
+			{FromNode: 0, ToNode: 2, Requirement: "v1.1.0"},
+		},
+		FetchedAt: time.Now(),
+	}
+}
+
+func testDependencyGraphs(t *testing.T, s store.Store) {
+	if g, err := s.GetDependencyGraph("GO", cobra, "v1.8.0"); g != nil || err != nil {
+		t.Fatalf("GetDependencyGraph on an empty store = %v, %v; want nil, nil", g, err)
+	}
+	if err := s.InsertDependencyGraph("GO", cobra, "v1.8.0", store.SourceDepsDev, graph()); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+	older := graph()
+	older.Nodes[0].VersionKey.Version = "v1.7.0"
+	if err := s.InsertDependencyGraph("GO", cobra, "v1.7.0", store.SourceDepsDev, older); err != nil {
+		t.Fatalf("InsertDependencyGraph v1.7.0: %v", err)
+	}
+
+	g, err := s.GetDependencyGraph("GO", cobra, "v1.8.0")
+	if err != nil || g == nil {
+		t.Fatalf("GetDependencyGraph = %v, %v", g, err)
+	}
+	want := graph()
+	if len(g.Nodes) != len(want.Nodes) || len(g.Edges) != len(want.Edges) {
+		t.Fatalf("got %d nodes and %d edges, want %d and %d", len(g.Nodes), len(g.Edges), len(want.Nodes), len(want.Edges))
+	}
+	for i := range want.Nodes {
+		if g.Nodes[i].VersionKey != want.Nodes[i].VersionKey || g.Nodes[i].Relation != want.Nodes[i].Relation {
+			t.Errorf("node %d = %+v, want %+v", i, g.Nodes[i], want.Nodes[i])
+		}
+	}
+	if g.FetchedAt.IsZero() {
+		t.Error("GetDependencyGraph lost fetched_at")
+	}
+
+	versions, err := s.ListGraphVersions("GO", cobra)
+	if err != nil {
+		t.Fatalf("ListGraphVersions: %v", err)
+	}
+	sort.Strings(versions)
+	if len(versions) != 2 || versions[0] != "v1.7.0" || versions[1] != "v1.8.0" {
+		t.Errorf("ListGraphVersions = %v, want [v1.7.0 v1.8.0]", versions)
+	}
+
+	smaller := graph()
+	smaller.Nodes = smaller.Nodes[:2]
+	smaller.Edges = smaller.Edges[:1]
+	if err := s.ReplaceDependencyGraph("GO", cobra, "v1.8.0", store.SourceGoMod, smaller); err != nil {
+		t.Fatalf("ReplaceDependencyGraph: %v", err)
+	}
+	if g, err := s.GetDependencyGraph("GO", cobra, "v1.8.0"); err != nil || g == nil || len(g.Nodes) != 2 || len(g.Edges) != 1 {
+		t.Fatalf("GetDependencyGraph after ReplaceDependencyGraph = %+v, %v; want 2 nodes and 1 edge", g, err)
+	}
+	if g, _ := s.GetDependencyGraph("GO", cobra, "v1.7.0"); g == nil || len(g.Nodes) != 3 {
+		t.Error("ReplaceDependencyGraph changed another version")
+	}
+
+	if err := s.DeleteDependencyGraph("GO", cobra, "v1.8.0"); err != nil {
+		t.Fatalf("DeleteDependencyGraph: %v", err)
+	}
+	if g, _ := s.GetDependencyGraph("GO", cobra, "v1.8.0"); g != nil {
+		t.Error("graph is still there after DeleteDependencyGraph")
+	}
+	if g, _ := s.GetDependencyGraph("GO", cobra, "v1.7.0"); g == nil {
+		t.Error("DeleteDependencyGraph removed another version")
+	}
+}
+
+func testDependencyCRUD(t *testing.T, s store.Store) {
+	dep := func(name, version string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "GO", Name: name, Version: version}, Relation: "DIRECT"}
+	}
+	for _, d := range []models.Node{dep("github.com/spf13/pflag", "v1.0.5"), dep("gopkg.in/yaml.v3", "v3.0.1"), dep("github.com/spf13/pflag", "v1.0.6")} {
+		if err := s.AddOrUpdateDependency("my-project", d); err != nil {
+			t.Fatalf("AddOrUpdateDependency(%s): %v", d.VersionKey.Name, err)
+		}
+	}
+
+	got, err := s.GetDependency("my-project", "github.com/spf13/pflag")
+	if err != nil || got == nil {
+		t.Fatalf("GetDependency = %v, %v", got, err)
+	}
+	if got.VersionKey.Version != "v1.0.6" {
+		t.Errorf("GetDependency version = %s, want the updated v1.0.6", got.VersionKey.Version)
+	}
+
+	listed, err := s.ListDependencies(store.DependencyQuery{Name: "pflag"})
+	if err != nil {
+		t.Fatalf("ListDependencies: %v", err)
+	}
+	if len(listed.Dependencies) != 1 || listed.Total != 1 {
+		t.Errorf("ListDependencies(pflag) returned %d nodes of %d, want 1", len(listed.Dependencies), listed.Total)
+	}
+
+	if err := s.DeleteDependency("my-project", "github.com/spf13/pflag"); err != nil {
+		t.Fatalf("DeleteDependency: %v", err)
+	}
+	if err := s.DeleteDependency("my-project", "github.com/spf13/pflag"); err == nil {
+		t.Error("deleting a missing dependency did not fail")
+	}
+	if got, _ := s.GetDependency("my-project", "github.com/spf13/pflag"); got != nil {
+		t.Error("dependency is still there after DeleteDependency")
+	}
+
+	// Manual dependencies of a project live apart from its fetched graphs.
+	if err := s.InsertDependencyGraph("GO", cobra, "v1.8.0", store.SourceDepsDev, graph()); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+	if err := s.AddOrUpdateDependency(cobra, dep("github.com/spf13/pflag", "v1.0.6")); err != nil {
+		t.Fatalf("AddOrUpdateDependency(%s): %v", cobra, err)
+	}
+	g, err := s.GetDependencyGraph("GO", cobra, "v1.8.0")
+	if err != nil || g == nil || len(g.Nodes) != 3 || g.Nodes[1].VersionKey.Version != "v1.0.5" {
+		t.Errorf("AddOrUpdateDependency changed a fetched graph: %+v, %v", g, err)
+	}
+	listed, err = s.ListDependencies(store.DependencyQuery{Name: "pflag"})
+	if err != nil || listed.Total != 2 {
+		t.Errorf("ListDependencies(pflag) = %+v, %v; want the graph's node and the manual one", listed, err)
+	}
+}
+
+func testDependencyQuery(t *testing.T, s store.Store) {
+	pflag := project("2024-01-08T00:00:00Z", "aaa", 7, 8)
+	pflag.ProjectKey.ID = "github.com/spf13/pflag"
+	pflag.License = "BSD-3-Clause"
+	pflag.StarsCount = 2000
+	if err := s.InsertProjects([]*models.Project{project("2024-01-08T00:00:00Z", "aaa", 5.9, 10), pflag}); err != nil {
+		t.Fatalf("InsertProjects: %v", err)
+	}
+	older := graph()
+	older.Nodes[0].VersionKey.Version = "v1.7.0"
+	for version, g := range map[string]*models.DependencyGraph{"v1.8.0": graph(), "v1.7.0": older} {
+		if err := s.InsertDependencyGraph("GO", cobra, version, store.SourceDepsDev, g); err != nil {
+			t.Fatalf("InsertDependencyGraph %s: %v", version, err)
+		}
+	}
+
+	minStars, maxScore := 10000, 6.0
+	filters := []struct {
+		name  string
+		query store.DependencyQuery
+		want  int
+	}{
+		{"everything", store.DependencyQuery{}, 6},
+		{"relation", store.DependencyQuery{Relations: []string{"DIRECT"}}, 4},
+		{"system", store.DependencyQuery{Systems: []string{"NPM"}}, 0},
+		{"check", store.DependencyQuery{Checks: []store.CheckFilter{{Check: "maintained", Op: "<", Score: 9}}}, 2},
+		{"checks", store.DependencyQuery{Checks: []store.CheckFilter{{Check: "Maintained", Op: ">=", Score: 8}, {Check: "License", Op: "=", Score: 10}}}, 4},
+		{"license", store.DependencyQuery{Licenses: []string{"apache-2.0"}}, 2},
+		{"stars", store.DependencyQuery{MinStars: &minStars}, 2},
+		{"max score", store.DependencyQuery{MaxScore: &maxScore}, 2},
+	}
+	for _, f := range filters {
+		page, err := s.ListDependencies(f.query)
+		if err != nil {
+			t.Errorf("%s: ListDependencies: %v", f.name, err)
+			continue
+		}
+		if page.Total != f.want || len(page.Dependencies) != f.want || page.NextCursor != "" {
+			t.Errorf("%s: got %d dependencies of %d, cursor %q; want all %d", f.name, len(page.Dependencies), page.Total, page.NextCursor, f.want)
+		}
+	}
+
+	// Best score first, unknown last, then by version; pflag is in both graphs.
+	want := []string{
+		"github.com/spf13/pflag@v1.0.5", "github.com/spf13/pflag@v1.0.5",
+		"github.com/spf13/cobra@v1.7.0", "github.com/spf13/cobra@v1.8.0",
+		"github.com/inconshreveable/mousetrap@v1.1.0", "github.com/inconshreveable/mousetrap@v1.1.0",
+	}
+	query := store.DependencyQuery{Sort: []store.SortKey{{Field: store.SortScore, Desc: true}, {Field: store.SortVersion}}, Limit: 2}
+	var got []string
+	for pages := 0; pages < 4; pages++ {
+		page, err := s.ListDependencies(query)
+		if err != nil {
+			t.Fatalf("ListDependencies page %d: %v", pages+1, err)
+		}
+		if page.Total != 6 {
+			t.Errorf("page %d Total = %d, want 6", pages+1, page.Total)
+		}
+		for _, d := range page.Dependencies {
+			got = append(got, d.Node.VersionKey.Name+"@"+d.Node.VersionKey.Version)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("paginated listing = %v, want %v", got, want)
+	}
+
+	invalid := map[string]store.DependencyQuery{
+		"sort field":     {Sort: []store.SortKey{{Field: "downloads"}}},
+		"operator":       {Checks: []store.CheckFilter{{Check: "Maintained", Op: "~", Score: 1}}},
+		"cursor":         {Cursor: "not a cursor"},
+		"cursor of sort": {Cursor: query.Cursor},
+	}
+	for name, q := range invalid {
+		if _, err := s.ListDependencies(q); err == nil {
+			t.Errorf("%s: ListDependencies succeeded, want an error", name)
+		}
+	}
+}
+
+// testDependencyProjects lists the nodes of another system than Go, which only
+// join the project SetVersionProject recorded for their version.
+func testDependencyProjects(t *testing.T, s store.Store) {
+	express := project("2024-01-08T00:00:00Z", "aaa", 8, 10)
+	express.ProjectKey.ID = "github.com/expressjs/express"
+	express.License = "MIT"
+	if err := s.InsertProjects([]*models.Project{project("2024-01-08T00:00:00Z", "aaa", 5.9, 10), express}); err != nil {
+		t.Fatalf("InsertProjects: %v", err)
+	}
+	node := func(name, version, relation string) models.Node {
+		return models.Node{VersionKey: models.VersionKey{System: "NPM", Name: name, Version: version}, Relation: relation, Errors: []string{}}
+	}
+	g := &models.DependencyGraph{
+		Nodes: []models.Node{node("express", "4.18.2", "SELF"), node(cobra, "1.0.0", "DIRECT")},
+		Edges: []models.Edge{{FromNode: 0, ToNode: 1, Requirement: "^1.0.0"}},
+	}
+	if err := s.InsertDependencyGraph("NPM", "express", "4.18.2", store.SourceDepsDev, g); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+	if err := s.SetVersionProject(models.VersionKey{System: "NPM", Name: "express", Version: "4.18.2"}, "old/express"); err != nil {
+		t.Fatalf("SetVersionProject: %v", err)
+	}
+	if err := s.SetVersionProject(models.VersionKey{System: "NPM", Name: "express", Version: "4.18.2"}, express.ProjectKey.ID); err != nil {
+		t.Fatalf("SetVersionProject again: %v", err)
+	}
+
+	minScore := 7.0
+	page, err := s.ListDependencies(store.DependencyQuery{Systems: []string{"NPM"}, MinScore: &minScore})
+	if err != nil {
+		t.Fatalf("ListDependencies: %v", err)
+	}
+	if len(page.Dependencies) != 1 {
+		t.Fatalf("got %d NPM dependencies scoring 7 or more, want express", len(page.Dependencies))
+	}
+	if d := page.Dependencies[0]; d.Node.VersionKey.Name != "express" || d.SourceProjectID != express.ProjectKey.ID || d.Score != 8 || d.License != "MIT" {
+		t.Errorf("express = %s from %q, score %v, license %q; want %s, 8, MIT", d.Node.VersionKey.Name, d.SourceProjectID, d.Score, d.License, express.ProjectKey.ID)
+	}
+
+	// An NPM package named like a Go module is not that module's project.
+	page, err = s.ListDependencies(store.DependencyQuery{Name: cobra})
+	if err != nil {
+		t.Fatalf("ListDependencies(%s): %v", cobra, err)
+	}
+	if len(page.Dependencies) != 1 || page.Dependencies[0].SourceProjectID != "" || page.Dependencies[0].Score != -1 {
+		t.Errorf("NPM %s = %+v, want it without a project", cobra, page.Dependencies)
+	}
+
+	checks := []store.CheckFilter{{Check: "maintained", Op: ">=", Score: 10}}
+	if page, err := s.ListDependencies(store.DependencyQuery{Checks: checks}); err != nil || page.Total != 1 {
+		t.Errorf("check filter matched %v, %v; want express", page, err)
+	}
+
+	// min_score of GET /dependencies keeps comparing the ossf_score stored with
+	// the node, which is still looked up by name.
+	minOSSF := 5.0
+	if page, err := s.ListDependencies(store.DependencyQuery{MinOSSFScore: &minOSSF}); err != nil || page.Total != 1 || page.Dependencies[0].Node.VersionKey.Name != cobra {
+		t.Errorf("MinOSSFScore matched %+v, %v; want %s", page, err, cobra)
+	}
+}
+
+func testStaleness(t *testing.T, s store.Store) {
+	old := project("2024-01-08T00:00:00Z", "aaa", 5.9, 10)
+	old.FetchedAt = time.Now().Add(-48 * time.Hour)
+	if err := s.InsertProject(old); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	fresh := project("2024-01-08T00:00:00Z", "aaa", 7, 8)
+	fresh.ProjectKey.ID = "github.com/spf13/pflag"
+	if err := s.InsertProject(fresh); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	if err := s.StorePackageVersions(&models.PackageVersions{
+		PackageKey: models.PackageKey{System: "GO", Name: cobra},
+		Versions:   []models.Version{{VersionKey: models.VersionKey{System: "GO", Name: cobra, Version: "v1.8.0"}}},
+		FetchedAt:  old.FetchedAt,
+	}); err != nil {
+		t.Fatalf("StorePackageVersions: %v", err)
+	}
+	stale := graph()
+	stale.FetchedAt = old.FetchedAt
+	if err := s.InsertDependencyGraph("GO", cobra, "v1.8.0", store.SourceDepsDev, stale); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+	if err := s.InsertDependencyGraph("GO", "example.com/local", "local", store.SourceGoMod, stale); err != nil {
+		t.Fatalf("InsertDependencyGraph: %v", err)
+	}
+
+	cutoff := time.Now().Add(-24 * time.Hour)
+	projects, err := s.ListStaleProjects(cutoff, 10)
+	if err != nil {
+		t.Fatalf("ListStaleProjects: %v", err)
+	}
+	if len(projects) != 1 || projects[0] != cobra {
+		t.Errorf("ListStaleProjects = %v, want [%s]", projects, cobra)
+	}
+	packages, err := s.ListStalePackages(cutoff, 10)
+	if err != nil {
+		t.Fatalf("ListStalePackages: %v", err)
+	}
+	if len(packages) != 1 || packages[0].Name != cobra {
+		t.Errorf("ListStalePackages = %v, want %s", packages, cobra)
+	}
+	graphs, err := s.ListStaleGraphs(cutoff, 10)
+	if err != nil {
+		t.Fatalf("ListStaleGraphs: %v", err)
+	}
+	want := store.StaleGraph{System: "GO", ProjectID: cobra, Version: "v1.8.0"}
+	if len(graphs) != 1 || graphs[0] != want {
+		t.Errorf("ListStaleGraphs = %v, want only the deps.dev graph %v", graphs, want)
+	}
+
+	// A project tried after another one expired goes behind it.
+	older := project("2024-01-08T00:00:00Z", "aaa", 7, 8)
+	older.ProjectKey.ID = "github.com/spf13/pflag"
+	older.FetchedAt = time.Now().Add(-72 * time.Hour)
+	if err := s.InsertProject(older); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+	if err := s.MarkProjectAttempt(older.ProjectKey.ID, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("MarkProjectAttempt: %v", err)
+	}
+	if err := s.MarkPackageAttempt(models.PackageKey{System: "GO", Name: cobra}, time.Now()); err != nil {
+		t.Fatalf("MarkPackageAttempt: %v", err)
+	}
+	if err := s.MarkGraphAttempt(want, time.Now()); err != nil {
+		t.Fatalf("MarkGraphAttempt: %v", err)
+	}
+	if projects, err := s.ListStaleProjects(cutoff, 1); err != nil || len(projects) != 1 || projects[0] != cobra {
+		t.Errorf("ListStaleProjects after an attempt = %v, %v; want %s first", projects, err, cobra)
+	}
+	if packages, err := s.ListStalePackages(cutoff, 10); err != nil || len(packages) != 1 {
+		t.Errorf("ListStalePackages after an attempt = %v, %v; want it still stale", packages, err)
+	}
+	if graphs, err := s.ListStaleGraphs(cutoff, 10); err != nil || len(graphs) != 1 {
+		t.Errorf("ListStaleGraphs after an attempt = %v, %v; want it still stale", graphs, err)
+	}
+}
+
+func testAdvisories(t *testing.T, s store.Store) {
+	key := models.VersionKey{System: "NPM", Name: "body-parser", Version: "1.20.1"}
+	if ids, fetchedAt, err := s.GetVersionAdvisories(key); ids != nil || !fetchedAt.IsZero() || err != nil {
+		t.Fatalf("GetVersionAdvisories on an empty store = %v, %v, %v", ids, fetchedAt, err)
+	}
+	if err := s.SetVersionAdvisories(key, []string{"GHSA-2", "GHSA-1"}, time.Now()); err != nil {
+		t.Fatalf("SetVersionAdvisories: %v", err)
+	}
+	if err := s.SetVersionAdvisories(key, []string{"GHSA-1"}, time.Now()); err != nil {
+		t.Fatalf("SetVersionAdvisories again: %v", err)
+	}
+	ids, fetchedAt, err := s.GetVersionAdvisories(key)
+	if err != nil || fetchedAt.IsZero() || len(ids) != 1 || ids[0] != "GHSA-1" {
+		t.Errorf("GetVersionAdvisories = %v, %v, %v; want [GHSA-1]", ids, fetchedAt, err)
+	}
+
+	advisories := []models.Advisory{
+		{
+			AdvisoryKey: models.AdvisoryKey{ID: "GHSA-1"},
+			Title:       "denial of service",
+			Aliases:     []string{"CVE-2024-1", "CVE-2024-2"},
+			CVSS3Score:  7.5,
+			Severity:    "HIGH",
+			Source:      store.SourceDepsDev,
+		},
+		{
+			AdvisoryKey: models.AdvisoryKey{ID: "GHSA-3"},
+			CVSS3Score:  -1,
+			Severity:    "LOW",
+			Source:      "osv",
+			Affected: []models.Affected{
+				{System: "NPM", Name: "cookie", Introduced: "0", Fixed: "0.7.0"},
+				{System: "NPM", Name: "cookie", Version: "1.0.0-rc.1"},
+			},
+		},
+	}
+	if err := s.InsertAdvisories(advisories); err != nil {
+		t.Fatalf("InsertAdvisories: %v", err)
+	}
+	if err := s.InsertAdvisories(advisories[1:]); err != nil {
+		t.Fatalf("InsertAdvisories again: %v", err)
+	}
+
+	got, err := s.GetAdvisories([]string{"GHSA-1", "GHSA-3", "GHSA-unknown"})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("GetAdvisories = %v, %v; want 2 advisories", got, err)
+	}
+	if a := got[0]; a.Title != "denial of service" || len(a.Aliases) != 2 || a.CVSS3Score != 7.5 || a.Severity != "HIGH" {
+		t.Errorf("GHSA-1 = %+v", a)
+	}
+	if len(got[1].Affected) != 2 {
+		t.Errorf("GHSA-3 has %d affected entries, want 2 (re-inserting replaces them)", len(got[1].Affected))
+	}
+
+	cookie, err := s.ListPackageAdvisories("NPM", "cookie")
+	if err != nil || len(cookie) != 1 || cookie[0].AdvisoryKey.ID != "GHSA-3" {
+		t.Errorf("ListPackageAdvisories = %v, %v; want GHSA-3", cookie, err)
+	}
+}
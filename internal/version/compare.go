@@ -0,0 +1,147 @@
+package version
+
+import (
+	"sort"
+	"strings"
+)
+
+// Compare returns -1, 0 or +1 following SemVer 2.0 precedence. Build metadata,
+// including "+incompatible", does not take part in the comparison.
+func Compare(a, b Version) int {
+	if c := compareNumeric(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareNumeric(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareNumeric(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// CompareStrings compares two version strings. Invalid versions sort before
+// every valid version and are ordered lexically among themselves.
+func CompareStrings(a, b string) int {
+	va, errA := Parse(a)
+	vb, errB := Parse(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	if c := Compare(va, vb); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+// Sort orders versions from lowest to highest precedence in place.
+func Sort(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return CompareStrings(versions[i], versions[j]) < 0
+	})
+}
+
+func compareNumeric(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+func compareIdentifier(a, b string) int {
+	numA, numB := allDigits(a), allDigits(b)
+	switch {
+	case numA && numB:
+		return compareNumeric(a, b)
+	case numA:
+		return -1
+	case numB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+type Options struct {
+	// ExcludePrereleases drops prereleases, and with them pseudo-versions.
+	ExcludePrereleases  bool
+	ExcludePseudo       bool
+	ExcludeIncompatible bool
+	// ModulePath, when set, drops versions that don't match its major-version suffix.
+	ModulePath string
+}
+
+// Latest picks the highest version the way the go command resolves "latest":
+// releases win over prereleases, which win over pseudo-versions. It reports
+// false when no version is left after applying opts.
+func Latest(versions []string, opts Options) (string, bool) {
+	var release, prerelease, pseudo []Version
+	for _, s := range versions {
+		v, err := Parse(s)
+		if err != nil {
+			continue
+		}
+		if opts.ExcludeIncompatible && v.IsIncompatible() {
+			continue
+		}
+		if opts.ModulePath != "" && !MatchesModulePath(opts.ModulePath, v) {
+			continue
+		}
+		switch {
+		case v.IsPseudo():
+			if !opts.ExcludePrereleases && !opts.ExcludePseudo {
+				pseudo = append(pseudo, v)
+			}
+		case v.IsPrerelease():
+			if !opts.ExcludePrereleases {
+				prerelease = append(prerelease, v)
+			}
+		default:
+			release = append(release, v)
+		}
+	}
+
+	for _, tier := range [][]Version{release, prerelease, pseudo} {
+		if len(tier) == 0 {
+			continue
+		}
+		max := tier[0]
+		for _, v := range tier[1:] {
+			if Compare(v, max) > 0 {
+				max = v
+			}
+		}
+		return max.Original, true
+	}
+	return "", false
+}
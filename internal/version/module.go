@@ -0,0 +1,42 @@
+package version
+
+import (
+	"strings"
+)
+
+// ModuleMajor returns the major-version suffix of a Go module path: "v2" for
+// "github.com/foo/bar/v2", "v3" for "gopkg.in/yaml.v3" and "" when there is none.
+func ModuleMajor(modulePath string) string {
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		if i := strings.LastIndex(modulePath, ".v"); i >= 0 && allDigits(modulePath[i+2:]) {
+			return modulePath[i+1:]
+		}
+		return ""
+	}
+	i := strings.LastIndex(modulePath, "/")
+	if i < 0 {
+		return ""
+	}
+	last := modulePath[i+1:]
+	if len(last) < 2 || last[0] != 'v' || !isNumeric(last[1:]) || last == "v0" || last == "v1" {
+		return ""
+	}
+	return last
+}
+
+// MatchesModulePath reports whether v is a legal version of the Go module at modulePath.
+// Modules without a suffix only own v0/v1 releases plus "+incompatible" ones,
+// modules with a /vN suffix only own compatible vN releases.
+func MatchesModulePath(modulePath string, v Version) bool {
+	suffix := ModuleMajor(modulePath)
+	if strings.HasPrefix(modulePath, "gopkg.in/") {
+		if suffix == "" {
+			return true
+		}
+		return "v"+v.Major == suffix
+	}
+	if suffix == "" {
+		return v.Major == "0" || v.Major == "1" || v.IsIncompatible()
+	}
+	return "v"+v.Major == suffix && !v.IsIncompatible()
+}
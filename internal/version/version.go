@@ -0,0 +1,125 @@
+package version
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Version is a parsed SemVer 2.0 version. The leading "v" used by Go modules is optional.
+type Version struct {
+	Original   string
+	Major      string
+	Minor      string
+	Patch      string
+	Prerelease []string
+	Build      []string
+}
+
+var pseudoVersionRE = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+var identifierRE = regexp.MustCompile(`^[0-9A-Za-z-]+$`)
+
+// Parse parses v as a semantic version. Like the go command it accepts the
+// shorthands "v1" and "v1.2", which stand for "v1.0.0" and "v1.2.0".
+func Parse(v string) (Version, error) {
+	parsed := Version{Original: v}
+	rest := strings.TrimPrefix(v, "v")
+
+	if i := strings.Index(rest, "+"); i >= 0 {
+		build, err := identifiers(rest[i+1:], false)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid build metadata in %q: %v", v, err)
+		}
+		parsed.Build = build
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "-"); i >= 0 {
+		pre, err := identifiers(rest[i+1:], true)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid prerelease in %q: %v", v, err)
+		}
+		parsed.Prerelease = pre
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q: too many components", v)
+	}
+	if len(parts) < 3 && (parsed.Prerelease != nil || parsed.Build != nil) {
+		return Version{}, fmt.Errorf("invalid version %q: shorthand versions can't carry a prerelease or build", v)
+	}
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	for _, p := range parts {
+		if !isNumeric(p) {
+			return Version{}, fmt.Errorf("invalid version %q: %q is not a number", v, p)
+		}
+	}
+	parsed.Major, parsed.Minor, parsed.Patch = parts[0], parts[1], parts[2]
+	return parsed, nil
+}
+
+func identifiers(s string, prerelease bool) ([]string, error) {
+	ids := strings.Split(s, ".")
+	for _, id := range ids {
+		if !identifierRE.MatchString(id) {
+			return nil, fmt.Errorf("bad identifier %q", id)
+		}
+		if prerelease && allDigits(id) && len(id) > 1 && id[0] == '0' {
+			return nil, fmt.Errorf("numeric identifier %q has a leading zero", id)
+		}
+	}
+	return ids, nil
+}
+
+func isNumeric(s string) bool {
+	return allDigits(s) && (len(s) == 1 || s[0] != '0')
+}
+
+func allDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func IsValid(v string) bool {
+	_, err := Parse(v)
+	return err == nil
+}
+
+func (v Version) IsPrerelease() bool {
+	return len(v.Prerelease) > 0
+}
+
+// IsPseudo reports whether v is a Go pseudo-version such as
+// v0.0.0-20240101123456-abcdefabcdef.
+func (v Version) IsPseudo() bool {
+	return pseudoVersionRE.MatchString(v.Original)
+}
+
+// IsIncompatible reports whether v carries the Go "+incompatible" build tag.
+func (v Version) IsIncompatible() bool {
+	return len(v.Build) == 1 && v.Build[0] == "incompatible"
+}
+
+// Canonical returns the vMAJOR.MINOR.PATCH[-PRERELEASE] form of v, dropping build metadata
+// except for "+incompatible", which is significant to Go.
+func (v Version) Canonical() string {
+	s := "v" + v.Major + "." + v.Minor + "." + v.Patch
+	if v.IsPrerelease() {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.IsIncompatible() {
+		s += "+incompatible"
+	}
+	return s
+}
@@ -0,0 +1,209 @@
+package version_test
+
+import (
+	"codenotary/internal/version"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in         string
+		canonical  string
+		prerelease bool
+		pseudo     bool
+	}{
+		{"v1.2.3", "v1.2.3", false, false},
+		{"1.2.3", "v1.2.3", false, false},
+		{"v1", "v1.0.0", false, false},
+		{"v1.2", "v1.2.0", false, false},
+		{"v1.2.3-rc.1", "v1.2.3-rc.1", true, false},
+		{"v1.2.3+build.5", "v1.2.3", false, false},
+		{"v2.0.0+incompatible", "v2.0.0+incompatible", false, false},
+		{"v0.0.0-20240101123456-abcdefabcdef", "v0.0.0-20240101123456-abcdefabcdef", true, true},
+		{"v1.2.4-0.20240101123456-abcdefabcdef", "v1.2.4-0.20240101123456-abcdefabcdef", true, true},
+		{"v1.2.4-rc.1.0.20240101123456-abcdefabcdef", "v1.2.4-rc.1.0.20240101123456-abcdefabcdef", true, true},
+		{"v3.0.0-20240101123456-abcdefabcdef+incompatible", "v3.0.0-20240101123456-abcdefabcdef+incompatible", true, true},
+	}
+	for _, tt := range tests {
+		v, err := version.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got := v.Canonical(); got != tt.canonical {
+			t.Errorf("Parse(%q).Canonical() = %q, want %q", tt.in, got, tt.canonical)
+		}
+		if v.IsPrerelease() != tt.prerelease || v.IsPseudo() != tt.pseudo {
+			t.Errorf("Parse(%q): prerelease %v, pseudo %v, want %v, %v", tt.in, v.IsPrerelease(), v.IsPseudo(), tt.prerelease, tt.pseudo)
+		}
+	}
+
+	for _, in := range []string{"", "v", "latest", "v1.2.3.4", "v01.2.3", "v1.2.x", "v1-rc.1", "v1.2.3-01", "v1.2.3-rc..1", "v1.2.3+", "v1.2.3+build_5"} {
+		if version.IsValid(in) {
+			t.Errorf("IsValid(%q) = true, want false", in)
+		}
+	}
+}
+
+func TestIncompatible(t *testing.T) {
+	for in, want := range map[string]bool{
+		"v2.0.0+incompatible":       true,
+		"v2.0.0-rc.1+incompatible":  true,
+		"v2.0.0":                    false,
+		"v2.0.0+incompatible.build": false,
+		"v2.0.0+build":              false,
+	} {
+		v, err := version.Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", in, err)
+		}
+		if v.IsIncompatible() != want {
+			t.Errorf("Parse(%q).IsIncompatible() = %v, want %v", in, !want, want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version has a lower precedence than the next one, SemVer 2.0 §11.
+	ordered := []string{
+		"v0.0.0-20230101000000-aaaaaaaaaaaa",
+		"v0.0.0-20240101000000-aaaaaaaaaaaa",
+		"v0.9.0",
+		"v1.0.0-alpha",
+		"v1.0.0-alpha.1",
+		"v1.0.0-alpha.beta",
+		"v1.0.0-beta",
+		"v1.0.0-beta.2",
+		"v1.0.0-beta.11",
+		"v1.0.0-rc.1",
+		"v1.0.0",
+		"v1.0.1-0.20240101123456-abcdefabcdef",
+		"v1.0.1",
+		"v1.2.0",
+		"v1.10.0",
+		"v2.0.0+incompatible",
+		"v10.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := version.CompareStrings(ordered[i], ordered[j]); got != want {
+				t.Errorf("CompareStrings(%q, %q) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+
+	equal := [][2]string{
+		{"v1.2.3+build.1", "v1.2.3+build.2"},
+		{"v1.2.3", "v1.2.3+incompatible"},
+		{"v1.2.3", "1.2.3"},
+		{"v1", "v1.0.0"},
+	}
+	for _, pair := range equal {
+		a, _ := version.Parse(pair[0])
+		b, _ := version.Parse(pair[1])
+		if c := version.Compare(a, b); c != 0 {
+			t.Errorf("Compare(%q, %q) = %d, want 0", pair[0], pair[1], c)
+		}
+	}
+
+	// Invalid versions sort first.
+	if c := version.CompareStrings("latest", "v0.0.1"); c != -1 {
+		t.Errorf("CompareStrings(latest, v0.0.1) = %d, want -1", c)
+	}
+}
+
+func TestSort(t *testing.T) {
+	versions := []string{"v1.10.0", "v1.2.0", "master", "v1.2.0-rc.1", "v0.0.0-20240101123456-abcdefabcdef", "v1.9.0"}
+	version.Sort(versions)
+	want := "master v0.0.0-20240101123456-abcdefabcdef v1.2.0-rc.1 v1.2.0 v1.9.0 v1.10.0"
+	if got := strings.Join(versions, " "); got != want {
+		t.Errorf("Sort = %s, want %s", got, want)
+	}
+}
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name     string
+		versions []string
+		opts     version.Options
+		want     string
+	}{
+		{"highest release", []string{"v1.9.0", "v1.10.0", "v1.2.0"}, version.Options{}, "v1.10.0"},
+		{"release over prerelease", []string{"v1.0.0", "v1.1.0-rc.1"}, version.Options{}, "v1.0.0"},
+		{"prerelease over pseudo", []string{"v1.1.0-rc.1", "v1.1.1-0.20240101123456-abcdefabcdef"}, version.Options{}, "v1.1.0-rc.1"},
+		{"pseudo only", []string{"v0.0.0-20230101000000-aaaaaaaaaaaa", "v0.0.0-20240101000000-bbbbbbbbbbbb"}, version.Options{}, "v0.0.0-20240101000000-bbbbbbbbbbbb"},
+		{"invalid skipped", []string{"latest", "v0.1.0"}, version.Options{}, "v0.1.0"},
+		{"no prereleases", []string{"v1.1.0-rc.1", "v0.0.0-20240101000000-bbbbbbbbbbbb"}, version.Options{ExcludePrereleases: true}, ""},
+		{"no pseudo", []string{"v1.1.0-rc.1", "v1.1.1-0.20240101123456-abcdefabcdef"}, version.Options{ExcludePseudo: true}, "v1.1.0-rc.1"},
+		{"incompatible", []string{"v1.5.0", "v2.1.0+incompatible"}, version.Options{}, "v2.1.0+incompatible"},
+		{"no incompatible", []string{"v1.5.0", "v2.1.0+incompatible"}, version.Options{ExcludeIncompatible: true}, "v1.5.0"},
+		{"module v1", []string{"v1.5.0", "v2.1.0"}, version.Options{ModulePath: "github.com/foo/bar"}, "v1.5.0"},
+		{"module v2", []string{"v1.5.0", "v2.1.0", "v3.0.0+incompatible", "v2.2.0+incompatible"}, version.Options{ModulePath: "github.com/foo/bar/v2"}, "v2.1.0"},
+		{"gopkg.in", []string{"v2.4.0", "v3.0.1"}, version.Options{ModulePath: "gopkg.in/yaml.v3"}, "v3.0.1"},
+		{"nothing left", []string{"v2.1.0"}, version.Options{ModulePath: "github.com/foo/bar/v3"}, ""},
+		{"empty", nil, version.Options{}, ""},
+	}
+	for _, tt := range tests {
+		got, ok := version.Latest(tt.versions, tt.opts)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("%s: Latest = %q, %v, want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestModuleMajor(t *testing.T) {
+	tests := map[string]string{
+		"github.com/foo/bar":     "",
+		"github.com/foo/bar/v2":  "v2",
+		"github.com/foo/bar/v10": "v10",
+		"github.com/foo/bar/v1":  "",
+		"github.com/foo/bar/v0":  "",
+		"github.com/foo/bar/v02": "",
+		"github.com/foo/v2/bar":  "",
+		"github.com/foo/bar/vv2": "",
+		"gopkg.in/yaml.v3":       "v3",
+		"gopkg.in/yaml.v1":       "v1",
+		"gopkg.in/check.v1/sub":  "",
+		"stdlib":                 "",
+	}
+	for path, want := range tests {
+		if got := version.ModuleMajor(path); got != want {
+			t.Errorf("ModuleMajor(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestMatchesModulePath(t *testing.T) {
+	tests := []struct {
+		path, version string
+		want          bool
+	}{
+		{"github.com/foo/bar", "v0.3.0", true},
+		{"github.com/foo/bar", "v1.3.0", true},
+		{"github.com/foo/bar", "v2.0.0", false},
+		{"github.com/foo/bar", "v2.0.0+incompatible", true},
+		{"github.com/foo/bar/v2", "v2.0.0", true},
+		{"github.com/foo/bar/v2", "v2.0.0+incompatible", false},
+		{"github.com/foo/bar/v2", "v1.0.0", false},
+		{"github.com/foo/bar/v2", "v3.0.0", false},
+		{"gopkg.in/yaml.v3", "v3.0.1", true},
+		{"gopkg.in/yaml.v3", "v2.4.0", false},
+	}
+	for _, tt := range tests {
+		v, err := version.Parse(tt.version)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.version, err)
+		}
+		if got := version.MatchesModulePath(tt.path, v); got != tt.want {
+			t.Errorf("MatchesModulePath(%q, %q) = %v, want %v", tt.path, tt.version, got, tt.want)
+		}
+	}
+}
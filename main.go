@@ -2,42 +2,151 @@ package main
 
 import (
 	"codenotary/internal"
+	"codenotary/internal/config"
 	"codenotary/internal/deps"
-	"codenotary/internal/sqlite"
-	"database/sql"
+	"codenotary/internal/store"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
-	"net/http"
-
-	_ "github.com/mattn/go-sqlite3"
+	"log/slog"
+	"os"
+	"strings"
 )
 
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "run the HTTP API (the default without a command)", runServe},
+	{"scan", "scan a package, a go.mod or an SBOM and store its dependency graph", runScan},
+	{"graph", "print the dependency graph of a package", runGraph},
+	{"export", "write an SBOM of a package", runExport},
+	{"check", "evaluate a policy and exit non-zero on violations", runCheckCommand},
+	{"vulns", "list the dependencies affected by security advisories, or import OSV records", runVulns},
+	{"refresh", "re-fetch stale projects, packages and graphs", runRefresh},
+	{"db", "inspect and migrate the database schema", runDB},
+}
+
+// exitCode is returned by commands that have already reported why they failed.
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
 func main() {
-	var err error
-	internal.Db, err = sql.Open("sqlite3", internal.Database)
-	if err != nil {
-		log.Fatalf("Failed it all! %v", err)
+	args := os.Args[1:]
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	// migrate predates the db command.
+	if name == "migrate" {
+		name, args = "db", append([]string{"migrate"}, args...)
+	}
+	if name == "help" {
+		usage()
+		return
 	}
-	err = sqlite.Create(internal.Db)
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(args)
+		var code exitCode
+		switch {
+		case err == nil, errors.Is(err, flag.ErrHelp):
+		case errors.As(err, &code):
+			os.Exit(int(code))
+		default:
+			fmt.Fprintf(os.Stderr, "codenotary %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: codenotary [command] [flags]\n\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun codenotary {command} -h for its flags.")
+}
+
+// loadConfig registers -config and the flags of groups on flags, over the defaults,
+// the configuration file and the environment. The configuration is complete once
+// flags are parsed; openStore and openClient validate it.
+func loadConfig(flags *flag.FlagSet, args []string, groups int) (*config.Config, error) {
+	cfg, err := config.Load(flags, args, groups, os.Getenv)
 	if err != nil {
-		fmt.Println(err)
+		return nil, fmt.Errorf("invalid configuration: %v", err)
 	}
-	internal.Client = deps.NewClient(internal.Db)
-	mux := http.NewServeMux()
+	return cfg, nil
+}
 
-	internal.Client.GetProject("github.com/cli/cli")
-	mux.HandleFunc("/dependency/", HandleGetDependencies)
+// openStore validates cfg, applies its log level and opens the store without
+// migrating it.
+func openStore(cfg *config.Config) (store.Store, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%v", err)
+	}
+	var level slog.Level
+	level.UnmarshalText([]byte(cfg.LogLevel))
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
 
-	mux.HandleFunc("/dependency/add", HandleAddOrUpdateDependency) // POST
-	mux.HandleFunc("/dependency/get/", HandleGetDependency)        // GET /dependency/get/{projectName}/{depName}
-	mux.HandleFunc("/dependency/delete/", HandleDeleteDependency)  // DELETE /dependency/delete/{projectName}/{depName}
-	mux.HandleFunc("/dependencies", HandleListDependencies)        // GET /dependencies?name=xyz&min_score=50
+	st, err := internal.OpenStore(cfg.Store.Backend, cfg.Store.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open the %s store: %v", cfg.Store.Backend, err)
+	}
+	return st, nil
+}
 
-	port := "8080"
-	log.Printf("Server is running on port %s", port)
-	err = http.ListenAndServe(":"+port, mux)
+// openClient opens and migrates the store and points internal.Store and
+// internal.Client at it. The caller closes internal.Store.
+func openClient(cfg *config.Config) error {
+	st, err := openStore(cfg)
 	if err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+		return err
+	}
+	if _, err := st.Migrate(store.MigrateOptions{}); err != nil {
+		st.Close()
+		return fmt.Errorf("failed to migrate the database: %v", err)
 	}
+	// Validate has checked the mode.
+	cassetteMode, _ := deps.ParseCassetteMode(cfg.DepsDev.CassetteMode)
+	internal.Store = st
+	internal.Client = deps.NewClient(st,
+		deps.WithBaseURL(cfg.DepsDev.URL),
+		deps.WithTimeout(cfg.DepsDev.Timeout),
+		deps.WithMaxConcurrency(cfg.DepsDev.MaxConcurrency),
+		deps.WithCassette(cfg.DepsDev.CassetteDir, cassetteMode),
+		deps.WithTTLs(cfg.TTLs()))
+	return nil
+}
 
+// packageArg splits a name[@version] command line argument like the API paths do:
+// "NPM/express@4.18.2", or "express" with system "NPM". Names without a known
+// system prefix are Go modules.
+func packageArg(system, arg string) (string, string, string, error) {
+	if system != "" {
+		normalized, ok := deps.NormalizeSystem(system)
+		if !ok {
+			return "", "", "", fmt.Errorf("unknown system %q", system)
+		}
+		arg = normalized + "/" + arg
+	}
+	system, name := parseSystemAndName(arg)
+	if name == "" {
+		return "", "", "", fmt.Errorf("invalid package name %q", arg)
+	}
+	name, version := splitVersion(name)
+	return system, name, version, nil
 }
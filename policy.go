@@ -0,0 +1,110 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/policy"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type PolicyViolation struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+type PolicyDependency struct {
+	Index      int               `json:"index"`
+	System     string            `json:"system"`
+	Name       string            `json:"name"`
+	Version    string            `json:"version"`
+	Relation   string            `json:"relation"`
+	Violations []PolicyViolation `json:"violations"`
+}
+
+type PolicyResponse struct {
+	ProjectName  string             `json:"project_name"`
+	System       string             `json:"system"`
+	Version      string             `json:"version"`
+	Passed       bool               `json:"passed"`
+	Evaluated    int                `json:"evaluated"`
+	Errors       int                `json:"errors"`
+	Warnings     int                `json:"warnings"`
+	Dependencies []PolicyDependency `json:"dependencies"`
+}
+
+// HandleEvaluatePolicy serves POST /policy/evaluate/{name}[@version]. A policy in
+// the request body (JSON or YAML) takes precedence over the one configured with
+// CODENOTARY_POLICY.
+func HandleEvaluatePolicy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	system, projectName := parseSystemAndName(strings.TrimPrefix(r.URL.Path, "/policy/evaluate/"))
+	if projectName == "" {
+		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
+		return
+	}
+	projectName, pinnedVersion := splitVersion(projectName)
+
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxUploadSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	rules := internal.Policy
+	if strings.TrimSpace(string(body)) != "" {
+		rules, err = policy.Parse(body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid policy: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+	if rules == nil {
+		http.Error(w, "No policy configured, send one as the request body or set CODENOTARY_POLICY", http.StatusBadRequest)
+		return
+	}
+
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies: %v", err), http.StatusBadGateway)
+		return
+	}
+	projects := nodeProjects(dependencyGraph)
+	result := rules.Evaluate(policy.Input{Graph: dependencyGraph, Projects: projects, Checks: nodeChecks(projects)})
+	response := PolicyResponse{
+		ProjectName:  projectName,
+		System:       system,
+		Version:      selfVersion(dependencyGraph),
+		Passed:       result.Passed,
+		Evaluated:    result.Evaluated,
+		Errors:       result.Errors,
+		Warnings:     result.Warnings,
+		Dependencies: []PolicyDependency{},
+	}
+	for _, dependency := range result.Dependencies {
+		entry := PolicyDependency{
+			Index:      dependency.Index,
+			System:     dependency.Node.VersionKey.System,
+			Name:       dependency.Node.VersionKey.Name,
+			Version:    dependency.Node.VersionKey.Version,
+			Relation:   dependency.Node.Relation,
+			Violations: []PolicyViolation{},
+		}
+		for _, violation := range dependency.Violations {
+			entry.Violations = append(entry.Violations, PolicyViolation(violation))
+		}
+		response.Dependencies = append(response.Dependencies, entry)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/profile"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// profileQuery is the ?profile= of /dependencies and /dependency/: the scoring
+// profile to rank dependencies by, and the range of profile scores to keep.
+type profileQuery struct {
+	name    string
+	profile *profile.Profile
+	min     *float64
+	max     *float64
+}
+
+// parseProfileQuery reads profile, min_profile_score and max_profile_score. It
+// returns nil without a profile.
+func parseProfileQuery(query url.Values) (*profileQuery, error) {
+	name := query.Get("profile")
+	if name == "" {
+		if query.Get("min_profile_score") != "" || query.Get("max_profile_score") != "" {
+			return nil, fmt.Errorf("min_profile_score and max_profile_score need a profile")
+		}
+		return nil, nil
+	}
+	p := internal.Profiles.Get(name)
+	if p == nil {
+		if internal.Profiles == nil {
+			return nil, fmt.Errorf("unknown profile %q, no profiles are configured", name)
+		}
+		return nil, fmt.Errorf("unknown profile %q, use one of %s", name, strings.Join(internal.Profiles.Names(), ", "))
+	}
+
+	q := &profileQuery{name: name, profile: p}
+	for param, bound := range map[string]**float64{"min_profile_score": &q.min, "max_profile_score": &q.max} {
+		value := query.Get(param)
+		if value == "" {
+			continue
+		}
+		score, err := strconv.ParseFloat(value, 64)
+		if err != nil || score < 0 || score > 10 {
+			return nil, fmt.Errorf("invalid %s %q, use a score between 0 and 10", param, value)
+		}
+		*bound = &score
+	}
+	return q, nil
+}
+
+// keep reports whether a profile score is in range. Projects without a profile
+// score only pass when no range is given.
+func (q *profileQuery) keep(score float64) bool {
+	if q.min == nil && q.max == nil {
+		return true
+	}
+	if score < 0 {
+		return false
+	}
+	return (q.min == nil || score >= *q.min) && (q.max == nil || score <= *q.max)
+}
+
+// score returns the profile score of a stored project, -1 if it has no checks.
+func (q *profileQuery) score(projectID string) float64 {
+	checks, err := internal.Store.GetScoresByProjectID(projectID)
+	if err != nil {
+		slog.Error("fetching scores", "project", projectID, "err", err)
+		return -1
+	}
+	return q.profile.Score(checks)
+}
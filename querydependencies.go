@@ -0,0 +1,220 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"codenotary/internal/store"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Page sizes of GET /dependencies/query.
+const (
+	defaultQueryLimit = 50
+	maxQueryLimit     = 500
+)
+
+type QueriedDependency struct {
+	GraphID     string   `json:"graph_id"`
+	ProjectName string   `json:"project_name"`
+	System      string   `json:"system"`
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Relation    string   `json:"relation"`
+	Bundled     bool     `json:"bundled"`
+	Errors      []string `json:"errors"`
+	// SourceProject is the project the dependency's score, license and stars
+	// come from, empty when unknown.
+	SourceProject string  `json:"source_project"`
+	Score         float64 `json:"score"`
+	License       string  `json:"license"`
+	Stars         int     `json:"stars"`
+}
+
+type DependencyQueryResponse struct {
+	Total        int                 `json:"total"`
+	Count        int                 `json:"count"`
+	NextCursor   string              `json:"next_cursor,omitempty"`
+	Dependencies []QueriedDependency `json:"dependencies"`
+}
+
+// HandleQueryDependencies serves GET /dependencies/query, a page of the stored
+// dependency nodes matching the filters of parseDependencyQuery.
+func HandleQueryDependencies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := parseDependencyQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := q.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, _, err := q.DecodeCursor(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := internal.Store.ListDependencies(q)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error listing dependencies: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := DependencyQueryResponse{
+		Total:        page.Total,
+		Count:        len(page.Dependencies),
+		NextCursor:   page.NextCursor,
+		Dependencies: []QueriedDependency{},
+	}
+	for _, d := range page.Dependencies {
+		response.Dependencies = append(response.Dependencies, QueriedDependency{
+			GraphID:       d.GraphID,
+			ProjectName:   d.ProjectID,
+			System:        d.Node.VersionKey.System,
+			Name:          d.Node.VersionKey.Name,
+			Version:       d.Node.VersionKey.Version,
+			Relation:      d.Node.Relation,
+			Bundled:       d.Node.Bundled,
+			Errors:        d.Node.Errors,
+			SourceProject: d.SourceProjectID,
+			Score:         d.Score,
+			License:       d.License,
+			Stars:         d.Stars,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+// parseDependencyQuery reads the filters of GET /dependencies/query:
+//
+//	name=pflag                    name contains
+//	system=GO,NPM                 ecosystems
+//	relation=DIRECT               SELF, DIRECT or INDIRECT
+//	license=MIT,Apache-2.0        project licenses
+//	min_score=5&max_score=8       overall OpenSSF score
+//	min_stars=100&max_stars=1000  stars of the project
+//	check.Maintained<5            a scorecard check (<, <=, >, >=, =, !=), repeatable
+//	sort=-score,name              sort fields, - for descending
+//	limit=50&cursor=...           page size and the next_cursor of the previous page
+func parseDependencyQuery(query url.Values) (store.DependencyQuery, error) {
+	q := store.DependencyQuery{Name: query.Get("name"), Limit: defaultQueryLimit, Cursor: query.Get("cursor")}
+
+	for _, system := range splitList(query.Get("system")) {
+		normalized, ok := deps.NormalizeSystem(system)
+		if !ok {
+			return q, fmt.Errorf("unknown system %q, use one of %s", system, strings.Join(deps.Systems, ", "))
+		}
+		q.Systems = append(q.Systems, normalized)
+	}
+	for _, relation := range splitList(query.Get("relation")) {
+		relation = strings.ToUpper(relation)
+		if relation != "SELF" && relation != "DIRECT" && relation != "INDIRECT" {
+			return q, fmt.Errorf("unknown relation %q, use SELF, DIRECT or INDIRECT", relation)
+		}
+		q.Relations = append(q.Relations, relation)
+	}
+	q.Licenses = splitList(query.Get("license"))
+
+	for name, bound := range map[string]**float64{"min_score": &q.MinScore, "max_score": &q.MaxScore} {
+		if value := query.Get(name); value != "" {
+			score, err := strconv.ParseFloat(value, 64)
+			if err != nil || score < 0 || score > 10 {
+				return q, fmt.Errorf("invalid %s %q, use a score between 0 and 10", name, value)
+			}
+			*bound = &score
+		}
+	}
+	for name, bound := range map[string]**int{"min_stars": &q.MinStars, "max_stars": &q.MaxStars} {
+		if value := query.Get(name); value != "" {
+			stars, err := strconv.Atoi(value)
+			if err != nil || stars < 0 {
+				return q, fmt.Errorf("invalid %s %q", name, value)
+			}
+			*bound = &stars
+		}
+	}
+
+	// "check.Maintained<5" arrives as a key without a value, while
+	// "check.Maintained>=5" is split at its "=".
+	for key, values := range query {
+		if !strings.HasPrefix(key, "check.") {
+			continue
+		}
+		for _, value := range values {
+			expression := key
+			if value != "" {
+				expression += "=" + value
+			}
+			check, err := parseCheckFilter(strings.TrimPrefix(expression, "check."))
+			if err != nil {
+				return q, err
+			}
+			q.Checks = append(q.Checks, check)
+		}
+	}
+	// Keep the query text stable, whatever the map order.
+	sort.Slice(q.Checks, func(i, j int) bool { return q.Checks[i].Check < q.Checks[j].Check })
+
+	for _, field := range splitList(query.Get("sort")) {
+		key := store.SortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		q.Sort = append(q.Sort, key)
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxQueryLimit {
+			return q, fmt.Errorf("invalid limit %q, use 1 to %d", value, maxQueryLimit)
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
+
+// parseCheckFilter parses "Maintained<5".
+func parseCheckFilter(expression string) (store.CheckFilter, error) {
+	i := strings.IndexAny(expression, "<>=!")
+	if i <= 0 {
+		return store.CheckFilter{}, fmt.Errorf("invalid check filter %q, use check.Name<5", "check."+expression)
+	}
+	check := store.CheckFilter{Check: expression[:i]}
+	rest := expression[i:]
+	for _, op := range []string{"<=", ">=", "!=", "<", ">", "="} {
+		if strings.HasPrefix(rest, op) {
+			check.Op = op
+			break
+		}
+	}
+	score, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(rest, check.Op)), 64)
+	if check.Op == "" || err != nil {
+		return store.CheckFilter{}, fmt.Errorf("invalid check filter %q, use check.Name<5", "check."+expression)
+	}
+	check.Score = score
+	return check, nil
+}
+
+// splitList splits a comma separated parameter, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"flag"
+	"fmt"
+	"log/slog"
+)
+
+// runRefresh implements `codenotary refresh`: one pass of the background refresher,
+// or a forced re-fetch of the named packages.
+func runRefresh(args []string) error {
+	flags := flag.NewFlagSet("refresh", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary refresh [flags] [name[@version] ...]")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient)
+	if err != nil {
+		return err
+	}
+	systemFlag := flags.String("system", "", "ecosystem of the named packages (GO, NPM, PYPI, ...), GO by default")
+	limit := flags.Int("limit", 50, "how many stale entries of each kind to refresh without named packages")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return fmt.Errorf("limit must be positive")
+	}
+
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	if flags.NArg() == 0 {
+		stats, err := internal.Client.RefreshStale(*limit)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("refreshed %d projects, %d packages and %d graphs, %d failed\n", stats.Projects, stats.Packages, stats.Graphs, stats.Failed)
+		if stats.Failed > 0 {
+			return exitCode(1)
+		}
+		return nil
+	}
+
+	failed := 0
+	for _, arg := range flags.Args() {
+		if err := refreshPackage(*systemFlag, arg); err != nil {
+			slog.Error("refreshing package", "package", arg, "err", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return exitCode(1)
+	}
+	return nil
+}
+
+// refreshPackage re-fetches a package's versions, the graph of the given or the
+// latest version and the scorecard of every project in it, fresh or not.
+func refreshPackage(system, arg string) error {
+	system, name, version, err := packageArg(system, arg)
+	if err != nil {
+		return err
+	}
+	if err := internal.Client.RefreshPackage(system, name); err != nil {
+		return err
+	}
+	if version == "" {
+		latest, err := internal.Client.GetDependencies(system, name)
+		if err != nil {
+			return err
+		}
+		version = selfVersion(latest)
+	}
+	if err := internal.Client.RefreshGraph(system, name, version); err != nil {
+		return err
+	}
+	dependencyGraph, err := internal.Client.GetDependenciesAtVersion(system, name, version)
+	if err != nil {
+		return err
+	}
+
+	refreshed, failed := 0, 0
+	for _, project := range internal.Client.GetNodeProjects(dependencyGraph) {
+		if project == nil {
+			continue
+		}
+		if err := internal.Client.RefreshProject(project.ProjectKey.ID); err != nil {
+			slog.Error("refreshing project", "project", project.ProjectKey.ID, "err", err)
+			failed++
+			continue
+		}
+		refreshed++
+	}
+	fmt.Printf("refreshed %s/%s@%s with %d nodes and %d projects, %d failed\n", system, name, version, len(dependencyGraph.Nodes), refreshed, failed)
+	return nil
+}
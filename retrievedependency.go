@@ -2,13 +2,15 @@ package main
 
 import (
 	"codenotary/internal"
+	"codenotary/internal/deps"
 	"codenotary/internal/models"
-	"codenotary/internal/sqlite"
+	"codenotary/internal/version"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 	"net/http"
-	"net/url"
+	"sort"
 	"strings"
+	"time"
 )
 
 func HandleGetDependencies(w http.ResponseWriter, r *http.Request) {
@@ -21,27 +23,22 @@ func HandleGetDependencies(w http.ResponseWriter, r *http.Request) {
 	encodedProjectName := strings.TrimPrefix(r.URL.Path, "/dependency/")
 
 	
-	projectName, err := url.QueryUnescape(encodedProjectName)
-	if err != nil || projectName == "" {
+	system, projectName := parseSystemAndName(encodedProjectName)
+	if projectName == "" {
 		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
 		return
 	}
 
-	fmt.Printf("Received GET /dependency for project: %s\n", projectName)
+	projectName, pinnedVersion := splitVersion(projectName)
 
-	
-	project, err := internal.Client.GetProject(projectName)
+	profileQuery, err := parseProfileQuery(r.URL.Query())
 	if err != nil {
-		
-	}
-	err = sqlite.InsertProject(internal.Db, project)
-	if err != nil {
-		
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	
-	fmt.Print("Fetching dependency graph...")
-	dependencyGraph, err := internal.Client.GetDependencies(projectName)
+	slog.Debug("fetching dependency graph", "system", system, "name", projectName, "version", pinnedVersion)
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
 	if err != nil {
 		errorMessage := "Failed to fetch dependencies. Please check your internet connection or ensure the project exists in the database."
 		jsonError := struct {
@@ -52,16 +49,33 @@ func HandleGetDependencies(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(jsonError)
-		fmt.Printf("Error fetching dependency graph: %v\n", err)
+		slog.Error("fetching dependency graph", "system", system, "name", projectName, "err", err)
 		return
 	}
 
 	
+	mainProjectID := projectName
+	project, err := internal.Client.GetProjectForPackage(system, projectName, selfVersion(dependencyGraph))
+	if err != nil {
+		slog.Error("fetching project", "name", projectName, "err", err)
+	} else {
+		mainProjectID = project.ProjectKey.ID
+		if err := internal.Store.InsertProject(project); err != nil {
+			slog.Error("storing project", "project", mainProjectID, "err", err)
+		}
+	}
+
+	
 	dependenciesProjects, skipped, err := internal.Client.GetAllProjectsFromGraph(dependencyGraph)
 	if err != nil {
-		fmt.Printf("Error fetching related projects: %v\n", err)
+		slog.Error("fetching related projects", "err", err)
 	}
 
+	if err := internal.Store.InsertProjects(dependenciesProjects); err != nil {
+		slog.Error("storing projects", "err", err)
+	}
+	// Skipped dependencies are only listed: a placeholder stored in their place
+	// would replace the scorecard of a project stored before.
 	for _, skippedProject := range skipped {
 		proj := emptyProjectFromName(skippedProject)
 		dependenciesProjects = append(dependenciesProjects, &proj)
@@ -72,57 +86,125 @@ func HandleGetDependencies(w http.ResponseWriter, r *http.Request) {
 		ID          string         `json:"id"`
 		Score       float64        `json:"score"`
 		CheckScores map[string]int `json:"check_scores,omitempty"`
+		// ProfileScore is only set with ?profile=, -1 without checks to score.
+		ProfileScore *float64 `json:"profile_score,omitempty"`
+		Stale        bool     `json:"stale,omitempty"`
 	}
 
-	mainScores, err := sqlite.GetScoresByProjectID(internal.Db, projectName)
+	mainScores, err := internal.Store.GetScoresByProjectID(mainProjectID)
 	if err != nil {
-		
+		slog.Error("fetching scores", "project", mainProjectID, "err", err)
 	}
 	response := struct {
 		MainScores   map[string]int `json:"main_scores"`
+		Profile      string         `json:"profile,omitempty"`
+		ProfileScore *float64       `json:"profile_score,omitempty"`
 		Message      string         `json:"message"`
 		ProjectName  string         `json:"project_name"`
+		System       string         `json:"system"`
+		Version      string         `json:"version"`
+		Versions     []string       `json:"stored_versions"`
+		Stale        bool           `json:"stale"`
+		FetchedAt    time.Time      `json:"fetched_at"`
 		Dependencies []Dependency   `json:"dependencies"`
 	}{
 		Message:      "No dependencies =) Hiring Marcin is a great idea",
 		MainScores:   mainScores,
 		ProjectName:  projectName,
+		System:       system,
+		Version:      selfVersion(dependencyGraph),
+		Stale:        dependencyGraph.Stale || (project != nil && project.Stale),
+		FetchedAt:    dependencyGraph.FetchedAt,
 		Dependencies: []Dependency{},
 	}
 
-	
-	fmt.Println("Populating dependencies...")
-	sqlite.InsertProjects(internal.Db, dependenciesProjects)
+	if profileQuery != nil {
+		profileScore := profileQuery.profile.Score(mainScores)
+		response.Profile = profileQuery.name
+		response.ProfileScore = &profileScore
+	}
+
+	response.Versions, err = internal.Store.ListGraphVersions(system, projectName)
+	if err != nil {
+		slog.Error("listing stored versions", "name", projectName, "err", err)
+	}
+	version.Sort(response.Versions)
+
 	for _, project := range dependenciesProjects {
 		if project == nil {
-			fmt.Println("Encountered nil project, skipping...")
 			continue
 		}
-		checkScores, err := sqlite.GetScoresByProjectID(internal.Db, project.ProjectKey.ID)
+		checkScores, err := internal.Store.GetScoresByProjectID(project.ProjectKey.ID)
 		if err != nil {
-			fmt.Printf("Error fetching scores for project %s: %v\n", project.ProjectKey.ID, err)
+			slog.Error("fetching scores", "project", project.ProjectKey.ID, "err", err)
 			checkScores = nil
 		}
 		
-		response.Dependencies = append(response.Dependencies, Dependency{
+		dependency := Dependency{
 			ID:          project.ProjectKey.ID,
 			Score:       project.Scorecard.OverallScore,
 			CheckScores: checkScores,
+			Stale:       project.Stale,
+		}
+		if profileQuery != nil {
+			profileScore := profileQuery.profile.Score(checkScores)
+			if !profileQuery.keep(profileScore) {
+				continue
+			}
+			dependency.ProfileScore = &profileScore
+		}
+		response.Dependencies = append(response.Dependencies, dependency)
+	}
+	if profileQuery != nil {
+		// Best first; -1 sorts the dependencies without a profile score last.
+		sort.SliceStable(response.Dependencies, func(i, j int) bool {
+			return *response.Dependencies[i].ProfileScore > *response.Dependencies[j].ProfileScore
 		})
 	}
 
 	
-	fmt.Printf("Generated JSON Response: %s\n", toJSONString(response))
+	slog.Debug("dependency graph response", "body", toJSONString(response))
 
 	
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		fmt.Printf("Error encoding response: %v\n", err)
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+// parseSystemAndName splits "{system}/{name}" paths. Paths that don't start with a
+// known ecosystem are treated as Go module names for backwards compatibility. The
+// path is taken as it is: net/http has already decoded it, and a "+" in it is part
+// of a version such as v2.0.0+incompatible.
+func parseSystemAndName(path string) (string, string) {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) == 2 {
+		if system, ok := deps.NormalizeSystem(parts[0]); ok {
+			return system, parts[1]
+		}
+	}
+	return deps.DefaultSystem, path
+}
+
+// splitVersion splits "name@version". A leading "@" belongs to the name (npm scopes).
+func splitVersion(name string) (string, string) {
+	at := strings.LastIndex(name, "@")
+	if at <= 0 {
+		return name, ""
 	}
+	return name[:at], name[at+1:]
 }
 
+func selfVersion(graph *models.DependencyGraph) string {
+	for _, node := range graph.Nodes {
+		if node.Relation == "SELF" {
+			return node.VersionKey.Version
+		}
+	}
+	return ""
+}
 
 func toJSONString(v interface{}) string {
 	bytes, err := json.MarshalIndent(v, "", "  ")
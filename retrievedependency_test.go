@@ -0,0 +1,136 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"codenotary/internal/depsdevtest"
+	"codenotary/internal/models"
+	"codenotary/internal/sqlite"
+	"codenotary/internal/store"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// useTestStore points internal.Store at a new migrated SQLite database for the
+// duration of the test.
+func useTestStore(t *testing.T) *sqlite.Store {
+	t.Helper()
+	s, err := sqlite.Open(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatalf("opening store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	if _, err := s.Migrate(store.MigrateOptions{}); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	old := internal.Store
+	internal.Store = s
+	t.Cleanup(func() { internal.Store = old })
+	return s
+}
+
+// useTestClient points internal.Client at a fake deps.dev serving the recorded
+// fixtures, storing into s.
+func useTestClient(t *testing.T, s store.Store) *depsdevtest.Server {
+	t.Helper()
+	fake, err := depsdevtest.NewWithFixtures()
+	if err != nil {
+		t.Fatalf("loading fixtures: %v", err)
+	}
+	server := fake.Start()
+	t.Cleanup(server.Close)
+	old := internal.Client
+	internal.Client = deps.NewClient(s, deps.WithBaseURL(server.URL+"/v3"))
+	t.Cleanup(func() { internal.Client = old })
+	return fake
+}
+
+func TestGetDependenciesIncompatiblePin(t *testing.T) {
+	s := useTestStore(t)
+	fake := useTestClient(t, s)
+
+	const name, pinned = "github.com/foo/bar", "v2.0.0+incompatible"
+	key := models.VersionKey{System: "GO", Name: name, Version: pinned}
+	fake.AddPackage(models.PackageVersions{
+		PackageKey: models.PackageKey{System: "GO", Name: name},
+		Versions:   []models.Version{{VersionKey: key, IsDefault: true}},
+	})
+	fake.AddDependencies(key, models.DependencyGraph{
+		Nodes: []models.Node{{VersionKey: key, Relation: "SELF"}},
+	})
+
+	rec := httptest.NewRecorder()
+	HandleGetDependencies(rec, httptest.NewRequest(http.MethodGet, "/dependency/"+name+"@"+pinned, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var response struct {
+		ProjectName string `json:"project_name"`
+		Version     string `json:"version"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if response.ProjectName != name || response.Version != pinned {
+		t.Errorf("got %s@%s, want %s@%s", response.ProjectName, response.Version, name, pinned)
+	}
+
+	if _, _, version, err := packageArg("", name+"@"+pinned); err != nil || version != pinned {
+		t.Errorf("packageArg version = %q, %v; want %s", version, err, pinned)
+	}
+}
+
+// failingProjectReads fails reading one project, as a locked database would.
+type failingProjectReads struct {
+	store.Store
+	id string
+}
+
+func (s failingProjectReads) GetProject(id string) (*models.Project, error) {
+	if id == s.id {
+		return nil, errors.New("database is locked")
+	}
+	return s.Store.GetProject(id)
+}
+
+func TestGetDependenciesKeepsStoredProjectOfSkipped(t *testing.T) {
+	s := useTestStore(t)
+	// yaml.v3 is stored but can't be read, and deps.dev has no project for it:
+	// the handler lists it as skipped.
+	const yaml = "gopkg.in/yaml.v3"
+	useTestClient(t, failingProjectReads{Store: s, id: yaml})
+	stored := &models.Project{
+		ProjectKey: models.ProjectKey{ID: yaml},
+		Scorecard: models.Scorecard{
+			Date:         "2024-01-08T00:00:00Z",
+			OverallScore: 7,
+			Checks:       []models.ScorecardCheck{{Name: "Maintained", Score: 9}},
+		},
+		FetchedAt: time.Now(),
+	}
+	if err := s.InsertProject(stored); err != nil {
+		t.Fatalf("InsertProject: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	HandleGetDependencies(rec, httptest.NewRequest(http.MethodGet, "/dependency/github.com/spf13/cobra@v1.8.0", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	got, err := s.GetProject(yaml)
+	if err != nil || got == nil {
+		t.Fatalf("GetProject(%s) = %v, %v", yaml, got, err)
+	}
+	if got.Scorecard.OverallScore != 7 || len(got.Scorecard.Checks) != 1 {
+		t.Errorf("stored %s now scores %v with %d checks, want 7 with 1", yaml, got.Scorecard.OverallScore, len(got.Scorecard.Checks))
+	}
+	if p, _ := s.GetProject("github.com/russross/blackfriday/v2"); p != nil {
+		t.Errorf("a placeholder was stored for a skipped dependency: %+v", p)
+	}
+}
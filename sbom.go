@@ -0,0 +1,184 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"codenotary/internal/models"
+	"codenotary/internal/sbom"
+	"codenotary/internal/store"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type sbomFormat struct {
+	contentType string
+	write       func(w io.Writer, in sbom.Input) error
+}
+
+// sbomFormats are the formats GET /sbom/ and `codenotary export` produce.
+var sbomFormats = map[string]sbomFormat{
+	"cyclonedx-json": {sbom.CycloneDXJSONContent, func(w io.Writer, in sbom.Input) error { return sbom.CycloneDX(in).WriteJSON(w) }},
+	"cyclonedx-xml":  {sbom.CycloneDXXMLContent, func(w io.Writer, in sbom.Input) error { return sbom.CycloneDX(in).WriteXML(w) }},
+	"spdx-json":      {sbom.SPDXJSONContent, func(w io.Writer, in sbom.Input) error { return sbom.SPDX(in).WriteJSON(w) }},
+	"spdx-tag-value": {sbom.SPDXTagValueContent, func(w io.Writer, in sbom.Input) error { return sbom.SPDX(in).WriteTagValue(w) }},
+}
+
+const sbomFormatNames = "cyclonedx-json, cyclonedx-xml, spdx-json or spdx-tag-value"
+
+// sbomInput resolves the projects of a graph for export.
+func sbomInput(system, projectName string, dependencyGraph *models.DependencyGraph) sbom.Input {
+	return sbom.Input{
+		System:    system,
+		Name:      projectName,
+		Version:   selfVersion(dependencyGraph),
+		Graph:     dependencyGraph,
+		Projects:  nodeProjects(dependencyGraph),
+		Timestamp: time.Now(),
+	}
+}
+
+// HandleExportSBOM serves GET /sbom/{name}[@version]?format=... with an SBOM of the
+// stored dependency graph: cyclonedx-json (the default), cyclonedx-xml, spdx-json
+// or spdx-tag-value.
+func HandleExportSBOM(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	system, projectName := parseSystemAndName(strings.TrimPrefix(r.URL.Path, "/sbom/"))
+	if projectName == "" {
+		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
+		return
+	}
+	projectName, pinnedVersion := splitVersion(projectName)
+
+	formatName := r.URL.Query().Get("format")
+	if formatName == "" {
+		formatName = "cyclonedx-json"
+	}
+	format, ok := sbomFormats[formatName]
+	if !ok {
+		http.Error(w, "format must be "+sbomFormatNames, http.StatusBadRequest)
+		return
+	}
+
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", format.contentType)
+	if err := format.write(w, sbomInput(system, projectName, dependencyGraph)); err != nil {
+		slog.Error("writing SBOM", "err", err)
+	}
+}
+
+// sbomRoot names the package an imported SBOM describes. system, name and version
+// override what the SBOM says; the name and system must be known one way or the other.
+func sbomRoot(imported *sbom.Imported, system, name, version string) (models.VersionKey, error) {
+	root := imported.Root
+	if name != "" {
+		root.Name = name
+	}
+	if version != "" {
+		root.Version = version
+	}
+	if system != "" {
+		normalized, ok := deps.NormalizeSystem(system)
+		if !ok {
+			return root, fmt.Errorf("unknown system %q", system)
+		}
+		root.System = normalized
+	}
+	if root.Name == "" {
+		return root, fmt.Errorf("the SBOM doesn't name the package it describes")
+	}
+	if root.System == "" {
+		return root, fmt.Errorf("the SBOM doesn't tell the package's system")
+	}
+	return root, nil
+}
+
+// importSBOM stores the graph of an imported SBOM under root.
+func importSBOM(imported *sbom.Imported, root models.VersionKey) (*deps.ScanResult, error) {
+	imported.Graph.Nodes[0].VersionKey = root
+	return internal.Client.ImportGraph(root.System, root.Name, root.Version, store.SourceSBOM, imported.Graph)
+}
+
+type SBOMImportResponse struct {
+	ScanResponse
+	Format            string   `json:"format"`
+	SkippedComponents []string `json:"skipped_components"`
+}
+
+func sbomImportResponse(imported *sbom.Imported, result *deps.ScanResult) SBOMImportResponse {
+	return SBOMImportResponse{
+		ScanResponse:      scanResponse(imported.Graph.Nodes[0].VersionKey.System, result),
+		Format:            imported.Format,
+		SkippedComponents: append([]string{}, imported.Skipped...),
+	}
+}
+
+// HandleImportSBOM accepts a CycloneDX or SPDX document, either as the request body
+// or as the "sbom" file of a multipart form, and stores its dependency graph.
+// ?system=, ?name= and ?version= name the stored graph when the SBOM doesn't.
+func HandleImportSBOM(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readSBOMUpload(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	imported, err := sbom.Parse(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to parse SBOM: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	root, err := sbomRoot(imported, query.Get("system"), query.Get("name"), query.Get("version"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("%v, pass ?system=, ?name= and ?version=", err), http.StatusBadRequest)
+		return
+	}
+	result, err := importSBOM(imported, root)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to store SBOM: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sbomImportResponse(imported, result)); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+func readSBOMUpload(r *http.Request) ([]byte, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return io.ReadAll(r.Body)
+	}
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, err
+	}
+	data, err := readFormFile(r, "sbom")
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("missing sbom file")
+	}
+	return data, nil
+}
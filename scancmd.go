@@ -0,0 +1,155 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"codenotary/internal/deps"
+	"codenotary/internal/sbom"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+// runScan implements `codenotary scan`: it fetches a package's graph from deps.dev,
+// or reads it from a go.mod or an SBOM, stores it with the scorecards of its
+// dependencies and prints them.
+func runScan(args []string) error {
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary scan [flags] {name[@version] | -gomod go.mod | -sbom file}")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient)
+	if err != nil {
+		return err
+	}
+	systemFlag := flags.String("system", "", "ecosystem of the named package (GO, NPM, PYPI, ...), GO by default; for -sbom, the system of the described package")
+	gomodPath := flags.String("gomod", "", "scan a local go.mod")
+	gosumPath := flags.String("gosum", "", "go.sum of -gomod, the one next to it by default")
+	sbomPath := flags.String("sbom", "", "import a CycloneDX or SPDX file")
+	name := flags.String("name", "", "name of the package -sbom describes, when the SBOM doesn't say")
+	version := flags.String("version", "", "version the -gomod or -sbom graph is stored under (default \"local\")")
+	asJSON := flags.Bool("json", false, "print the API's JSON response instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	sources := 0
+	for _, set := range []bool{flags.NArg() > 0, *gomodPath != "", *sbomPath != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 || flags.NArg() > 1 {
+		flags.Usage()
+		return fmt.Errorf("scan one package, go.mod or SBOM")
+	}
+
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	var response interface{}
+	var scanned ScanResponse
+	switch {
+	case *gomodPath != "":
+		gomod, gosum, err := readGoMod(*gomodPath, *gosumPath)
+		if err != nil {
+			return err
+		}
+		result, err := internal.Client.ScanGoMod(gomod, gosum, *version)
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %v", *gomodPath, err)
+		}
+		scanned = scanResponse("GO", result)
+		response = scanned
+
+	case *sbomPath != "":
+		data, err := os.ReadFile(*sbomPath)
+		if err != nil {
+			return err
+		}
+		imported, err := sbom.Parse(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %v", *sbomPath, err)
+		}
+		root, err := sbomRoot(imported, *systemFlag, *name, *version)
+		if err != nil {
+			return fmt.Errorf("%v, pass -system, -name and -version", err)
+		}
+		result, err := importSBOM(imported, root)
+		if err != nil {
+			return err
+		}
+		imports := sbomImportResponse(imported, result)
+		for _, skipped := range imports.SkippedComponents {
+			fmt.Fprintf(os.Stderr, "skipped %s: no purl deps.dev knows\n", skipped)
+		}
+		scanned = imports.ScanResponse
+		response = imports
+
+	default:
+		system, name, pinnedVersion, err := packageArg(*systemFlag, flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		dependencyGraph, err := fetchGraph(system, name, pinnedVersion)
+		if err != nil {
+			return fmt.Errorf("failed to fetch the dependencies of %s: %v", name, err)
+		}
+		result := &deps.ScanResult{
+			Module:       name,
+			Version:      selfVersion(dependencyGraph),
+			Graph:        dependencyGraph,
+			NodeProjects: nodeProjects(dependencyGraph),
+		}
+		scanned = scanResponse(system, result)
+		response = scanned
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	}
+	writeScanText(os.Stdout, scanned)
+	return nil
+}
+
+func writeScanText(w io.Writer, s ScanResponse) {
+	fmt.Fprintf(w, "%s/%s@%s: %d dependencies\n", s.System, s.ProjectName, s.Version, len(s.Dependencies))
+	if len(s.Dependencies) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, d := range s.Dependencies {
+		fmt.Fprintf(tw, "%s\t%s\t%s\tscore %s\n", d.Relation, d.ID, d.Version, formatScore(d.Score))
+	}
+	tw.Flush()
+}
+
+// readGoMod reads a go.mod and its go.sum, which defaults to the one next to it
+// and may then be missing.
+func readGoMod(gomodPath, gosumPath string) ([]byte, []byte, error) {
+	gomod, err := os.ReadFile(gomodPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	explicit := gosumPath != ""
+	if !explicit {
+		gosumPath = filepath.Join(filepath.Dir(gomodPath), "go.sum")
+	}
+	gosum, err := os.ReadFile(gosumPath)
+	if err != nil && (explicit || !errors.Is(err, os.ErrNotExist)) {
+		return nil, nil, err
+	}
+	return gomod, gosum, nil
+}
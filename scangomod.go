@@ -0,0 +1,135 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+const maxUploadSize = 10 << 20
+
+type ScanDependency struct {
+	ID          string         `json:"id"`
+	Version     string         `json:"version"`
+	Relation    string         `json:"relation"`
+	Score       float64        `json:"score"`
+	CheckScores map[string]int `json:"check_scores,omitempty"`
+	Errors      []string       `json:"errors,omitempty"`
+}
+
+type ScanResponse struct {
+	ProjectName  string           `json:"project_name"`
+	System       string           `json:"system"`
+	Version      string           `json:"version"`
+	Dependencies []ScanDependency `json:"dependencies"`
+}
+
+// HandleScanGoMod accepts either a multipart form with "gomod" and optional "gosum"
+// files, or a raw go.mod as the request body. ?version= names the stored graph.
+func HandleScanGoMod(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	gomod, gosum, err := readGoModUpload(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	result, err := internal.Client.ScanGoMod(gomod, gosum, r.URL.Query().Get("version"))
+	if errors.Is(err, deps.ErrInvalidGoMod) {
+		http.Error(w, fmt.Sprintf("Failed to scan go.mod: %v", err), http.StatusBadRequest)
+		return
+	} else if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to store scan: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := scanResponse("GO", result)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+func readGoModUpload(r *http.Request) ([]byte, []byte, error) {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxUploadSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		gomod, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, nil, err
+		}
+		return gomod, nil, nil
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, nil, err
+	}
+	gomod, err := readFormFile(r, "gomod")
+	if err != nil {
+		return nil, nil, err
+	}
+	if gomod == nil {
+		return nil, nil, fmt.Errorf("missing gomod file")
+	}
+	gosum, err := readFormFile(r, "gosum")
+	if err != nil {
+		return nil, nil, err
+	}
+	return gomod, gosum, nil
+}
+
+func readFormFile(r *http.Request, field string) ([]byte, error) {
+	file, _, err := r.FormFile(field)
+	if err == http.ErrMissingFile {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// scanResponse lists the dependencies of a scanned graph with the scores of their
+// projects, -1 where the project is unknown.
+func scanResponse(system string, result *deps.ScanResult) ScanResponse {
+	response := ScanResponse{
+		ProjectName:  result.Module,
+		System:       system,
+		Version:      result.Version,
+		Dependencies: []ScanDependency{},
+	}
+	for i, node := range result.Graph.Nodes {
+		if node.Relation == "SELF" {
+			continue
+		}
+		dependency := ScanDependency{
+			ID:       node.VersionKey.Name,
+			Version:  node.VersionKey.Version,
+			Relation: node.Relation,
+			Score:    -1,
+			Errors:   node.Errors,
+		}
+		if i < len(result.NodeProjects) && result.NodeProjects[i] != nil {
+			project := result.NodeProjects[i]
+			dependency.Score = project.Scorecard.OverallScore
+			checkScores, err := internal.Store.GetScoresByProjectID(project.ProjectKey.ID)
+			if err != nil {
+				slog.Error("fetching scores", "project", project.ProjectKey.ID, "err", err)
+			}
+			dependency.CheckScores = checkScores
+		}
+		response.Dependencies = append(response.Dependencies, dependency)
+	}
+	return response
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"codenotary/internal/models"
+	"codenotary/internal/store"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// failingGraphWrites fails storing graphs, as a full disk would.
+type failingGraphWrites struct {
+	store.Store
+}
+
+func (failingGraphWrites) ReplaceDependencyGraph(system, projectID, version, source string, graph *models.DependencyGraph) error {
+	return errors.New("database or disk is full")
+}
+
+func TestScanGoModStatus(t *testing.T) {
+	s := useTestStore(t)
+	useTestClient(t, s)
+	const gomod = "module example.com/app\n\nrequire github.com/spf13/pflag v1.0.5\n"
+
+	scan := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		HandleScanGoMod(rec, httptest.NewRequest(http.MethodPost, "/scan/gomod", strings.NewReader(body)))
+		return rec
+	}
+	if rec := scan(gomod); rec.Code != http.StatusOK {
+		t.Errorf("scanning a go.mod: status %d: %s", rec.Code, rec.Body)
+	}
+	if rec := scan("not a go.mod"); rec.Code != http.StatusBadRequest {
+		t.Errorf("scanning a malformed go.mod: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	if rec := scan("go 1.22\n"); rec.Code != http.StatusBadRequest {
+		t.Errorf("scanning a go.mod without module: status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	useTestClient(t, failingGraphWrites{s})
+	if rec := scan(gomod); rec.Code != http.StatusInternalServerError {
+		t.Errorf("scan that couldn't be stored: status %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/deps"
+	"codenotary/internal/store"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Result counts of GET /search.
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+type SearchHit struct {
+	Kind        string            `json:"kind"`
+	System      string            `json:"system,omitempty"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Homepage    string            `json:"homepage,omitempty"`
+	Score       float64           `json:"score"`
+	Highlights  map[string]string `json:"highlights"`
+}
+
+type SearchResponse struct {
+	Query   string      `json:"query"`
+	Count   int         `json:"count"`
+	Results []SearchHit `json:"results"`
+}
+
+// HandleSearch serves GET /search?q=...&kind=project|package&system=NPM&limit=N,
+// a full-text search over every project and package the database holds.
+func HandleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	q := store.SearchQuery{Text: strings.TrimSpace(query.Get("q")), Kind: query.Get("kind"), Limit: defaultSearchLimit}
+	if q.Text == "" {
+		http.Error(w, "Missing search text, use ?q=", http.StatusBadRequest)
+		return
+	}
+	if q.Kind != "" && q.Kind != store.SearchProject && q.Kind != store.SearchPackage {
+		http.Error(w, fmt.Sprintf("Invalid kind %q, use %s or %s", q.Kind, store.SearchProject, store.SearchPackage), http.StatusBadRequest)
+		return
+	}
+	if system := query.Get("system"); system != "" {
+		normalized, ok := deps.NormalizeSystem(system)
+		if !ok {
+			http.Error(w, fmt.Sprintf("Unknown system %q, use one of %s", system, strings.Join(deps.Systems, ", ")), http.StatusBadRequest)
+			return
+		}
+		q.System = normalized
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxSearchLimit {
+			http.Error(w, fmt.Sprintf("Invalid limit %q, use 1 to %d", value, maxSearchLimit), http.StatusBadRequest)
+			return
+		}
+		q.Limit = limit
+	}
+
+	searcher, ok := internal.Store.(store.Searcher)
+	if !ok {
+		http.Error(w, "Search is only available with the SQLite backend", http.StatusNotImplemented)
+		return
+	}
+	results, err := searcher.Search(q)
+	if errors.Is(err, store.ErrSearchUnavailable) {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error searching: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	response := SearchResponse{Query: q.Text, Count: len(results), Results: []SearchHit{}}
+	for _, result := range results {
+		response.Results = append(response.Results, SearchHit(result))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
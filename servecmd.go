@@ -0,0 +1,117 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"codenotary/internal/policy"
+	"codenotary/internal/profile"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// runServe implements `codenotary serve`, the HTTP API.
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient|config.GroupServer)
+	if err != nil {
+		return err
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	if cfg.Policy != "" {
+		internal.Policy, err = policy.Load(cfg.Policy)
+		if err != nil {
+			return fmt.Errorf("invalid policy: %v", err)
+		}
+	}
+	if cfg.Profiles != "" {
+		internal.Profiles, err = profile.Load(cfg.Profiles)
+		if err != nil {
+			return fmt.Errorf("invalid profiles: %v", err)
+		}
+	}
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	if cfg.Cache.RefreshInterval > 0 {
+		internal.Client.StartRefresher(context.Background(), cfg.Cache.RefreshInterval, cfg.Cache.RefreshBatch)
+	}
+
+	server := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      withCORS(cfg.Server.CORSOrigins, newMux()),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	slog.Info("server is running", "addr", cfg.Server.Addr)
+	return server.ListenAndServe()
+}
+
+func newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/dependency/", HandleGetDependencies)
+
+	mux.HandleFunc("/dependency/add", HandleAddOrUpdateDependency) // POST
+	mux.HandleFunc("/dependency/get/", HandleGetDependency)        // GET /dependency/get/{projectName}/{depName}
+	mux.HandleFunc("/dependency/delete/", HandleDeleteDependency)  // DELETE /dependency/delete/{projectName}/{depName}
+	mux.HandleFunc("/dependencies", HandleListDependencies)        // GET /dependencies?name=xyz&min_score=50
+	mux.HandleFunc("/dependencies/query", HandleQueryDependencies) // GET /dependencies/query?check.Maintained<5&sort=-score&limit=50
+	mux.HandleFunc("/scan/gomod", HandleScanGoMod)                 // POST /scan/gomod?version=v1.2.3
+	mux.HandleFunc("/projects/", HandleProjectHistory)             // GET /projects/{projectID}/history
+	mux.HandleFunc("/sbom/import", HandleImportSBOM)               // POST /sbom/import?system=&name=&version=
+	mux.HandleFunc("/sbom/", HandleExportSBOM)                     // GET /sbom/{name}?format=cyclonedx-json|cyclonedx-xml|spdx-json|spdx-tag-value
+	mux.HandleFunc("/diff/", HandleDiff)                           // GET /diff/{name}?from=vX&to=vY&format=text
+	mux.HandleFunc("/policy/evaluate/", HandleEvaluatePolicy)      // POST /policy/evaluate/{name}, optional policy as the body
+	mux.HandleFunc("/vulns/", HandleVulns)                         // GET /vulns/{name}?min_severity=HIGH
+	mux.HandleFunc("/graph/", HandleGetGraph)                      // GET /graph/{name}?depth=2&relation=DIRECT, GET /graph/{name}/why/{dependency}
+	mux.HandleFunc("/search", HandleSearch)                        // GET /search?q=cobra&kind=project&system=GO&limit=20
+
+	return mux
+}
+
+// withCORS lets browsers on origins call the API. "*" allows any origin; without
+// origins next is returned as is. Preflight requests are answered here.
+func withCORS(origins []string, next http.Handler) http.Handler {
+	if len(origins) == 0 {
+		return next
+	}
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.TrimSuffix(origin, "/")] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || (!allowed["*"] && !allowed[origin]) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if allowed["*"] {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			header.Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type")
+			header.Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/models"
+	"codenotary/internal/osv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+type VulnAdvisory struct {
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Aliases       []string `json:"aliases"`
+	URL           string   `json:"url"`
+	Severity      string   `json:"severity"`
+	CVSS3Score    float64  `json:"cvss3_score"`
+	CVSS3Vector   string   `json:"cvss3_vector,omitempty"`
+	FixedVersions []string `json:"fixed_versions"`
+	Source        string   `json:"source"`
+}
+
+type VulnerableDependency struct {
+	Index      int            `json:"index"`
+	System     string         `json:"system"`
+	Name       string         `json:"name"`
+	Version    string         `json:"version"`
+	Relation   string         `json:"relation"`
+	Severity   string         `json:"severity"`
+	Advisories []VulnAdvisory `json:"advisories"`
+}
+
+type VulnSummary struct {
+	VulnerableDependencies int            `json:"vulnerable_dependencies"`
+	Advisories             int            `json:"advisories"`
+	BySeverity             map[string]int `json:"by_severity"`
+}
+
+type VulnsResponse struct {
+	ProjectName  string                 `json:"project_name"`
+	System       string                 `json:"system"`
+	Version      string                 `json:"version"`
+	Summary      VulnSummary            `json:"summary"`
+	Dependencies []VulnerableDependency `json:"dependencies"`
+	// Unchecked dependencies couldn't be looked up on deps.dev; only imported OSV
+	// advisories cover them.
+	Unchecked []string `json:"unchecked"`
+}
+
+// HandleVulns serves GET /vulns/{name}[@version]?min_severity=HIGH: every node of
+// the stored dependency graph affected by a known advisory, most severe first,
+// with the versions that fix it. CVSS scores are -1 when unknown.
+func HandleVulns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	system, projectName := parseSystemAndName(strings.TrimPrefix(r.URL.Path, "/vulns/"))
+	if projectName == "" {
+		http.Error(w, "Invalid or missing project name", http.StatusBadRequest)
+		return
+	}
+	projectName, pinnedVersion := splitVersion(projectName)
+	minSeverity, err := parseSeverity(r.URL.Query().Get("min_severity"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dependencyGraph, err := fetchGraph(system, projectName, pinnedVersion)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to fetch dependencies: %v", err), http.StatusBadGateway)
+		return
+	}
+	response := vulnsResponse(system, projectName, dependencyGraph, minSeverity)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("encoding response", "err", err)
+	}
+}
+
+// parseSeverity accepts a severity name in any case; empty means every severity.
+func parseSeverity(s string) (string, error) {
+	if s == "" {
+		return osv.SeverityUnknown, nil
+	}
+	severity := strings.ToUpper(s)
+	for _, known := range osv.Severities {
+		if severity == known {
+			return severity, nil
+		}
+	}
+	return "", fmt.Errorf("min_severity must be one of %s", strings.Join(osv.Severities, ", "))
+}
+
+func vulnsResponse(system, projectName string, dependencyGraph *models.DependencyGraph, minSeverity string) VulnsResponse {
+	response := VulnsResponse{
+		ProjectName:  projectName,
+		System:       system,
+		Version:      selfVersion(dependencyGraph),
+		Dependencies: []VulnerableDependency{},
+		Unchecked:    []string{},
+		Summary:      VulnSummary{BySeverity: make(map[string]int)},
+	}
+
+	advisories, errs := internal.Client.GetNodeAdvisories(dependencyGraph)
+	counted := make(map[string]bool)
+	for i, node := range dependencyGraph.Nodes {
+		key := node.VersionKey
+		if errs[i] != nil {
+			slog.Error("checking advisories", "system", key.System, "name", key.Name, "version", key.Version, "err", errs[i])
+			response.Unchecked = append(response.Unchecked, key.Name+"@"+key.Version)
+		}
+
+		dependency := VulnerableDependency{
+			Index:    i,
+			System:   key.System,
+			Name:     key.Name,
+			Version:  key.Version,
+			Relation: node.Relation,
+			Severity: osv.SeverityUnknown,
+		}
+		for _, a := range advisories[i] {
+			if osv.SeverityRank(a.Severity) > osv.SeverityRank(minSeverity) {
+				continue
+			}
+			advisory := VulnAdvisory{
+				ID:            a.AdvisoryKey.ID,
+				Title:         a.Title,
+				Aliases:       append([]string{}, a.Aliases...),
+				URL:           a.URL,
+				Severity:      a.Severity,
+				CVSS3Score:    a.CVSS3Score,
+				CVSS3Vector:   a.CVSS3Vector,
+				FixedVersions: append([]string{}, osv.FixedVersions(a, key.System, key.Name)...),
+				Source:        a.Source,
+			}
+			dependency.Advisories = append(dependency.Advisories, advisory)
+			if osv.SeverityRank(a.Severity) < osv.SeverityRank(dependency.Severity) {
+				dependency.Severity = a.Severity
+			}
+			if !counted[a.AdvisoryKey.ID] {
+				counted[a.AdvisoryKey.ID] = true
+				response.Summary.Advisories++
+				response.Summary.BySeverity[a.Severity]++
+			}
+		}
+		if len(dependency.Advisories) > 0 {
+			response.Dependencies = append(response.Dependencies, dependency)
+		}
+	}
+	response.Summary.VulnerableDependencies = len(response.Dependencies)
+
+	sort.SliceStable(response.Dependencies, func(i, j int) bool {
+		a, b := response.Dependencies[i], response.Dependencies[j]
+		if ra, rb := osv.SeverityRank(a.Severity), osv.SeverityRank(b.Severity); ra != rb {
+			return ra < rb
+		}
+		return a.Name < b.Name
+	})
+	return response
+}
+
+func writeVulnsText(w io.Writer, v VulnsResponse) {
+	fmt.Fprintf(w, "%s/%s@%s\n", v.System, v.ProjectName, v.Version)
+	fmt.Fprintf(w, "%d vulnerable dependencies, %d advisories", v.Summary.VulnerableDependencies, v.Summary.Advisories)
+	for _, severity := range osv.Severities {
+		if n := v.Summary.BySeverity[severity]; n > 0 {
+			fmt.Fprintf(w, ", %d %s", n, strings.ToLower(severity))
+		}
+	}
+	fmt.Fprintln(w)
+	if len(v.Unchecked) > 0 {
+		fmt.Fprintf(w, "not checked on deps.dev: %s\n", strings.Join(v.Unchecked, ", "))
+	}
+	if len(v.Dependencies) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, d := range v.Dependencies {
+		for _, a := range d.Advisories {
+			fixed := "no fix known"
+			if len(a.FixedVersions) > 0 {
+				fixed = "fixed in " + strings.Join(a.FixedVersions, ", ")
+			}
+			fmt.Fprintf(tw, "%s %s\t%s\t%s\t%s\t%s\t%s\n", d.Name, d.Version, d.Relation, a.Severity, a.ID, fixed, a.Title)
+		}
+	}
+	tw.Flush()
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"codenotary/internal"
+	"codenotary/internal/config"
+	"codenotary/internal/models"
+	"codenotary/internal/osv"
+	"codenotary/internal/store"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runVulns implements `codenotary vulns`, the command line twin of GET /vulns/, and
+// `codenotary vulns import`, which loads OSV records for air-gapped use.
+func runVulns(args []string) error {
+	if len(args) > 0 && args[0] == "import" {
+		return runImportAdvisories(args[1:])
+	}
+
+	flags := flag.NewFlagSet("vulns", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: codenotary vulns [flags] name[@version]\n       codenotary vulns import [flags] {file.json | dir | dump.zip} ...")
+		flags.PrintDefaults()
+	}
+	cfg, err := loadConfig(flags, args, config.GroupStore|config.GroupClient)
+	if err != nil {
+		return err
+	}
+	systemFlag := flags.String("system", "", "ecosystem of the package (GO, NPM, PYPI, ...), GO by default")
+	minSeverityFlag := flags.String("min-severity", "", "only report advisories at least this severe: CRITICAL, HIGH, MEDIUM, LOW")
+	asJSON := flags.Bool("json", false, "print the API's JSON response instead of a table")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("name one package")
+	}
+	minSeverity, err := parseSeverity(*minSeverityFlag)
+	if err != nil {
+		return err
+	}
+
+	system, name, pinnedVersion, err := packageArg(*systemFlag, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := openClient(cfg); err != nil {
+		return err
+	}
+	defer internal.Store.Close()
+
+	dependencyGraph, err := fetchGraph(system, name, pinnedVersion)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the dependencies of %s: %v", name, err)
+	}
+	response := vulnsResponse(system, name, dependencyGraph, minSeverity)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(response)
+	}
+	writeVulnsText(os.Stdout, response)
+	return nil
+}
+
+func runImportAdvisories(args []string) error {
+	flags := flag.NewFlagSet("vulns import", flag.ContinueOnError)
+	cfg, err := loadConfig(flags, args, config.GroupStore)
+	if err != nil {
+		return err
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("usage: codenotary vulns import [flags] {file.json | dir | dump.zip} ...")
+	}
+
+	var advisories []models.Advisory
+	for _, path := range flags.Args() {
+		loaded, err := osv.Load(path)
+		if err != nil {
+			return err
+		}
+		advisories = append(advisories, loaded...)
+	}
+
+	st, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	if _, err := st.Migrate(store.MigrateOptions{}); err != nil {
+		return fmt.Errorf("failed to migrate the database: %v", err)
+	}
+	if err := st.InsertAdvisories(advisories); err != nil {
+		return err
+	}
+	fmt.Printf("imported %d advisories\n", len(advisories))
+	return nil
+}